@@ -14,6 +14,7 @@ func main() {
 	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -21,5 +22,7 @@ func main() {
 
 	if err := app.Run(ctx, cfg); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(1)
 	}
 }
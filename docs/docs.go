@@ -0,0 +1,10 @@
+// Package docs embeds the OpenAPI specification of the URL shortener API,
+// so it can be served regardless of the working directory of the binary.
+package docs
+
+import _ "embed"
+
+// Swagger contains the contents of the swagger.yml OpenAPI specification.
+//
+//go:embed swagger.yml
+var Swagger []byte
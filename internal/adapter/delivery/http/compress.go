@@ -0,0 +1,191 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressionMinSize is the minimum size of response bodies that are compressed.
+// Smaller bodies are sent as is, since compressing them saves little and costs CPU time.
+const compressionMinSize = 1024
+
+// compressibleContentTypes lists the media types of the responses that are compressed.
+var compressibleContentTypes = map[string]bool{
+	"application/json": true,
+	"text/csv":         true,
+	"text/plain":       true,
+	"text/html":        true,
+}
+
+// compress is a middleware that compresses JSON and text responses with gzip or deflate,
+// whichever the client accepts, preferring gzip. Responses are buffered until they reach
+// compressionMinSize, so that smaller responses are sent uncompressed.
+func compress(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// The response isn't closed if the handler panics, so that recoverer can still write the error response.
+		cw := &compressResponseWriter{ResponseWriter: w, encoding: encoding}
+		next.ServeHTTP(cw, r)
+		cw.close()
+	})
+}
+
+// acceptedEncoding returns the content coding of the Accept-Encoding header the response is compressed with,
+// or an empty string if the client accepts neither gzip nor deflate.
+func acceptedEncoding(header string) string {
+	var deflate bool
+
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip":
+			return "gzip"
+		case "deflate":
+			deflate = true
+		}
+	}
+
+	if deflate {
+		return "deflate"
+	}
+
+	return ""
+}
+
+// compressResponseWriter buffers the response body until it reaches compressionMinSize,
+// then writes the buffered and following bytes through a compressor if the response is compressible.
+type compressResponseWriter struct {
+	http.ResponseWriter
+	encoding    string
+	status      int
+	buf         bytes.Buffer
+	compressor  io.WriteCloser
+	wroteHeader bool
+}
+
+// WriteHeader records the status code, which is written along with the body once
+// it is known whether the body is compressed.
+func (w *compressResponseWriter) WriteHeader(statusCode int) {
+	if w.status == 0 {
+		w.status = statusCode
+	}
+}
+
+// Write buffers p until the body reaches compressionMinSize, after which the body is written
+// compressed if the response is compressible and as is otherwise.
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if w.compressor != nil {
+		return w.compressor.Write(p)
+	}
+
+	if w.wroteHeader {
+		return w.ResponseWriter.Write(p)
+	}
+
+	w.buf.Write(p)
+
+	if w.buf.Len() < compressionMinSize {
+		return len(p), nil
+	}
+
+	if w.compressible() {
+		w.startCompression()
+	}
+
+	if err := w.flushBuffer(); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// compressible reports whether the response can be compressed based on its headers.
+func (w *compressResponseWriter) compressible() bool {
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	return err == nil && compressibleContentTypes[mediaType]
+}
+
+// startCompression sets the compression headers and creates the compressor writing to the response.
+func (w *compressResponseWriter) startCompression() {
+	h := w.Header()
+	h.Set("Content-Encoding", w.encoding)
+	h.Del("Content-Length")
+
+	w.writeHeader()
+
+	switch w.encoding {
+	case "gzip":
+		w.compressor = gzip.NewWriter(w.ResponseWriter)
+	default:
+		// The deflate content coding is the zlib format, not raw DEFLATE, see RFC 9110 section 8.4.1.2.
+		w.compressor = zlib.NewWriter(w.ResponseWriter)
+	}
+}
+
+// writeHeader writes the recorded status code, defaulting to 200 OK.
+func (w *compressResponseWriter) writeHeader() {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// flushBuffer writes the buffered body, through the compressor if compression has started.
+func (w *compressResponseWriter) flushBuffer() error {
+	w.writeHeader()
+
+	var dst io.Writer = w.ResponseWriter
+	if w.compressor != nil {
+		dst = w.compressor
+	}
+
+	_, err := w.buf.WriteTo(dst)
+	return err
+}
+
+// close writes the rest of the response: the buffered body of a response smaller than
+// compressionMinSize as is, or the end of the compressed stream.
+func (w *compressResponseWriter) close() {
+	if w.compressor != nil {
+		w.compressor.Close() //nolint:errcheck
+		return
+	}
+
+	if w.status == 0 && w.buf.Len() == 0 {
+		// Nothing was written, so net/http writes the default response.
+		return
+	}
+
+	w.flushBuffer() //nolint:errcheck
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/httplog/v2"
+	"github.com/go-chi/render"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+)
+
+// httpStatusFor maps an error returned by the use case to the status code and the response the request
+// is answered with, so that every handler answers the same error alike. Domain errors are client errors.
+// Canceled requests are answered with 499 and requests that ran out of time with 503, so that aborted
+// queries aren't reported as server errors. An unavailable database is answered with 503, and any
+// other error with 500.
+func httpStatusFor(err error) (int, errorResponse) {
+	switch {
+	case errors.Is(err, entity.ErrURLNotFound):
+		return http.StatusNotFound, urlNotFoundResponse
+	case errors.Is(err, entity.ErrURLDeactivated), errors.Is(err, entity.ErrURLExpired):
+		return http.StatusGone, urlGoneResponse
+	case errors.Is(err, entity.ErrShortCodeExists):
+		return http.StatusConflict, shortCodeExistsResponse
+	case errors.Is(err, entity.ErrShortCodeTooShort):
+		return http.StatusBadRequest, shortCodeTooShortResponse
+	case errors.Is(err, entity.ErrUnknownTier):
+		return http.StatusBadRequest, unknownTierResponse
+	case errors.Is(err, entity.ErrEncodingNotAllowed):
+		return http.StatusBadRequest, encodingNotAllowedResponse
+	case errors.Is(err, entity.ErrDomainNotAllowed):
+		return http.StatusBadRequest, domainNotAllowedResponse
+	case errors.Is(err, entity.ErrSelfReferentialURL):
+		return http.StatusBadRequest, selfReferentialURLResponse
+	case errors.Is(err, entity.ErrCreationCooldown):
+		return http.StatusTooManyRequests, creationCooldownResponse
+	case errors.Is(err, entity.ErrLinkCheckInProgress):
+		return http.StatusTooManyRequests, linkCheckInProgressResponse
+	case errors.Is(err, context.Canceled):
+		return statusClientClosedRequest, requestCanceledResponse
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable, requestTimeoutResponse
+	case errors.Is(err, entity.ErrDatabaseUnavailable):
+		return http.StatusServiceUnavailable, databaseUnavailableResponse
+	default:
+		return http.StatusInternalServerError, serverErrorResponse
+	}
+}
+
+// retryAfterFor returns the Retry-After header of the response to a request that failed with the error,
+// or an empty string if retrying the request won't help.
+func retryAfterFor(err error) string {
+	switch {
+	case errors.Is(err, entity.ErrDatabaseUnavailable):
+		return databaseUnavailableRetryAfter
+	case errors.Is(err, entity.ErrLinkCheckInProgress):
+		return linkCheckRetryAfter
+	default:
+		return ""
+	}
+}
+
+// renderError renders the response to a request that failed with the error returned by the use case,
+// as mapped by httpStatusFor. Errors that aren't caused by the request itself are logged.
+func renderError(w http.ResponseWriter, r *http.Request, err error) {
+	status, resp := httpStatusFor(err)
+
+	if status >= http.StatusInternalServerError || status == statusClientClosedRequest {
+		httplog.LogEntrySetField(r.Context(), "err", slog.AnyValue(err))
+	}
+
+	if retryAfter := retryAfterFor(err); retryAfter != "" {
+		w.Header().Set("Retry-After", retryAfter)
+	}
+
+	render.Status(r, status)
+	renderJSON(w, r, withRequestID(r, resp))
+}
@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// fieldSelection lists the fields of a response that a client asked for with the fields query parameter,
+// e.g. ?fields=short_code,stats.access_count, to reduce the size of responses. Fields of nested objects
+// are named by their path. A nil selection selects all fields.
+type fieldSelection []string
+
+// unknownFieldError is returned by parseFields for a field that the response doesn't have.
+type unknownFieldError struct {
+	field string
+}
+
+func (e *unknownFieldError) Error() string {
+	return fmt.Sprintf("unknown field %q", e.field)
+}
+
+// parseFields parses the comma-separated fields query parameter, checking the fields against
+// the JSON fields of the response type T. It returns a nil selection if no fields are listed.
+func parseFields[T any](values url.Values) (fieldSelection, error) {
+	if !values.Has("fields") {
+		return nil, nil
+	}
+
+	allowed := make(map[string]bool)
+	collectFields(reflect.TypeFor[T](), "", allowed)
+
+	var fields fieldSelection
+
+	for _, field := range strings.Split(values.Get("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if !allowed[field] {
+			return nil, &unknownFieldError{field: field}
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+var textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+
+// collectFields adds the paths of the JSON fields of the struct type t to fields, prefixed by prefix.
+// Fields of nested objects are added along with the object, while values marshaled as text,
+// such as time.Time, are leaves.
+func collectFields(t reflect.Type, prefix string, fields map[string]bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct || t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		return
+	}
+
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		path := prefix + name
+		fields[path] = true
+		collectFields(f.Type, path+".", fields)
+	}
+}
+
+// apply returns the selected fields of the response as a JSON object, or the response itself
+// if all fields are selected. Selected fields that are omitted from the response are left out.
+func (s fieldSelection) apply(v any) any {
+	if s == nil {
+		return v
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+
+	// Numbers are kept as they are, since the access count may exceed the precision of float64.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var full map[string]any
+	if err := dec.Decode(&full); err != nil {
+		return v
+	}
+
+	selected := make(map[string]any, len(s))
+	for _, field := range s {
+		copyField(selected, full, strings.Split(field, "."))
+	}
+
+	return selected
+}
+
+// copyField copies the field at the path from src to dst, creating the objects along the path in dst.
+func copyField(dst, src map[string]any, path []string) {
+	value, ok := src[path[0]]
+	if !ok {
+		return
+	}
+
+	if len(path) == 1 {
+		dst[path[0]] = value
+		return
+	}
+
+	nested, ok := value.(map[string]any)
+	if !ok {
+		return
+	}
+
+	dstNested, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		dstNested = make(map[string]any)
+		dst[path[0]] = dstNested
+	}
+
+	copyField(dstNested, nested, path[1:])
+}
+
+// partialURLListResponse is a urlListResponse whose URLs only have the fields selected by the client.
+// Its URLs field takes precedence over the one of the embedded response when marshaled.
+type partialURLListResponse struct {
+	urlListResponse
+	URLs []any `json:"urls"`
+}
+
+// applyToList returns the list response with the selected fields of each URL, or the response itself
+// if all fields are selected.
+func (s fieldSelection) applyToList(resp urlListResponse) any {
+	if s == nil {
+		return resp
+	}
+
+	urls := make([]any, 0, len(resp.URLs))
+	for _, u := range resp.URLs {
+		urls = append(urls, s.apply(u))
+	}
+
+	return partialURLListResponse{urlListResponse: resp, URLs: urls}
+}
@@ -1,22 +1,54 @@
 package http
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
 	"reflect"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/httplog/v2"
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
+	"github.com/vadimbarashkov/url-shortener/docs"
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
 )
 
+// databaseUnavailableRetryAfter is the number of seconds clients are advised to wait
+// before retrying a request that failed because the database is unavailable.
+const databaseUnavailableRetryAfter = "5"
+
+// serverBusyRetryAfter is the number of seconds clients are advised to wait
+// before retrying a request that was rejected because too many requests are in flight.
+const serverBusyRetryAfter = "1"
+
+// linkCheckRetryAfter is the number of seconds clients are advised to wait
+// before retrying a link check while another one is running.
+const linkCheckRetryAfter = "30"
+
+// statusClientClosedRequest is the non-standard status code of requests whose client went away
+// before the response was written. It is only ever seen in logs, since the client no longer listens.
+const statusClientClosedRequest = 499
+
+// urlLocation returns the path of the endpoint resolving the given short code,
+// used as the Location of created URLs.
+func urlLocation(shortCode string) string {
+	return "/api/v1/shorten/" + url.PathEscape(shortCode)
+}
+
 // handlePing handles the ping request and responds with "pong".
 // This is a simple health check endpoint.
 func handlePing(w http.ResponseWriter, r *http.Request) {
@@ -24,24 +56,194 @@ func handlePing(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprint(w, "pong")
 }
 
+// handleHealthz handles the health check request of the admin router and responds with "ok".
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "ok")
+}
+
+// handleNotFound handles requests to paths that match no route, and to endpoints of disabled features.
+func handleNotFound(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusNotFound)
+	renderJSON(w, r, withRequestID(r, notFoundResponse))
+}
+
+// routeMethods lists the methods probed for the Allow header of 405 Method Not Allowed responses.
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
+// handleMethodNotAllowed returns a handler for requests to paths that match a route of the router,
+// but not with the request's method. chi only sets the Allow header for its own handler, so the methods
+// the path is served with are found by matching the path against a flat copy of the routes, since
+// matching against the router itself mistakes the paths of its subrouters for routes.
+func handleMethodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	flat := chi.NewRouter()
+
+	_ = chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// Trailing slashes are stripped from requests, so routes are matched without them too.
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+
+		flat.Method(method, route, http.NotFoundHandler())
+		return nil
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := r.URL.RawPath
+		if path == "" {
+			path = r.URL.Path
+		}
+
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+
+		var allowed []string
+
+		for _, method := range routeMethods {
+			if flat.Match(chi.NewRouteContext(), method, path) {
+				allowed = append(allowed, method)
+			}
+		}
+
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+		}
+
+		render.Status(r, http.StatusMethodNotAllowed)
+		renderJSON(w, r, withRequestID(r, methodNotAllowedResponse))
+	}
+}
+
+// handleReadyz returns a handler for the readiness check that responds with "ok" if the check passes
+// or isn't set, and with 503 Service Unavailable otherwise, so that traffic isn't routed to the service
+// until it can serve it, e.g. while the database is unreachable or its migrations are pending.
+func handleReadyz(check func(ctx context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if check != nil {
+			if err := check(r.Context()); err != nil {
+				httplog.LogEntrySetField(r.Context(), "err", slog.AnyValue(err))
+				render.Status(r, http.StatusServiceUnavailable)
+				renderJSON(w, r, withRequestID(r, notReadyResponse))
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "ok")
+	}
+}
+
+// serviceName is the name of the service reported on the root path.
+const serviceName = "url-shortener"
+
+// handleRoot returns a handler for the root path that redirects to redirectURL if it is set,
+// or responds with a banner describing the service otherwise, linking to docsURL if it is set.
+func handleRoot(redirectURL, docsURL string) http.HandlerFunc {
+	banner := bannerResponse{
+		Service: serviceName,
+		Version: serviceVersion(),
+		DocsURL: docsURL,
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if redirectURL != "" {
+			http.Redirect(w, r, redirectURL, http.StatusFound)
+			return
+		}
+
+		renderJSON(w, r, banner)
+	}
+}
+
+// handleConfig returns a handler responding with the settings of the service clients can adapt to.
+func handleConfig(config configResponse) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		renderJSON(w, r, config)
+	}
+}
+
+// serviceVersion returns the version of the main module the binary was built from,
+// or "unknown" if the binary wasn't built with module support.
+func serviceVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Version == "" {
+		return "unknown"
+	}
+
+	return info.Main.Version
+}
+
+// handleSwaggerSpec serves the OpenAPI specification embedded into the binary.
+func handleSwaggerSpec(w http.ResponseWriter, r *http.Request) {
+	http.ServeContent(w, r, "swagger.yml", time.Time{}, bytes.NewReader(docs.Swagger))
+}
+
 // urlUseCase defines the methods required for URL shortening and management.
 // It abstracts the business logic needed for handling URLs.
 type urlUseCase interface {
-	ShortenURL(ctx context.Context, originalURL string) (*entity.URL, error)
-	ResolveShortCode(ctx context.Context, shortCode string) (*entity.URL, error)
-	ModifyURL(ctx context.Context, shortCode, originalURL string) (*entity.URL, error)
+	ShortenURL(ctx context.Context, in entity.ShortenInput) (*entity.URL, error)
+	CloneURL(ctx context.Context, shortCode, ip string) (*entity.URL, error)
+	ReserveShortCode(ctx context.Context, ip string) (*entity.URL, error)
+	ResolveShortCode(ctx context.Context, shortCode string, click entity.Click) (*entity.URL, error)
+	LookupShortCode(ctx context.Context, shortCode string) (*entity.URL, error)
+	LookupURLs(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error)
+	ListURLs(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error)
+	ListURLsBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error)
+	ListAccessEvents(ctx context.Context, shortCode string, before int64, limit int) ([]entity.AccessEvent, error)
+	GetCreator(ctx context.Context, shortCode string) (*entity.Creator, error)
+	UpsertURL(ctx context.Context, shortCode, originalURL, note string, tags []string, ip string) (*entity.URL, bool, error)
+	RenameShortCode(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error)
+	UpdateURL(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error)
 	DeactivateURL(ctx context.Context, shortCode string) error
+	ResetStats(ctx context.Context, shortCode string) error
+	AddAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error)
+	ShortCodeExists(ctx context.Context, shortCode string) (bool, error)
 	GetURLStats(ctx context.Context, shortCode string) (*entity.URL, error)
+	GetAccessCounts(ctx context.Context, shortCodes []string) (map[string]int64, error)
+	GetSummary(ctx context.Context) (*entity.Summary, error)
+	GetDomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error)
+	CheckLinks(ctx context.Context, shortCodes []string) ([]entity.LinkCheck, error)
+}
+
+// urlHandlerConfig holds the settings of urlHandler, set by the router options.
+// If notFoundRedirectURL is set, requests to follow unknown short codes are redirected to it.
+// Requests to follow expired short codes are redirected to expiredRedirectURL if it is set,
+// or answered with a "this link has expired" page if expiredPage is set.
+// If interstitial is set, followed short codes are answered with a page linking to the original URL,
+// which redirects after interstitialDelay if it is positive, instead of an immediate redirect.
+// Redirects to original URLs may be cached for redirectCacheMaxAge. The links of URLs requested
+// by hypermedia clients are built from baseURL. Batch requests may have at most maxBatchSize short codes.
+// URLs are listed with listLimit and listSort unless the request sets the limit and order.
+type urlHandlerConfig struct {
+	notFoundRedirectURL string
+	expiredRedirectURL  string
+	expiredPage         bool
+	interstitial        bool
+	interstitialDelay   time.Duration
+	redirectCacheMaxAge time.Duration
+	baseURL             string
+	maxBatchSize        int
+	listLimit           int
+	listSort            string
 }
 
 // urlHandler handles HTTP requests related to URLs.
 type urlHandler struct {
+	urlHandlerConfig
 	useCase  urlUseCase
 	validate *validator.Validate
 }
 
-// newURLHandler creates a new instance of urlHandler with the provided use case and validator.
-func newURLHandler(useCase urlUseCase, validate *validator.Validate) *urlHandler {
+// newURLHandler creates a new instance of urlHandler with the provided use case, validator and settings.
+func newURLHandler(useCase urlUseCase, validate *validator.Validate, cfg urlHandlerConfig) *urlHandler {
 	validate.RegisterTagNameFunc(func(fld reflect.StructField) string {
 		name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
 		if name == "-" {
@@ -49,10 +251,21 @@ func newURLHandler(useCase urlUseCase, validate *validator.Validate) *urlHandler
 		}
 		return name
 	})
+	mustRegisterValidation(validate, "httpurl", validateHTTPURL)
+	mustRegisterValidation(validate, "shortcode", validateShortCode)
 
 	return &urlHandler{
-		useCase:  useCase,
-		validate: validate,
+		urlHandlerConfig: cfg,
+		useCase:          useCase,
+		validate:         validate,
+	}
+}
+
+// mustRegisterValidation registers the validation function under the tag. It panics if the tag is invalid,
+// since requests couldn't be validated without the validation.
+func mustRegisterValidation(validate *validator.Validate, tag string, fn validator.Func) {
+	if err := validate.RegisterValidation(tag, fn); err != nil {
+		panic(fmt.Sprintf("register %s validation: %v", tag, err))
 	}
 }
 
@@ -61,98 +274,468 @@ func (h *urlHandler) shortenURL(w http.ResponseWriter, r *http.Request) {
 	var req urlRequest
 
 	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		if errors.Is(err, io.EOF) {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, emptyRequestBodyResponse)
-			return
-		}
-
 		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, invalidRequestBodyResponse)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
 		return
 	}
 
+	req.trimSpace()
+
 	if err := h.validate.Struct(req); err != nil {
 		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, validationErrorResponse(err))
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
 		return
 	}
 
-	url, err := h.useCase.ShortenURL(r.Context(), req.OriginalURL)
+	url, err := h.useCase.ShortenURL(r.Context(), entity.ShortenInput{
+		OriginalURL: req.OriginalURL,
+		Note:        req.Note,
+		Tags:        req.Tags,
+		UTM:         req.toUTM(),
+		Tier:        req.Tier,
+		Encoding:    req.Encoding,
+		Creator: entity.Creator{
+			IP:        r.RemoteAddr,
+			UserAgent: r.UserAgent(),
+		},
+	})
 	if err != nil {
-		httplog.LogEntrySetField(r.Context(), "err", slog.AnyValue(err))
+		renderError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", urlLocation(url.ShortCode))
+	render.Status(r, http.StatusCreated)
+	renderJSON(w, r, toURLResponse(url, linkerFor(r, h.baseURL)))
+}
+
+// cloneURL handles the request to create a new short code for the original URL of an existing one.
+func (h *urlHandler) cloneURL(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, serverErrorResponse)
+	url, err := h.useCase.CloneURL(r.Context(), shortCode, r.RemoteAddr)
+	if err != nil {
+		renderError(w, r, err)
 		return
 	}
 
+	w.Header().Set("Location", urlLocation(url.ShortCode))
 	render.Status(r, http.StatusCreated)
-	render.JSON(w, r, toURLResponse(url))
+	renderJSON(w, r, toURLResponse(url, linkerFor(r, h.baseURL)))
+}
+
+// reserveShortCode handles the request to reserve a short code before the original URL is submitted.
+func (h *urlHandler) reserveShortCode(w http.ResponseWriter, r *http.Request) {
+	url, err := h.useCase.ReserveShortCode(r.Context(), r.RemoteAddr)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	renderJSON(w, r, toReservationResponse(url))
 }
 
 // resolveShortCode handles the request to resolve a shortened URL.
+// The click isn't counted in the statistics if the track query parameter is false.
 func (h *urlHandler) resolveShortCode(w http.ResponseWriter, r *http.Request) {
 	shortCode := chi.URLParam(r, "shortCode")
 
-	url, err := h.useCase.ResolveShortCode(r.Context(), shortCode)
+	fields, err := parseFields[urlResponse](r.URL.Query())
 	if err != nil {
-		if errors.Is(err, entity.ErrURLNotFound) {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, urlNotFoundResponse)
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, unknownFieldResponse(err)))
+		return
+	}
+
+	track := true
+	if v := r.URL.Query().Get("track"); v != "" {
+		var err error
+		if track, err = strconv.ParseBool(v); err != nil {
+			render.Status(r, http.StatusBadRequest)
+			renderJSON(w, r, withRequestID(r, invalidQueryParamsResponse))
 			return
 		}
+	}
+
+	var url *entity.URL
+
+	if track {
+		click := entity.Click{
+			Referrer:  r.Referer(),
+			UserAgent: r.UserAgent(),
+			IP:        r.RemoteAddr,
+		}
 
-		httplog.LogEntrySetField(r.Context(), "err", slog.AnyValue(err))
+		url, err = h.useCase.ResolveShortCode(r.Context(), shortCode, click)
+	} else {
+		url, err = h.useCase.LookupShortCode(r.Context(), shortCode)
+	}
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, serverErrorResponse)
+	if err != nil {
+		renderError(w, r, err)
 		return
 	}
 
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, toURLResponse(url))
+	renderJSON(w, r, fields.apply(toURLResponse(url, linkerFor(r, h.baseURL))))
 }
 
-// modifyURL handles the request to modify an existing shortened URL.
-func (h *urlHandler) modifyURL(w http.ResponseWriter, r *http.Request) {
-	var req urlRequest
+// redirectShortCode handles the request to follow a short code: it counts the click and redirects
+// to the original URL with 302 Found, or answers with the interstitial page if it is enabled,
+// with caching headers set by redirectCacheControl. HEAD requests get the same status and headers,
+// without a body and without counting the click.
+func (h *urlHandler) redirectShortCode(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
 
-	if err := render.DecodeJSON(r.Body, &req); err != nil {
-		if errors.Is(err, io.EOF) {
-			render.Status(r, http.StatusBadRequest)
-			render.JSON(w, r, emptyRequestBodyResponse)
+	click := entity.Click{
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		IP:        r.RemoteAddr,
+	}
+
+	var (
+		url *entity.URL
+		err error
+	)
+
+	// HEAD requests are made by crawlers and link checkers rather than visitors, so they aren't counted as clicks.
+	if r.Method == http.MethodHead {
+		url, err = h.useCase.LookupShortCode(r.Context(), shortCode)
+	} else {
+		url, err = h.useCase.ResolveShortCode(r.Context(), shortCode, click)
+	}
+	if err != nil {
+		// The short code may be created or reactivated later, so the outcome isn't cacheable.
+		w.Header().Set("Cache-Control", "no-store")
+
+		if errors.Is(err, entity.ErrURLExpired) && (h.expiredRedirectURL != "" || h.expiredPage) {
+			h.renderExpired(w, r)
 			return
 		}
 
+		h.renderUnresolved(w, r, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", redirectCacheControl(url, h.redirectCacheMaxAge))
+
+	if h.interstitial {
+		h.renderInterstitial(w, url.OriginalURL)
+		return
+	}
+
+	http.Redirect(w, r, url.OriginalURL, http.StatusFound)
+}
+
+// interstitialPage is the page answering requests to follow short codes if the interstitial page is enabled.
+// The click has been counted by the time the page is served, so it links to the original URL directly.
+var interstitialPage = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+{{- if .Delay}}
+<meta http-equiv="refresh" content="{{.Delay}};url={{.URL}}">
+{{- end}}
+<title>You are leaving for another site</title>
+</head>
+<body>
+<h1>You are leaving for another site</h1>
+<p>The link you followed points at <strong>{{.URL}}</strong>.</p>
+{{- if .Delay}}
+<p>You will be redirected in {{.Delay}} seconds.</p>
+{{- end}}
+<p><a href="{{.URL}}" rel="noreferrer">Continue</a></p>
+</body>
+</html>
+`))
+
+// renderInterstitial renders the interstitial page linking to the original URL with 200 OK.
+// The delay is rounded up to whole seconds, since that's what the refresh of the page supports.
+func (h *urlHandler) renderInterstitial(w http.ResponseWriter, originalURL string) {
+	data := struct {
+		URL   string
+		Delay int
+	}{
+		URL:   originalURL,
+		Delay: int(math.Ceil(h.interstitialDelay.Seconds())),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_ = interstitialPage.Execute(w, data)
+}
+
+// renderUnresolved renders the response to a short code that couldn't be resolved to its original URL.
+// Short codes that never existed are answered with 404 Not Found, while short codes whose URL was
+// deactivated or has expired are answered with 410 Gone, which clients and crawlers treat as permanent.
+// Short codes that never existed are redirected to notFoundRedirectURL instead if it is set.
+func (h *urlHandler) renderUnresolved(w http.ResponseWriter, r *http.Request, err error) {
+	status, _ := httpStatusFor(err)
+	if h.notFoundRedirectURL != "" && status == http.StatusNotFound {
+		http.Redirect(w, r, h.notFoundRedirectURL, http.StatusFound)
+		return
+	}
+
+	renderError(w, r, err)
+}
+
+// expiredPage is the page answering requests to follow expired short codes if the expired page is enabled.
+const expiredPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Link expired</title>
+</head>
+<body>
+<h1>This link has expired</h1>
+<p>The link you followed is no longer available.</p>
+</body>
+</html>
+`
+
+// renderExpired renders the response to following an expired short code: a redirect to
+// expiredRedirectURL if it is set, and the expired page with 410 Gone otherwise.
+func (h *urlHandler) renderExpired(w http.ResponseWriter, r *http.Request) {
+	if h.expiredRedirectURL != "" {
+		http.Redirect(w, r, h.expiredRedirectURL, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusGone)
+	_, _ = io.WriteString(w, expiredPage)
+}
+
+// redirectCacheControl returns the Cache-Control header of the redirect to the original URL of url.
+// Redirects of expiring URLs are never cached, so that caches don't outlive them, and neither are
+// any redirects if maxAge is not positive. Other redirects may be cached by shared caches, e.g. CDNs,
+// for maxAge, during which repeated clicks don't reach the service and aren't counted.
+func redirectCacheControl(url *entity.URL, maxAge time.Duration) string {
+	if maxAge <= 0 || !url.ExpiresAt.IsZero() {
+		return "no-store"
+	}
+
+	return "public, max-age=" + strconv.FormatInt(int64(maxAge/time.Second), 10)
+}
+
+// lookupURLs handles the request to retrieve the URLs of several short codes at once, e.g. for dashboards.
+// Unknown short codes are reported as missing rather than failing the request.
+func (h *urlHandler) lookupURLs(w http.ResponseWriter, r *http.Request) {
+	var req lookupRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	if len(req.ShortCodes) > h.maxBatchSize {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, batchTooLargeResponse(h.maxBatchSize)))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	urls, err := h.useCase.LookupURLs(r.Context(), req.ShortCodes)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, toLookupResponse(urls, req.ShortCodes, linkerFor(r, h.baseURL)))
+}
+
+// getAccessCounts handles the request to retrieve the access counts of several short codes at once,
+// e.g. for dashboards. Unknown short codes are reported with a null count rather than failing the request.
+func (h *urlHandler) getAccessCounts(w http.ResponseWriter, r *http.Request) {
+	var req lookupRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	if len(req.ShortCodes) > h.maxBatchSize {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, batchTooLargeResponse(h.maxBatchSize)))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	counts, err := h.useCase.GetAccessCounts(r.Context(), req.ShortCodes)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, toAccessCountsResponse(counts, req.ShortCodes))
+}
+
+// listURLs handles the request to list shortened URLs, optionally filtered by a search query.
+// URLs are paged by offset by default, or by cursor if requested, which stays efficient on large tables.
+// Adjacent pages are linked in the Link header as well as in the body. Requests without a limit or sort
+// get the configured defaults.
+func (h *urlHandler) listURLs(w http.ResponseWriter, r *http.Request) {
+	req := listRequest{
+		Limit:      h.listLimit,
+		Sort:       h.listSort,
+		Pagination: paginationOffset,
+	}
+
+	if err := decodeQuery(r.URL.Query(), &req); err != nil {
 		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, invalidRequestBodyResponse)
+		renderJSON(w, r, withRequestID(r, invalidQueryParamsResponse))
 		return
 	}
 
 	if err := h.validate.Struct(req); err != nil {
 		render.Status(r, http.StatusBadRequest)
-		render.JSON(w, r, validationErrorResponse(err))
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	fields, err := parseFields[urlResponse](r.URL.Query())
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, unknownFieldResponse(err)))
+		return
+	}
+
+	var urls []entity.URL
+
+	if req.Pagination == paginationCursor {
+		urls, err = h.useCase.ListURLsBefore(r.Context(), req.Query, req.Tags, req.Cursor, req.Limit)
+	} else {
+		urls, err = h.useCase.ListURLs(r.Context(), req.Query, req.Tags, entity.URLSort(req.Sort), req.Limit, req.Offset)
+	}
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	resp := toURLListResponse(urls, req, linkerFor(r, h.baseURL))
+	setURLListLinks(w, r, h.baseURL, req, resp)
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, fields.applyToList(resp))
+}
+
+// upsertURL handles the request to create a shortened URL with the short code as a custom alias,
+// or to modify the original URL if the short code exists. It responds with 201 Created and the
+// location of the URL if it was created, and with 200 OK if it was modified.
+func (h *urlHandler) upsertURL(w http.ResponseWriter, r *http.Request) {
+	var req urlRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	req.trimSpace()
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
 		return
 	}
 
 	shortCode := chi.URLParam(r, "shortCode")
 
-	url, err := h.useCase.ModifyURL(r.Context(), shortCode, req.OriginalURL)
+	url, created, err := h.useCase.UpsertURL(r.Context(), shortCode, req.OriginalURL, req.Note, req.Tags, r.RemoteAddr)
 	if err != nil {
-		if errors.Is(err, entity.ErrURLNotFound) {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, urlNotFoundResponse)
-			return
-		}
+		renderError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		w.Header().Set("Location", urlLocation(url.ShortCode))
+		status = http.StatusCreated
+	}
+
+	render.Status(r, status)
+	renderJSON(w, r, toURLResponse(url, linkerFor(r, h.baseURL)))
+}
+
+// renameShortCode handles the request to change the short code of a shortened URL.
+func (h *urlHandler) renameShortCode(w http.ResponseWriter, r *http.Request) {
+	var req shortCodeRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, serverErrorResponse)
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	shortCode := chi.URLParam(r, "shortCode")
+
+	url, err := h.useCase.RenameShortCode(r.Context(), shortCode, req.ShortCode)
+	if err != nil {
+		renderError(w, r, err)
 		return
 	}
 
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, toURLResponse(url))
+	renderJSON(w, r, toURLResponse(url, linkerFor(r, h.baseURL)))
+}
+
+// updateURL handles the request to update several fields of a shortened URL at once,
+// e.g. to enable or disable it without deleting it. Fields missing from the request are left unchanged.
+func (h *urlHandler) updateURL(w http.ResponseWriter, r *http.Request) {
+	var req updateRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	req.trimSpace()
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	shortCode := chi.URLParam(r, "shortCode")
+
+	update := req.toURLUpdate()
+	if update.IsEmpty() {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, emptyUpdateResponse))
+		return
+	}
+
+	url, err := h.useCase.UpdateURL(r.Context(), shortCode, update)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, toURLResponse(url, linkerFor(r, h.baseURL)))
 }
 
 // deactivateURL handles the request to deactivate a shortened URL.
@@ -161,37 +744,290 @@ func (h *urlHandler) deactivateURL(w http.ResponseWriter, r *http.Request) {
 
 	err := h.useCase.DeactivateURL(r.Context(), shortCode)
 	if err != nil {
-		if errors.Is(err, entity.ErrURLNotFound) {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, urlNotFoundResponse)
-			return
-		}
+		renderError(w, r, err)
+		return
+	}
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, serverErrorResponse)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resetStats handles the request to reset the statistics of a URL, e.g. when restarting a campaign.
+func (h *urlHandler) resetStats(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
+
+	if err := h.useCase.ResetStats(r.Context(), shortCode); err != nil {
+		renderError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// addAlias handles the request to add an alias to a shortened URL, e.g. for a variant of a campaign.
+// The alias resolves to the URL, and the accesses through it are reported in the statistics of the URL.
+func (h *urlHandler) addAlias(w http.ResponseWriter, r *http.Request) {
+	var req shortCodeRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	shortCode := chi.URLParam(r, "shortCode")
+
+	alias, err := h.useCase.AddAlias(r.Context(), shortCode, req.ShortCode)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", urlLocation(alias.ShortCode))
+	render.Status(r, http.StatusCreated)
+	renderJSON(w, r, toAliasResponse(alias))
+}
+
+// shortCodeExists handles the request to check whether a short code is taken. It responds with
+// 200 OK or 404 Not Found without a body and doesn't count an access to the URL.
+func (h *urlHandler) shortCodeExists(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
+
+	exists, err := h.useCase.ShortCodeExists(r.Context(), shortCode)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	if !exists {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
 // getURLStats handles the request to retrieve statistics for a shortened URL.
 func (h *urlHandler) getURLStats(w http.ResponseWriter, r *http.Request) {
 	shortCode := chi.URLParam(r, "shortCode")
 
+	fields, err := parseFields[urlStatsResponse](r.URL.Query())
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, unknownFieldResponse(err)))
+		return
+	}
+
 	url, err := h.useCase.GetURLStats(r.Context(), shortCode)
 	if err != nil {
-		if errors.Is(err, entity.ErrURLNotFound) {
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, urlNotFoundResponse)
-			return
+		renderError(w, r, err)
+		return
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	if wantsCSV(r) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", url.ShortCode+"-stats.csv"))
+		w.WriteHeader(http.StatusOK)
+
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(toURLStatsCSV(url)); err != nil {
+			httplog.LogEntrySetField(r.Context(), "err", slog.AnyValue(err))
+		}
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, fields.apply(toURLStatsResponse(url)))
+}
+
+// getAccessEvents handles the request to list the recorded accesses to a URL, most recent first.
+// Pages are chained with the before parameter set to the next_before value of the previous page,
+// which the next link of the Link header points at.
+func (h *urlHandler) getAccessEvents(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
+	req := eventsRequest{
+		Limit: defaultListLimit,
+	}
+
+	if err := decodeEventsQuery(r.URL.Query(), &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, invalidQueryParamsResponse))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	events, err := h.useCase.ListAccessEvents(r.Context(), shortCode, req.Before, req.Limit)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	resp := toAccessEventsResponse(events, req)
+	setAccessEventsLinks(w, r, h.baseURL, req, resp)
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, resp)
+}
+
+// getCreator handles the request to get the client that created a URL, for investigating abuse.
+func (h *urlHandler) getCreator(w http.ResponseWriter, r *http.Request) {
+	shortCode := chi.URLParam(r, "shortCode")
+
+	creator, err := h.useCase.GetCreator(r.Context(), shortCode)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, creatorResponse{
+		ShortCode: shortCode,
+		IP:        creator.IP,
+		UserAgent: creator.UserAgent,
+	})
+}
+
+// wantsCSV reports whether the client asked for a CSV representation, either with
+// the format query parameter or the Accept header. JSON is preferred unless text/csv
+// is listed before application/json in the Accept header.
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(accept, ";")
+
+		switch strings.ToLower(strings.TrimSpace(mediaType)) {
+		case "text/csv":
+			return true
+		case "application/json", "application/*", "*/*":
+			return false
 		}
+	}
+
+	return false
+}
+
+// getSummary handles the request to retrieve aggregate statistics across all shortened URLs.
+func (h *urlHandler) getSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.useCase.GetSummary(r.Context())
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, toSummaryResponse(summary))
+}
+
+// getDomainStats handles the request to retrieve the number of URLs and clicks per destination host,
+// e.g. to spot which domains dominate. The hosts may be narrowed down to a domain and its subdomains.
+func (h *urlHandler) getDomainStats(w http.ResponseWriter, r *http.Request) {
+	req := domainsRequest{
+		Limit: defaultListLimit,
+	}
+
+	if err := decodeDomainsQuery(r.URL.Query(), &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, invalidQueryParamsResponse))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	stats, err := h.useCase.GetDomainStats(r.Context(), req.Domain, req.Limit)
+	if err != nil {
+		renderError(w, r, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, toDomainListResponse(stats))
+}
+
+// checkLinks handles the request to check whether the original URLs of the given short codes,
+// or of all URLs if none are given, are reachable. Only one link check runs at a time.
+func (h *urlHandler) checkLinks(w http.ResponseWriter, r *http.Request) {
+	var req linkCheckRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
 
-		render.Status(r, http.StatusInternalServerError)
-		render.JSON(w, r, serverErrorResponse)
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	checks, err := h.useCase.CheckLinks(r.Context(), req.ShortCodes)
+	if err != nil {
+		renderError(w, r, err)
 		return
 	}
 
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, toURLStatsResponse(url))
+	renderJSON(w, r, toLinkCheckResponse(checks, req.ShortCodes))
+}
+
+// adminHandler handles HTTP requests to the admin endpoints that manage the service itself.
+type adminHandler struct {
+	validate *validator.Validate
+	readOnly *atomic.Bool
+}
+
+// newAdminHandler creates a new instance of adminHandler with the provided validator and read-only flag.
+func newAdminHandler(validate *validator.Validate, readOnly *atomic.Bool) *adminHandler {
+	return &adminHandler{
+		validate: validate,
+		readOnly: readOnly,
+	}
+}
+
+// getReadOnly handles the request to retrieve the state of the read-only mode.
+func (h *adminHandler) getReadOnly(w http.ResponseWriter, r *http.Request) {
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, readOnlyStateResponse{ReadOnly: h.readOnly.Load()})
+}
+
+// setReadOnly handles the request to enable or disable the read-only mode.
+func (h *adminHandler) setReadOnly(w http.ResponseWriter, r *http.Request) {
+	var req readOnlyRequest
+
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, decodeErrorResponse(err)))
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		render.Status(r, http.StatusBadRequest)
+		renderJSON(w, r, withRequestID(r, validationErrorResponse(err)))
+		return
+	}
+
+	h.readOnly.Store(*req.ReadOnly)
+	httplog.LogEntrySetField(r.Context(), "read_only", slog.BoolValue(*req.ReadOnly))
+
+	render.Status(r, http.StatusOK)
+	renderJSON(w, r, readOnlyStateResponse{ReadOnly: *req.ReadOnly})
 }
@@ -1,19 +1,33 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gavv/httpexpect/v2"
 	"github.com/go-chi/httplog/v2"
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
+	"github.com/vadimbarashkov/url-shortener/pkg/jwt"
 
 	httpMock "github.com/vadimbarashkov/url-shortener/mocks/http"
 )
@@ -33,7 +47,9 @@ func (suite *HandlersTestSuite) SetupSuite() {
 func (suite *HandlersTestSuite) SetupSubTest() {
 	suite.urlUseCaseMock = httpMock.NewMockUrlUseCase(suite.T())
 
-	router := NewRouter(suite.logger, suite.urlUseCaseMock)
+	router := NewRouter(suite.logger, suite.urlUseCaseMock,
+		WithTrustedProxies(netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")),
+	)
 	suite.server = httptest.NewServer(router)
 	suite.T().Cleanup(func() {
 		suite.server.Close()
@@ -57,6 +73,624 @@ func (suite *HandlersTestSuite) TestPing() {
 	})
 }
 
+func (suite *HandlersTestSuite) TestRoot() {
+	suite.Run("banner", func() {
+		resp := suite.e.GET("/").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("service", "url-shortener")
+		resp.Value("version").String().NotEmpty()
+		resp.HasValue("docs_url", "/swagger/index.html")
+	})
+
+	suite.Run("banner without docs", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithSwagger(false, ""))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			NotContainsKey("docs_url")
+	})
+
+	suite.Run("redirect", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRootRedirect("https://example.com/landing"))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/").
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusFound).
+			Header("Location").IsEqual("https://example.com/landing")
+	})
+}
+
+func (suite *HandlersTestSuite) TestSwagger() {
+	suite.Run("spec", func() {
+		suite.e.GET("/docs/swagger.yml").
+			Expect().
+			Status(http.StatusOK).
+			Body().NotEmpty()
+	})
+
+	suite.Run("ui", func() {
+		suite.e.GET("/swagger/index.html").
+			Expect().
+			Status(http.StatusOK)
+	})
+
+	suite.Run("custom path", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithSwagger(true, "/api-docs"))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/api-docs/index.html").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK)
+
+		e.GET("/swagger/index.html").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	suite.Run("disabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithSwagger(false, "/swagger"))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/swagger/index.html").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/docs/swagger.yml").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
+func (suite *HandlersTestSuite) TestUnmatchedRoutes() {
+	suite.Run("unknown path", func() {
+		obj := suite.e.GET("/api/v1/unknown").
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		obj.HasValue("status", statusError)
+		obj.HasValue("message", "not found")
+		obj.Value("request_id").String().NotEmpty()
+	})
+
+	suite.Run("wrong method", func() {
+		resp := suite.e.DELETE("/api/v1/ping").
+			Expect().
+			Status(http.StatusMethodNotAllowed)
+
+		resp.Header("Allow").IsEqual("GET")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", statusError)
+		obj.HasValue("message", "method not allowed")
+	})
+
+	suite.Run("wrong method of a short code", func() {
+		suite.e.POST("/api/v1/shorten/abc123").
+			Expect().
+			Status(http.StatusMethodNotAllowed).
+			Header("Allow").IsEqual("GET, HEAD, PUT, PATCH, DELETE")
+	})
+
+	suite.Run("disabled feature", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithFeatures(map[string]bool{FeatureList: false}))
+
+		httpexpect.Default(suite.T(), "").GET("/api/v1/shorten").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object().HasValue("message", "not found")
+	})
+}
+
+func (suite *HandlersTestSuite) TestRequestTimeout() {
+	suite.Run("handler runs too long", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Run(func(args mock.Arguments) {
+				<-args.Get(0).(context.Context).Done()
+			}).
+			Return(nil, context.DeadlineExceeded)
+
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRequestTimeout(50*time.Millisecond))
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.GET("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusServiceUnavailable).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "request timeout")
+		resp.ContainsKey("request_id")
+	})
+}
+
+func (suite *HandlersTestSuite) TestConcurrencyLimit() {
+	suite.Run("request over the limit", func() {
+		const limit = 2
+
+		entered := make(chan struct{})
+		release := make(chan struct{})
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Times(limit).
+			Run(func(args mock.Arguments) {
+				entered <- struct{}{}
+				<-release
+			}).
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
+
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithMaxConcurrentRequests(limit))
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		e := httpexpect.Default(suite.T(), server.URL)
+
+		var wg sync.WaitGroup
+		for range limit {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				e.GET("/api/v1/shorten/abc123").
+					Expect().
+					Status(http.StatusOK)
+			}()
+		}
+
+		for range limit {
+			<-entered
+		}
+
+		resp := e.GET("/api/v1/shorten/abc123").
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("1")
+		resp.JSON().Object().HasValue("message", "server is busy, try again later")
+
+		close(release)
+		wg.Wait()
+	})
+}
+
+func (suite *HandlersTestSuite) TestRecoverer() {
+	suite.Run("handler panics", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Run(func(args mock.Arguments) {
+				panic("unexpected state")
+			})
+
+		var logs bytes.Buffer
+		logger := httplog.NewLogger("", httplog.Options{JSON: true, Writer: &logs})
+		router := NewRouter(logger, suite.urlUseCaseMock)
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.GET("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusInternalServerError)
+
+		requestID := resp.Header("X-Request-ID").NotEmpty().Raw()
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.HasValue("message", "server error occurred")
+		obj.HasValue("request_id", requestID)
+		obj.NotContainsKey("stack")
+
+		suite.Contains(logs.String(), `"panic":"unexpected state"`)
+		suite.Contains(logs.String(), `"stack":"goroutine`)
+		suite.Contains(logs.String(), `"request_id":"`+requestID+`"`)
+	})
+}
+
+func (suite *HandlersTestSuite) TestOperationLogging() {
+	suite.Run("operation with short code", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		var logs bytes.Buffer
+		logger := httplog.NewLogger("", httplog.Options{JSON: true, Writer: &logs})
+		router := NewRouter(logger, suite.urlUseCaseMock)
+
+		httpexpect.Default(suite.T(), "").GET("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+
+		suite.Contains(logs.String(), `"operation":"resolve"`)
+		suite.Contains(logs.String(), `"short_code":"abc123"`)
+	})
+
+	suite.Run("operation without short code", func() {
+		var logs bytes.Buffer
+		logger := httplog.NewLogger("", httplog.Options{JSON: true, Writer: &logs})
+		router := NewRouter(logger, suite.urlUseCaseMock)
+
+		httpexpect.Default(suite.T(), "").POST("/api/v1/shorten").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		suite.Contains(logs.String(), `"operation":"shorten"`)
+		suite.NotContains(logs.String(), `"short_code"`)
+	})
+}
+
+func (suite *HandlersTestSuite) TestPrettyJSON() {
+	suite.Run("enabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithPrettyJSON(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/api/v1/shorten/abc.123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusBadRequest).
+			Body().HasPrefix("{\n  \"status\": \"error\",\n  \"message\": \"invalid short code\",\n")
+	})
+
+	suite.Run("disabled", func() {
+		suite.e.GET("/api/v1/shorten/abc.123").
+			Expect().
+			Status(http.StatusBadRequest).
+			Body().HasPrefix(`{"status":"error","message":"invalid short code",`)
+	})
+}
+
+func (suite *HandlersTestSuite) TestServerTiming() {
+	suite.Run("enabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithServerTiming(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/api/v1/shorten/abc.123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusBadRequest).
+			Header("Server-Timing").HasPrefix("total;dur=")
+	})
+
+	suite.Run("disabled", func() {
+		suite.e.GET("/api/v1/shorten/abc.123").
+			Expect().
+			Status(http.StatusBadRequest).
+			Headers().NotContainsKey("Server-Timing")
+	})
+}
+
+func (suite *HandlersTestSuite) TestLinks() {
+	url := &entity.URL{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com", Active: true}
+
+	suite.Run("not requested", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(url, nil)
+
+		suite.e.GET("/api/v1/shorten/abc123").
+			WithQuery("track", "false").
+			WithHeader("Accept", "application/json").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			NotContainsKey("_links")
+	})
+
+	suite.Run("root-relative", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(url, nil)
+
+		links := suite.e.GET("/api/v1/shorten/abc123").
+			WithQuery("track", "false").
+			WithHeader("Accept", "application/hal+json, application/json;q=0.9").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("_links").Object()
+
+		links.Value("self").Object().HasValue("href", "/api/v1/shorten/abc123")
+		links.Value("stats").Object().HasValue("href", "/api/v1/shorten/abc123/stats")
+		links.Value("redirect").Object().HasValue("href", "/api/v1/shorten/abc123/redirect")
+	})
+
+	suite.Run("base url", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithBaseURL("https://sho.rt/"))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortOldest, 20, 0).
+			Once().
+			Return([]entity.URL{*url}, nil)
+
+		e.GET("/api/v1/shorten").
+			WithHandler(router).
+			WithHeader("Accept", "application/hal+json").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("urls").Array().Value(0).Object().
+			Value("_links").Object().
+			Value("self").Object().HasValue("href", "https://sho.rt/api/v1/shorten/abc123")
+	})
+}
+
+func (suite *HandlersTestSuite) TestMetrics() {
+	suite.Run("enabled", func() {
+		metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Write([]byte("db_query_duration_seconds_count 1\n"))
+		})
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithMetricsHandler(metricsHandler))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/metrics").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK).
+			Body().Contains("db_query_duration_seconds_count 1")
+	})
+
+	suite.Run("disabled", func() {
+		suite.e.GET("/metrics").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
+func (suite *HandlersTestSuite) TestCORS() {
+	suite.Run("preflight", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithCORSMaxAge(10*time.Minute))
+
+		resp := httpexpect.Default(suite.T(), "").OPTIONS("/api/v1/shorten").
+			WithHandler(router).
+			WithHeader("Origin", "https://app.example.com").
+			WithHeader("Access-Control-Request-Method", http.MethodPost).
+			WithHeader("Access-Control-Request-Headers", "Content-Type").
+			Expect()
+
+		resp.Status(http.StatusOK)
+		resp.Header("Access-Control-Allow-Origin").IsEqual("https://app.example.com")
+		resp.Header("Access-Control-Allow-Methods").IsEqual(http.MethodPost)
+		resp.Header("Access-Control-Max-Age").IsEqual("600")
+	})
+
+	suite.Run("preflight with authorization", func() {
+		resp := suite.e.OPTIONS("/api/v1/urls/abc123").
+			WithHeader("Origin", "https://app.example.com").
+			WithHeader("Access-Control-Request-Method", http.MethodPut).
+			WithHeader("Access-Control-Request-Headers", "Authorization, Content-Type").
+			Expect()
+
+		resp.Status(http.StatusOK)
+		resp.Header("Access-Control-Allow-Origin").IsEqual("https://app.example.com")
+		resp.Header("Access-Control-Allow-Methods").IsEqual(http.MethodPut)
+		resp.Header("Access-Control-Allow-Headers").IsEqual("Authorization, Content-Type")
+	})
+
+	suite.Run("exposed headers", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
+
+		resp := suite.e.POST("/api/v1/shorten").
+			WithHeader("Origin", "https://app.example.com").
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect()
+
+		resp.Status(http.StatusCreated)
+		resp.Header("Access-Control-Allow-Origin").IsEqual("https://app.example.com")
+		resp.Header("Access-Control-Expose-Headers").IsEqual("Location, Link, X-Request-Id")
+	})
+
+	suite.Run("exposed server timing", func() {
+		tests := []struct {
+			name    string
+			headers []string
+			want    string
+		}{
+			{name: "appended", headers: []string{"Location"}, want: "Location, Server-Timing"},
+			{name: "already exposed", headers: []string{"Server-Timing", "Location"}, want: "Server-Timing, Location"},
+		}
+
+		for _, tt := range tests {
+			suite.Run(tt.name, func() {
+				router := NewRouter(suite.logger, suite.urlUseCaseMock, WithServerTiming(true), WithCORSExposedHeaders(tt.headers...))
+
+				resp := httpexpect.Default(suite.T(), "").GET("/readyz").
+					WithHandler(router).
+					WithHeader("Origin", "https://app.example.com").
+					Expect()
+
+				resp.Status(http.StatusOK)
+				resp.Header("Access-Control-Expose-Headers").IsEqual(tt.want)
+			})
+		}
+	})
+}
+
+func (suite *HandlersTestSuite) TestReadyz() {
+	suite.Run("without check", func() {
+		suite.e.GET("/readyz").
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEqual("ok")
+	})
+
+	suite.Run("check passes", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadinessCheck(func(context.Context) error {
+			return nil
+		}))
+
+		httpexpect.Default(suite.T(), "").GET("/readyz").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEqual("ok")
+	})
+
+	suite.Run("check fails", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadinessCheck(func(context.Context) error {
+			return errors.New("migrations pending")
+		}))
+
+		resp := httpexpect.Default(suite.T(), "").GET("/readyz").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusServiceUnavailable).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "service not ready")
+	})
+}
+
+func (suite *HandlersTestSuite) TestAdminRouter() {
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("db_query_duration_seconds_count 1\n"))
+	})
+
+	suite.Run("admin endpoints", func() {
+		_, admin := NewRouters(suite.logger, suite.urlUseCaseMock,
+			WithMetricsHandler(metricsHandler),
+			WithAdminToken("secret"),
+		)
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("GetSummary", mock.Anything).
+			Once().
+			Return(&entity.Summary{}, nil)
+
+		e.GET("/healthz").
+			WithHandler(admin).
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEqual("ok")
+
+		e.GET("/readyz").
+			WithHandler(admin).
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEqual("ok")
+
+		e.GET("/metrics").
+			WithHandler(admin).
+			Expect().
+			Status(http.StatusOK).
+			Body().Contains("db_query_duration_seconds_count 1")
+
+		e.GET("/admin/stats").
+			WithHandler(admin).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusOK)
+
+		e.GET("/api/v1/ping").
+			WithHandler(admin).
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	suite.Run("public router drops admin endpoints", func() {
+		public, _ := NewRouters(suite.logger, suite.urlUseCaseMock,
+			WithMetricsHandler(metricsHandler),
+			WithAdminToken("secret"),
+		)
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/metrics").
+			WithHandler(public).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/readyz").
+			WithHandler(public).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/api/v1/admin/stats").
+			WithHandler(public).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/api/v1/ping").
+			WithHandler(public).
+			Expect().
+			Status(http.StatusOK)
+	})
+}
+
+func (suite *HandlersTestSuite) TestRequestID() {
+	suite.Run("generated", func() {
+		suite.e.GET("/api/v1/ping").
+			Expect().
+			Status(http.StatusOK).
+			Header("X-Request-ID").NotEmpty()
+	})
+
+	suite.Run("propagated to error response", func() {
+		resp := suite.e.POST("/api/v1/shorten").
+			WithHeader("X-Request-ID", "test-request-id").
+			Expect().
+			Status(http.StatusBadRequest)
+
+		resp.Header("X-Request-ID").IsEqual("test-request-id")
+		resp.JSON().Object().HasValue("request_id", "test-request-id")
+	})
+}
+
+func (suite *HandlersTestSuite) TestInvalidShortCode() {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "too long", method: http.MethodGet, path: "/api/v1/shorten/" + strings.Repeat("a", 51)},
+		{name: "invalid characters", method: http.MethodGet, path: "/api/v1/shorten/abc.123"},
+		{name: "escaped characters", method: http.MethodDelete, path: "/api/v1/shorten/abc%20123"},
+		{name: "modify", method: http.MethodPut, path: "/api/v1/shorten/abc$123"},
+		{name: "stats", method: http.MethodGet, path: "/api/v1/shorten/abc!123/stats"},
+	}
+
+	for _, tt := range tests {
+		suite.Run(tt.name, func() {
+			resp := suite.e.Request(tt.method, tt.path).
+				Expect().
+				Status(http.StatusBadRequest).
+				JSON().Object()
+
+			resp.HasValue("status", "error")
+			resp.HasValue("message", "invalid short code")
+		})
+	}
+}
+
 func (suite *HandlersTestSuite) TestShortenURL() {
 	const path = "/api/v1/shorten"
 
@@ -81,28 +715,2605 @@ func (suite *HandlersTestSuite) TestShortenURL() {
 		resp.ContainsKey("message")
 	})
 
-	suite.Run("validation error", func() {
-		resp := suite.e.POST(path).
-			WithJSON(map[string]string{"original_url": "invalid url"}).
+	suite.Run("type mismatch", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "tags": "spring"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid request body")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "tags").
+			HasValue("message", "must be an array")
+	})
+
+	suite.Run("nested type mismatch", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "utm": map[string]any{"source": 1}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "utm.source").
+			HasValue("message", "must be a string")
+	})
+
+	suite.Run("truncated request body", func() {
+		resp := suite.e.POST(path).
+			WithHeader("Content-Type", "application/json").
+			WithText(`{"original_url": "https://exa`).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid request body: unexpected end of JSON input")
+		resp.NotContainsKey("errors")
+	})
+
+	suite.Run("malformed request body", func() {
+		resp := suite.e.POST(path).
+			WithHeader("Content-Type", "application/json").
+			WithText(`{"original_url": "https://example.com",}`).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid request body: malformed JSON at offset 40")
+	})
+
+	suite.Run("validation error", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "invalid url"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			ContainsKey("message")
+	})
+
+	suite.Run("non-http scheme", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "ftp://x"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			HasValue("message", "Only http and https URLs are allowed.")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.ContainsKey("message")
+	})
+
+	suite.Run("domain not allowed", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, entity.ErrDomainNotAllowed)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			HasValue("message", "domain is not allowed")
+	})
+
+	suite.Run("self-referential url", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://sho.rt/abc123"})).
+			Once().
+			Return(nil, entity.ErrSelfReferentialURL)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://sho.rt/abc123"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			HasValue("message", "must not point at this service")
+	})
+
+	suite.Run("creation cooldown", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, entity.ErrCreationCooldown)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusTooManyRequests).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "too many urls shortened from this ip address, try again later")
+	})
+
+	suite.Run("unknown tier", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com", Tier: "gold"})).
+			Once().
+			Return(nil, fmt.Errorf("shorten url: %w", entity.ErrUnknownTier))
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com", "tier": "gold"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "tier").
+			HasValue("message", "tier is unknown")
+	})
+
+	suite.Run("encoding not allowed", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com", Encoding: "hex"})).
+			Once().
+			Return(nil, fmt.Errorf("shorten url: %w", entity.ErrEncodingNotAllowed))
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com", "encoding": "hex"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "encoding").
+			HasValue("message", "encoding is not allowed")
+	})
+
+	suite.Run("request canceled", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, fmt.Errorf("save url: %w", context.Canceled))
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(statusClientClosedRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "request canceled")
+	})
+
+	suite.Run("deadline exceeded", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, fmt.Errorf("save url: %w", context.DeadlineExceeded))
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusServiceUnavailable).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "request timeout")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+			}, nil)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusCreated)
+
+		resp.Header("Location").IsEqual("/api/v1/shorten/abc123")
+
+		obj := resp.JSON().Object()
+		obj.ContainsKey("id")
+		obj.HasValue("short_code", "abc123")
+		obj.HasValue("original_url", "https://example.com")
+		obj.NotContainsKey("stats")
+		obj.Value("tags").Array().IsEmpty()
+		obj.ContainsKey("created_at")
+		obj.ContainsKey("updated_at")
+	})
+
+	suite.Run("surrounding whitespace", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+			}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]string{"original_url": "  https://example.com\n"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			HasValue("original_url", "https://example.com")
+	})
+
+	suite.Run("invalid tags", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "tags": []string{"spring", ""}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Length().IsEqual(1)
+	})
+
+	suite.Run("with tags", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com", Tags: []string{"spring", "email"}})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				Tags:        []string{"spring", "email"},
+			}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "tags": []string{"spring", "email"}}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			Value("tags").Array().IsEqual([]string{"spring", "email"})
+	})
+
+	suite.Run("with creator", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{
+				OriginalURL: "https://example.com",
+				Creator:     entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"},
+			})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				Creator:     entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"},
+			}, nil)
+
+		resp := suite.e.POST(path).
+			WithHeader("X-Forwarded-For", "203.0.113.7").
+			WithHeader("User-Agent", "curl/8.0").
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusCreated)
+
+		resp.JSON().Object().NotContainsKey("creator").NotContainsKey("ip").NotContainsKey("user_agent")
+		resp.Body().NotContains("203.0.113.7")
+	})
+
+	suite.Run("note too long", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "note": strings.Repeat("a", 256)}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "note")
+	})
+
+	suite.Run("with note", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com", Note: "spring newsletter"})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				Note:        "spring newsletter",
+			}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"original_url": "https://example.com", "note": "spring newsletter"}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			HasValue("note", "spring newsletter")
+	})
+
+	suite.Run("invalid utm", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{
+				"original_url": "https://example.com",
+				"utm":          map[string]string{"source": strings.Repeat("a", 256)},
+			}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Length().IsEqual(1)
+	})
+
+	suite.Run("with utm", func() {
+		suite.urlUseCaseMock.
+			On("ShortenURL", mock.Anything, shortenInput(entity.ShortenInput{OriginalURL: "https://example.com", UTM: entity.UTM{Source: "newsletter", Campaign: "spring"}})).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com?utm_campaign=spring&utm_source=newsletter",
+			}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{
+				"original_url": "https://example.com",
+				"utm":          map[string]string{"source": "newsletter", "campaign": "spring"},
+			}).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object().
+			HasValue("original_url", "https://example.com?utm_campaign=spring&utm_source=newsletter")
+	})
+}
+
+func (suite *HandlersTestSuite) TestListURLs() {
+	const path = "/api/v1/shorten"
+
+	suite.Run("invalid query parameters", func() {
+		resp := suite.e.GET(path).
+			WithQuery("limit", "ten").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid query parameters")
+	})
+
+	suite.Run("validation error", func() {
+		resp := suite.e.GET(path).
+			WithQuery("offset", "-1").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "offset")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortOldest, defaultListLimit, 0).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.GET(path).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "example.com", []string(nil), entity.URLSortOldest, maxListLimit, 10).
+			Once().
+			Return([]entity.URL{
+				{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"},
+			}, nil)
+
+		resp := suite.e.GET(path).
+			WithQuery("q", "example.com").
+			WithQuery("limit", "1000").
+			WithQuery("offset", "10").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("limit", maxListLimit)
+		resp.HasValue("offset", 10)
+		resp.Value("urls").Array().Length().IsEqual(1)
+		resp.Value("urls").Array().Value(0).Object().HasValue("short_code", "abc123")
+	})
+
+	suite.Run("filter by tags", func() {
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string{"spring", "email"}, entity.URLSortOldest, defaultListLimit, 0).
+			Once().
+			Return([]entity.URL{
+				{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com", Tags: []string{"spring", "email"}},
+			}, nil)
+
+		resp := suite.e.GET(path).
+			WithQuery("tag", "spring").
+			WithQuery("tag", "email").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.Value("urls").Array().Length().IsEqual(1)
+	})
+
+	suite.Run("sort", func() {
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortShortCode, defaultListLimit, 0).
+			Once().
+			Return([]entity.URL{{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"}}, nil)
+
+		suite.e.GET(path).
+			WithQuery("sort", "short_code").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("urls").Array().Length().IsEqual(1)
+	})
+
+	suite.Run("invalid sort", func() {
+		resp := suite.e.GET(path).
+			WithQuery("sort", "access_count").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "sort")
+	})
+
+	suite.Run("configured defaults", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithListDefaults(5, "newest"))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortNewest, 5, 0).
+			Once().
+			Return([]entity.URL{{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"}}, nil)
+
+		e.GET(path).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("limit", 5)
+
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortOldest, 10, 0).
+			Once().
+			Return([]entity.URL{{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"}}, nil)
+
+		e.GET(path).
+			WithHandler(router).
+			WithQuery("limit", "10").
+			WithQuery("sort", "oldest").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("limit", 10)
+	})
+
+	suite.Run("invalid pagination", func() {
+		resp := suite.e.GET(path).
+			WithQuery("pagination", "page").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "pagination")
+
+		suite.e.GET(path).
+			WithQuery("pagination", "cursor").
+			WithQuery("cursor", "abc").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object().
+			HasValue("message", "invalid query parameters")
+	})
+
+	suite.Run("cursor pagination", func() {
+		suite.urlUseCaseMock.
+			On("ListURLsBefore", mock.Anything, "", []string(nil), int64(10), 2).
+			Once().
+			Return([]entity.URL{
+				{ID: 9, ShortCode: "def456", OriginalURL: "https://example.com/b"},
+				{ID: 7, ShortCode: "abc123", OriginalURL: "https://example.com/a"},
+			}, nil)
+
+		resp := suite.e.GET(path).
+			WithQuery("pagination", "cursor").
+			WithQuery("cursor", "10").
+			WithQuery("limit", "2").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("limit", 2)
+		resp.HasValue("next_cursor", 7)
+		resp.Value("urls").Array().Length().IsEqual(2)
+	})
+
+	suite.Run("cursor pagination last page", func() {
+		suite.urlUseCaseMock.
+			On("ListURLsBefore", mock.Anything, "", []string(nil), int64(0), defaultListLimit).
+			Once().
+			Return([]entity.URL{{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"}}, nil)
+
+		resp := suite.e.GET(path).
+			WithQuery("pagination", "cursor").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.NotContainsKey("next_cursor")
+	})
+
+	suite.Run("link header", func() {
+		urls := []entity.URL{
+			{ID: 9, ShortCode: "def456", OriginalURL: "https://example.com/b"},
+			{ID: 7, ShortCode: "abc123", OriginalURL: "https://example.com/a"},
+		}
+
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "example", []string(nil), entity.URLSortOldest, 2, 3).
+			Once().
+			Return(urls, nil)
+
+		suite.e.GET(path).
+			WithQuery("q", "example").
+			WithQuery("limit", "2").
+			WithQuery("offset", "3").
+			Expect().
+			Status(http.StatusOK).
+			Header("Link").IsEqual(`</api/v1/shorten?limit=2&q=example>; rel="first", ` +
+			`</api/v1/shorten?limit=2&offset=1&q=example>; rel="prev", ` +
+			`</api/v1/shorten?limit=2&offset=5&q=example>; rel="next"`)
+
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortOldest, defaultListLimit, 0).
+			Once().
+			Return(urls, nil)
+
+		suite.e.GET(path).
+			Expect().
+			Status(http.StatusOK).
+			Header("Link").IsEqual(`</api/v1/shorten?limit=20>; rel="first"`)
+
+		suite.urlUseCaseMock.
+			On("ListURLsBefore", mock.Anything, "", []string(nil), int64(10), 2).
+			Once().
+			Return(urls, nil)
+
+		suite.e.GET(path).
+			WithQuery("pagination", "cursor").
+			WithQuery("cursor", "10").
+			WithQuery("limit", "2").
+			Expect().
+			Status(http.StatusOK).
+			Header("Link").IsEqual(`</api/v1/shorten?limit=2&pagination=cursor>; rel="first", ` +
+			`</api/v1/shorten?cursor=7&limit=2&pagination=cursor>; rel="next"`)
+	})
+}
+
+func (suite *HandlersTestSuite) TestLookupURLs() {
+	const path = "/api/v1/shorten/lookup"
+
+	suite.Run("empty request body", func() {
+		resp := suite.e.POST(path).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "empty request body")
+	})
+
+	suite.Run("validation error", func() {
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"abc123", "abc.123"}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Length().IsEqual(1)
+	})
+
+	suite.Run("no short codes", func() {
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	suite.Run("batch size", func() {
+		shortCodes := make([]string, defaultMaxBatchSize+1)
+		for i := range shortCodes {
+			shortCodes[i] = fmt.Sprintf("code%d", i)
+		}
+
+		suite.urlUseCaseMock.
+			On("LookupURLs", mock.Anything, shortCodes[:defaultMaxBatchSize]).
+			Once().
+			Return(map[string]*entity.URL{}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": shortCodes[:defaultMaxBatchSize]}).
+			Expect().
+			Status(http.StatusOK)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": shortCodes}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "short_codes").
+			HasValue("message", "at most 100 short codes are allowed")
+	})
+
+	suite.Run("custom batch size", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithMaxBatchSize(2))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("LookupURLs", mock.Anything, []string{"abc123", "def456"}).
+			Once().
+			Return(map[string]*entity.URL{}, nil)
+
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123", "def456"}}).
+			Expect().
+			Status(http.StatusOK)
+
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123", "def456", "ghi789"}}).
+			Expect().
+			Status(http.StatusBadRequest)
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("LookupURLs", mock.Anything, []string{"abc123"}).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"abc123"}}).
+			Expect().
+			Status(http.StatusInternalServerError)
+	})
+
+	suite.Run("existing and missing short codes", func() {
+		suite.urlUseCaseMock.
+			On("LookupURLs", mock.Anything, []string{"def456", "missing", "abc123", "def456"}).
+			Once().
+			Return(map[string]*entity.URL{
+				"abc123": {ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com", Active: true},
+				"def456": {ID: 2, ShortCode: "def456", OriginalURL: "https://example.org", Active: true},
+			}, nil)
+
+		resp := suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"def456", "missing", "abc123", "def456"}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		urls := resp.Value("urls").Array()
+		urls.Length().IsEqual(2)
+		urls.Value(0).Object().HasValue("short_code", "def456")
+		urls.Value(1).Object().HasValue("short_code", "abc123")
+		resp.Value("missing").Array().IsEqual([]string{"missing"})
+	})
+
+	suite.Run("read-only mode", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadOnly(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("LookupURLs", mock.Anything, []string{"abc123"}).
+			Once().
+			Return(map[string]*entity.URL{}, nil)
+
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123"}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			Value("missing").Array().IsEqual([]string{"abc123"})
+	})
+}
+
+func (suite *HandlersTestSuite) TestGetAccessCounts() {
+	const path = "/api/v1/shorten/stats"
+
+	suite.Run("empty request body", func() {
+		resp := suite.e.POST(path).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "empty request body")
+	})
+
+	suite.Run("validation error", func() {
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"abc.123"}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object().
+			HasValue("message", "validation error")
+	})
+
+	suite.Run("batch size exceeded", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithMaxBatchSize(1))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123", "def456"}}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object().
+			HasValue("message", "validation error")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("GetAccessCounts", mock.Anything, []string{"abc123"}).
+			Once().
+			Return(nil, entity.ErrDatabaseUnavailable)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"abc123"}}).
+			Expect().
+			Status(http.StatusServiceUnavailable).
+			Header("Retry-After").IsEqual("5")
+	})
+
+	suite.Run("existing and missing short codes", func() {
+		suite.urlUseCaseMock.
+			On("GetAccessCounts", mock.Anything, []string{"abc123", "def456", "missing"}).
+			Once().
+			Return(map[string]int64{"abc123": 3, "def456": 0}, nil)
+
+		suite.e.POST(path).
+			WithJSON(map[string]any{"short_codes": []string{"abc123", "def456", "missing"}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			IsEqual(map[string]any{"abc123": 3, "def456": 0, "missing": nil})
+	})
+
+	suite.Run("read-only mode", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadOnly(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("GetAccessCounts", mock.Anything, []string{"abc123"}).
+			Once().
+			Return(map[string]int64{"abc123": 1}, nil)
+
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123"}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("abc123", 1)
+	})
+}
+
+func (suite *HandlersTestSuite) TestCloneURL() {
+	const path = "/api/v1/shorten/%s/clone"
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("CloneURL", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("CloneURL", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("CloneURL", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(&entity.URL{
+				ID:          2,
+				ShortCode:   "def456",
+				OriginalURL: "https://example.com",
+				Active:      true,
+			}, nil)
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusCreated)
+
+		resp.Header("Location").IsEqual("/api/v1/shorten/def456")
+		resp.JSON().Object().HasValue("short_code", "def456")
+	})
+}
+
+func (suite *HandlersTestSuite) TestAddAlias() {
+	const path = "/api/v1/shorten/%s/aliases"
+
+	suite.Run("invalid request body", func() {
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"short_code": "a b"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("AddAlias", mock.Anything, "abc123", "alias1").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"short_code": "alias1"}).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("short code exists", func() {
+		suite.urlUseCaseMock.
+			On("AddAlias", mock.Anything, "abc123", "alias1").
+			Once().
+			Return(nil, entity.ErrShortCodeExists)
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"short_code": "alias1"}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("AddAlias", mock.Anything, "abc123", "alias1").
+			Once().
+			Return(&entity.URLAlias{ShortCode: "alias1", CanonicalShortCode: "abc123"}, nil)
+
+		resp := suite.e.POST(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"short_code": "alias1"}).
+			Expect().
+			Status(http.StatusCreated)
+
+		resp.Header("Location").IsEqual("/api/v1/shorten/alias1")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("short_code", "alias1")
+		obj.HasValue("canonical_short_code", "abc123")
+		obj.HasValue("access_count", int64(0))
+	})
+}
+
+func (suite *HandlersTestSuite) TestReserveShortCode() {
+	const path = "/api/v1/shorten/reserve"
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ReserveShortCode", mock.Anything, mock.Anything).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.POST(path).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ReserveShortCode", mock.Anything, mock.Anything).
+			Once().
+			Return(&entity.URL{
+				ShortCode: "abc123",
+				ExpiresAt: time.Now().Add(10 * time.Minute),
+			}, nil)
+
+		resp := suite.e.POST(path).
+			Expect().
+			Status(http.StatusCreated).
+			JSON().Object()
+
+		resp.HasValue("short_code", "abc123")
+		resp.ContainsKey("expires_at")
+		resp.NotContainsKey("original_url")
+	})
+}
+
+func (suite *HandlersTestSuite) TestResolveShortCode() {
+	path := "/api/v1/shorten/%s"
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", entity.Click{
+				Referrer:  "https://google.com",
+				UserAgent: "test-agent",
+				IP:        "203.0.113.1",
+			}).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				URLStats: entity.URLStats{
+					AccessCount: 1,
+				},
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithHeader("Referer", "https://google.com").
+			WithHeader("User-Agent", "test-agent").
+			WithHeader("X-Real-IP", "203.0.113.1").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.ContainsKey("id")
+		resp.HasValue("short_code", "abc123")
+		resp.HasValue("original_url", "https://example.com")
+		resp.NotContainsKey("stats")
+		resp.ContainsKey("created_at")
+		resp.ContainsKey("updated_at")
+	})
+
+	suite.Run("trailing slash", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Twice().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+			}, nil)
+
+		for _, p := range []string{fmt.Sprintf(path, "abc123"), fmt.Sprintf(path, "abc123") + "/"} {
+			suite.e.GET(p).
+				Expect().
+				Status(http.StatusOK).
+				JSON().Object().
+				HasValue("short_code", "abc123")
+		}
+	})
+
+	suite.Run("gone", func() {
+		for _, goneErr := range []error{entity.ErrURLDeactivated, entity.ErrURLExpired} {
+			suite.urlUseCaseMock.
+				On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+				Once().
+				Return(nil, fmt.Errorf("resolve short code: %w", goneErr))
+
+			resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+				Expect().
+				Status(http.StatusGone).
+				JSON().Object()
+
+			resp.HasValue("status", "error")
+			resp.HasValue("message", "url is no longer available")
+		}
+	})
+
+	suite.Run("gone without tracking", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrURLDeactivated)
+
+		suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithQuery("track", "false").
+			Expect().
+			Status(http.StatusGone)
+	})
+
+	suite.Run("not found redirect", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithNotFoundRedirect("https://example.com/home"))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		// API clients expect JSON, so only the redirect endpoint redirects unknown short codes.
+		e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object().HasValue("status", "error")
+	})
+
+	suite.Run("invalid track parameter", func() {
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithQuery("track", "maybe").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid query parameters")
+	})
+
+	suite.Run("without tracking", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				URLStats: entity.URLStats{
+					AccessCount: 3,
+				},
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithQuery("track", "false").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("short_code", "abc123")
+		resp.HasValue("original_url", "https://example.com")
+	})
+
+	suite.Run("without tracking not found", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithQuery("track", "0").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+}
+
+func (suite *HandlersTestSuite) TestRedirectShortCode() {
+	const path = "/api/v1/shorten/%s/redirect"
+
+	suite.Run("cache headers", func() {
+		tests := []struct {
+			name             string
+			maxAge           time.Duration
+			expiresAt        time.Time
+			wantCacheControl string
+		}{
+			{
+				name:             "caching disabled",
+				wantCacheControl: "no-store",
+			},
+			{
+				name:             "cached",
+				maxAge:           5 * time.Minute,
+				wantCacheControl: "public, max-age=300",
+			},
+			{
+				name:             "expiring url",
+				maxAge:           5 * time.Minute,
+				expiresAt:        time.Now().Add(time.Hour),
+				wantCacheControl: "no-store",
+			},
+		}
+
+		for _, tt := range tests {
+			router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRedirectCacheMaxAge(tt.maxAge))
+			e := httpexpect.Default(suite.T(), "")
+
+			suite.urlUseCaseMock.
+				On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+				Once().
+				Return(&entity.URL{
+					ShortCode:   "abc123",
+					OriginalURL: "https://example.com",
+					Active:      true,
+					ExpiresAt:   tt.expiresAt,
+				}, nil)
+
+			resp := e.GET(fmt.Sprintf(path, "abc123")).
+				WithHandler(router).
+				WithRedirectPolicy(httpexpect.DontFollowRedirects).
+				Expect().
+				Status(http.StatusFound)
+
+			resp.Header("Location").IsEqual("https://example.com")
+			resp.Header("Cache-Control").IsEqual(tt.wantCacheControl)
+		}
+	})
+
+	suite.Run("url not found", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRedirectCacheMaxAge(5*time.Minute))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+
+		resp.Header("Cache-Control").IsEqual("no-store")
+		resp.JSON().Object().HasValue("status", "error")
+	})
+
+	suite.Run("not found redirect", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock,
+			WithNotFoundRedirect("https://example.com/home"),
+			WithRedirectCacheMaxAge(5*time.Minute),
+		)
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusFound)
+
+		resp.Header("Location").IsEqual("https://example.com/home")
+		resp.Header("Cache-Control").IsEqual("no-store")
+	})
+
+	suite.Run("deactivated url with not found redirect", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithNotFoundRedirect("https://example.com/home"))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLDeactivated)
+
+		e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusGone)
+	})
+
+	suite.Run("deactivated url", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRedirectCacheMaxAge(5*time.Minute))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLDeactivated)
+
+		resp := e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusGone)
+
+		resp.Header("Cache-Control").IsEqual("no-store")
+		resp.JSON().Object().HasValue("message", "url is no longer available")
+	})
+
+	suite.Run("expired redirect", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock,
+			WithNotFoundRedirect("https://example.com/home"),
+			WithExpiredRedirect("https://example.com/expired"),
+		)
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLExpired)
+
+		resp := e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusFound)
+
+		resp.Header("Location").IsEqual("https://example.com/expired")
+		resp.Header("Cache-Control").IsEqual("no-store")
+
+		// Unknown short codes still use the not found redirect.
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "def456", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		e.GET(fmt.Sprintf(path, "def456")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusFound).
+			Header("Location").IsEqual("https://example.com/home")
+	})
+
+	suite.Run("expired page", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithExpiredPage(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLExpired)
+
+		resp := e.GET(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusGone)
+
+		resp.Header("Content-Type").IsEqual("text/html; charset=utf-8")
+		resp.Header("Cache-Control").IsEqual("no-store")
+		resp.Body().Contains("This link has expired")
+
+		// Deactivated URLs aren't expired and are still answered with a JSON error.
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "def456", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLDeactivated)
+
+		e.GET(fmt.Sprintf(path, "def456")).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusGone).
+			JSON().Object().HasValue("message", "url is no longer available")
+	})
+
+	suite.Run("interstitial", func() {
+		tests := []struct {
+			name        string
+			delay       time.Duration
+			wantRefresh string
+		}{
+			{
+				name: "continue button only",
+			},
+			{
+				name:        "delayed redirect",
+				delay:       2500 * time.Millisecond,
+				wantRefresh: `<meta http-equiv="refresh" content="3;url=https://example.com/a?b=1&amp;c=2">`,
+			},
+		}
+
+		for _, tt := range tests {
+			router := NewRouter(suite.logger, suite.urlUseCaseMock, WithInterstitial(true, tt.delay))
+			e := httpexpect.Default(suite.T(), "")
+
+			suite.urlUseCaseMock.
+				On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+				Once().
+				Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com/a?b=1&c=2", Active: true}, nil)
+
+			resp := e.GET(fmt.Sprintf(path, "abc123")).
+				WithHandler(router).
+				WithRedirectPolicy(httpexpect.DontFollowRedirects).
+				Expect().
+				Status(http.StatusOK)
+
+			resp.Header("Content-Type").IsEqual("text/html; charset=utf-8")
+			resp.Header("Location").IsEmpty()
+
+			body := resp.Body()
+			body.Contains(`<a href="https://example.com/a?b=1&amp;c=2" rel="noreferrer">Continue</a>`)
+
+			if tt.wantRefresh != "" {
+				body.Contains(tt.wantRefresh)
+			} else {
+				body.NotContains(`http-equiv="refresh"`)
+			}
+		}
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+		resp.Header("Cache-Control").IsEqual("no-store")
+	})
+
+	suite.Run("head", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithRedirectCacheMaxAge(5*time.Minute))
+		e := httpexpect.Default(suite.T(), "")
+
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com", Active: true}, nil)
+
+		resp := e.HEAD(fmt.Sprintf(path, "abc123")).
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
+			Expect().
+			Status(http.StatusFound)
+
+		resp.Header("Location").IsEqual("https://example.com")
+		resp.Header("Cache-Control").IsEqual("public, max-age=300")
+		suite.urlUseCaseMock.AssertNotCalled(suite.T(), "ResolveShortCode", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	suite.Run("head url not found", func() {
+		suite.urlUseCaseMock.
+			On("LookupShortCode", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.HEAD(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound)
+
+		resp.Header("Cache-Control").IsEqual("no-store")
+		suite.urlUseCaseMock.AssertNotCalled(suite.T(), "ResolveShortCode", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func (suite *HandlersTestSuite) TestShortCodeExists() {
+	path := "/api/v1/shorten/%s"
+
+	suite.Run("not found", func() {
+		suite.urlUseCaseMock.
+			On("ShortCodeExists", mock.Anything, "abc123").
+			Once().
+			Return(false, nil)
+
+		suite.e.HEAD(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound).
+			Body().IsEmpty()
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("ShortCodeExists", mock.Anything, "abc123").
+			Once().
+			Return(false, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.HEAD(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ShortCodeExists", mock.Anything, "abc123").
+			Once().
+			Return(false, errors.New("unknown error"))
+
+		suite.e.HEAD(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusInternalServerError)
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ShortCodeExists", mock.Anything, "abc123").
+			Once().
+			Return(true, nil)
+
+		suite.e.HEAD(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEmpty()
+	})
+}
+
+func (suite *HandlersTestSuite) TestUpsertURL() {
+	const path = "/api/v1/shorten/%s"
+
+	suite.Run("empty request body", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("invalid request body", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON("invalid body").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("validation error", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "invalid url"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			ContainsKey("message")
+	})
+
+	suite.Run("non-http scheme", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "ftp://x"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "original_url").
+			HasValue("message", "Only http and https URLs are allowed.")
+	})
+
+	suite.Run("short code too short", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "abc", "https://new-example.com", "", []string(nil), mock.Anything).
+			Once().
+			Return(nil, false, entity.ErrShortCodeTooShort)
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "short_code")
+	})
+
+	suite.Run("expired short code", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "abc123", "https://new-example.com", "", []string(nil), mock.Anything).
+			Once().
+			Return(nil, false, entity.ErrShortCodeExists)
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "abc123", "https://new-example.com", "", []string(nil), mock.Anything).
+			Once().
+			Return(nil, false, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "abc123", "https://new-example.com", "", []string(nil), mock.Anything).
+			Once().
+			Return(nil, false, errors.New("unknown error"))
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("updated", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "abc123", "https://new-example.com", "", []string(nil), mock.Anything).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://new-example.com",
+			}, false, nil)
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusOK)
+
+		resp.Header("Location").IsEmpty()
+
+		obj := resp.JSON().Object()
+		obj.ContainsKey("id")
+		obj.HasValue("short_code", "abc123")
+		obj.HasValue("original_url", "https://new-example.com")
+		obj.NotContainsKey("stats")
+		obj.ContainsKey("created_at")
+		obj.ContainsKey("updated_at")
+	})
+
+	suite.Run("created", func() {
+		suite.urlUseCaseMock.
+			On("UpsertURL", mock.Anything, "my-alias", "https://example.com", "launch", []string{"spring"}, mock.Anything).
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "my-alias",
+				OriginalURL: "https://example.com",
+				Note:        "launch",
+				Tags:        []string{"spring"},
+			}, true, nil)
+
+		resp := suite.e.PUT(fmt.Sprintf(path, "my-alias")).
+			WithJSON(map[string]any{
+				"original_url": "https://example.com",
+				"note":         "launch",
+				"tags":         []string{"spring"},
+			}).
+			Expect().
+			Status(http.StatusCreated)
+
+		resp.Header("Location").IsEqual("/api/v1/shorten/my-alias")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("short_code", "my-alias")
+		obj.HasValue("note", "launch")
+	})
+}
+
+func (suite *HandlersTestSuite) TestRenameShortCode() {
+	const path = "/api/v1/shorten/%s/code"
+
+	suite.Run("empty request body", func() {
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("validation error", func() {
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "my alias!"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "short_code").
+			ContainsKey("message")
+	})
+
+	suite.Run("short code too short", func() {
+		suite.urlUseCaseMock.
+			On("RenameShortCode", mock.Anything, "abc123", "ab").
+			Once().
+			Return(nil, entity.ErrShortCodeTooShort)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "ab"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("message", "validation error")
+		resp.Value("errors").Array().Value(0).Object().
+			HasValue("field", "short_code").
+			HasValue("message", "short code is too short")
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("RenameShortCode", mock.Anything, "abc123", "my-alias").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "my-alias"}).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("short code exists", func() {
+		suite.urlUseCaseMock.
+			On("RenameShortCode", mock.Anything, "abc123", "my-alias").
+			Once().
+			Return(nil, entity.ErrShortCodeExists)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "my-alias"}).
+			Expect().
+			Status(http.StatusConflict).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("RenameShortCode", mock.Anything, "abc123", "my-alias").
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "my-alias"}).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("RenameShortCode", mock.Anything, "abc123", "my-alias").
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "my-alias",
+				OriginalURL: "https://example.com",
+			}, nil)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]string{"short_code": "my-alias"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("short_code", "my-alias")
+		resp.HasValue("original_url", "https://example.com")
+	})
+}
+
+func (suite *HandlersTestSuite) TestUpdateURL() {
+	const path = "/api/v1/shorten/%s"
+
+	suite.Run("empty request body", func() {
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "empty request body")
+	})
+
+	suite.Run("no fields", func() {
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"note": nil}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "no fields to update")
+	})
+
+	suite.Run("validation error", func() {
+		testCases := []struct {
+			name  string
+			body  map[string]any
+			field string
+		}{
+			{name: "empty original url", body: map[string]any{"original_url": ""}, field: "original_url"},
+			{name: "invalid original url", body: map[string]any{"original_url": "ftp://example.com"}, field: "original_url"},
+			{name: "too many tags", body: map[string]any{"tags": make([]string, 11)}, field: "tags"},
+			{name: "empty tag", body: map[string]any{"tags": []string{""}}, field: "tags[0]"},
+			{name: "long note", body: map[string]any{"note": strings.Repeat("a", 256)}, field: "note"},
+		}
+
+		for _, tc := range testCases {
+			suite.Run(tc.name, func() {
+				resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+					WithJSON(tc.body).
+					Expect().
+					Status(http.StatusBadRequest).
+					JSON().Object()
+
+				resp.HasValue("status", "error")
+				resp.Value("errors").Array().Value(0).Object().HasValue("field", tc.field)
+			})
+		}
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("UpdateURL", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"active": false}).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("domain not allowed", func() {
+		suite.urlUseCaseMock.
+			On("UpdateURL", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(nil, entity.ErrDomainNotAllowed)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"original_url": "https://blocked.example"}).
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("active only", func() {
+		active := false
+
+		suite.urlUseCaseMock.
+			On("UpdateURL", mock.Anything, "abc123", entity.URLUpdate{Active: &active}).
+			Once().
+			Return(&entity.URL{
+				ID:          1,
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				Active:      false,
+			}, nil)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"active": false}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("short_code", "abc123")
+		resp.HasValue("active", false)
+		resp.NotContainsKey("expires_at")
+	})
+
+	suite.Run("zero values", func() {
+		note := ""
+		tags := []string{}
+
+		suite.urlUseCaseMock.
+			On("UpdateURL", mock.Anything, "abc123", entity.URLUpdate{Tags: &tags, Note: &note}).
+			Once().
+			Return(&entity.URL{
+				ID:          1,
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				Active:      true,
+			}, nil)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{"note": "", "tags": []string{}}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("note", "")
+		resp.Value("tags").Array().IsEmpty()
+	})
+
+	suite.Run("several fields", func() {
+		originalURL := "https://example.com/new"
+		expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		tags := []string{"summer"}
+
+		suite.urlUseCaseMock.
+			On("UpdateURL", mock.Anything, "abc123", entity.URLUpdate{
+				OriginalURL: &originalURL,
+				ExpiresAt:   &expiresAt,
+				Tags:        &tags,
+			}).
+			Once().
+			Return(&entity.URL{
+				ID:          1,
+				ShortCode:   "abc123",
+				OriginalURL: originalURL,
+				Active:      true,
+				Tags:        tags,
+				Note:        "launch",
+				ExpiresAt:   expiresAt,
+			}, nil)
+
+		resp := suite.e.PATCH(fmt.Sprintf(path, "abc123")).
+			WithJSON(map[string]any{
+				"original_url": " https://example.com/new\n",
+				"expires_at":   "2030-01-01T00:00:00Z",
+				"tags":         []string{"summer"},
+			}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("original_url", originalURL)
+		resp.HasValue("expires_at", "2030-01-01T00:00:00Z")
+		resp.HasValue("tags", []string{"summer"})
+		resp.HasValue("note", "launch")
+	})
+}
+
+func (suite *HandlersTestSuite) TestDeactivateURL() {
+	const path = "/api/v1/shorten/%s"
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(entity.ErrURLNotFound)
+
+		resp := suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("request canceled", func() {
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(fmt.Errorf("set active: %w", context.Canceled))
+
+		suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(statusClientClosedRequest).
+			JSON().Object().HasValue("message", "request canceled")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(errors.New("unknown error"))
+
+		resp := suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(nil)
+
+		suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNoContent)
+	})
+}
+
+func (suite *HandlersTestSuite) TestGetURLStats() {
+	path := "/api/v1/shorten/%s/stats"
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrDatabaseUnavailable)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusServiceUnavailable)
+
+		resp.Header("Retry-After").IsEqual("5")
+
+		obj := resp.JSON().Object()
+		obj.HasValue("status", "error")
+		obj.ContainsKey("message")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				URLStats: entity.URLStats{
+					AccessCount:  1,
+					TopReferrers: []entity.StatCount{{Value: "google.com", Count: 1}},
+					Aliases:      []entity.URLAlias{{ShortCode: "alias1", CanonicalShortCode: "abc123", AccessCount: 1}},
+				},
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.ContainsKey("id")
+		resp.HasValue("short_code", "abc123")
+		resp.HasValue("original_url", "https://example.com")
+
+		stats := resp.Value("stats").Object()
+		stats.HasValue("access_count", int64(1))
+		stats.HasValue("access_count_str", "1")
+		stats.Value("top_referrers").Array().Value(0).Object().
+			HasValue("value", "google.com").
+			HasValue("count", int64(1))
+		stats.Value("user_agents").Array().IsEmpty()
+		stats.Value("countries").Array().IsEmpty()
+		stats.Value("aliases").Array().Value(0).Object().
+			HasValue("short_code", "alias1").
+			HasValue("access_count", int64(1))
+		resp.ContainsKey("created_at")
+		resp.ContainsKey("updated_at")
+	})
+
+	suite.Run("never accessed", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		stats := resp.Value("stats").Object()
+		stats.HasValue("access_count", int64(0))
+		stats.HasValue("access_count_str", "0")
+		stats.Value("top_referrers").Array().IsEmpty()
+	})
+
+	suite.Run("large access count", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				URLStats: entity.URLStats{
+					AccessCount: math.MaxInt64,
+				},
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			Expect().
+			Status(http.StatusOK)
+
+		resp.Body().Contains(`"access_count":9223372036854775807`)
+		resp.JSON().Object().Value("stats").Object().
+			HasValue("access_count_str", "9223372036854775807")
+	})
+
+	suite.Run("success csv", func() {
+		const wantCSV = "dimension,value,count\n" +
+			"access_count,,3\n" +
+			"referrer,google.com,2\n" +
+			"user_agent,Chrome,3\n" +
+			"country,US,1\n"
+
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Twice().
+			Return(&entity.URL{
+				ShortCode:   "abc123",
+				OriginalURL: "https://example.com",
+				URLStats: entity.URLStats{
+					AccessCount:  3,
+					TopReferrers: []entity.StatCount{{Value: "google.com", Count: 2}},
+					UserAgents:   []entity.StatCount{{Value: "Chrome", Count: 3}},
+					Countries:    []entity.StatCount{{Value: "US", Count: 1}},
+				},
+			}, nil)
+
+		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithHeader("Accept", "text/csv").
+			Expect().
+			Status(http.StatusOK)
+
+		resp.Header("Content-Type").IsEqual("text/csv; charset=utf-8")
+		resp.Header("Content-Disposition").IsEqual(`attachment; filename="abc123-stats.csv"`)
+		resp.Body().IsEqual(wantCSV)
+
+		suite.e.GET(fmt.Sprintf(path, "abc123")).
+			WithQuery("format", "csv").
+			Expect().
+			Status(http.StatusOK).
+			Body().IsEqual(wantCSV)
+	})
+}
+
+func (suite *HandlersTestSuite) TestFieldSelection() {
+	url := &entity.URL{
+		ID:          1,
+		ShortCode:   "abc123",
+		OriginalURL: "https://example.com",
+		Active:      true,
+		URLStats:    entity.URLStats{AccessCount: 10},
+	}
+
+	suite.Run("unknown field", func() {
+		for _, path := range []string{"/api/v1/shorten/abc123", "/api/v1/shorten", "/api/v1/shorten/abc123/stats"} {
+			resp := suite.e.GET(path).
+				WithQuery("fields", "short_code,password").
+				Expect().
+				Status(http.StatusBadRequest).
+				JSON().Object()
+
+			resp.HasValue("status", "error")
+			err := resp.Value("errors").Array().Value(0).Object()
+			err.HasValue("field", "fields")
+			err.HasValue("message", `unknown field "password"`)
+		}
+
+		suite.urlUseCaseMock.AssertNotCalled(suite.T(), "ResolveShortCode", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	suite.Run("resolve", func() {
+		suite.urlUseCaseMock.
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
+			Once().
+			Return(url, nil)
+
+		suite.e.GET("/api/v1/shorten/abc123").
+			WithQuery("fields", "short_code, original_url").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			IsEqual(map[string]any{"short_code": "abc123", "original_url": "https://example.com"})
+	})
+
+	suite.Run("list", func() {
+		suite.urlUseCaseMock.
+			On("ListURLs", mock.Anything, "", []string(nil), entity.URLSortOldest, defaultListLimit, 0).
+			Once().
+			Return([]entity.URL{*url, {ID: 2, ShortCode: "def456"}}, nil)
+
+		resp := suite.e.GET("/api/v1/shorten").
+			WithQuery("fields", "short_code").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("limit", defaultListLimit)
+		resp.HasValue("offset", 0)
+		resp.Value("urls").Array().IsEqual([]map[string]any{{"short_code": "abc123"}, {"short_code": "def456"}})
+	})
+
+	suite.Run("nested stats fields", func() {
+		suite.urlUseCaseMock.
+			On("GetURLStats", mock.Anything, "abc123").
+			Once().
+			Return(url, nil)
+
+		suite.e.GET("/api/v1/shorten/abc123/stats").
+			WithQuery("fields", "short_code,stats.access_count").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			IsEqual(map[string]any{"short_code": "abc123", "stats": map[string]any{"access_count": 10}})
+	})
+}
+
+func (suite *HandlersTestSuite) TestGetAccessEvents() {
+	const path = "/api/v1/shorten/abc123/events"
+
+	admin := func() *httpexpect.Expect {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		return httpexpect.Default(suite.T(), "").Builder(func(req *httpexpect.Request) {
+			req.WithHandler(router).WithHeader("Authorization", "Bearer secret")
+		})
+	}
+
+	suite.Run("disabled", func() {
+		suite.e.GET(path).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	suite.Run("unauthorized", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		httpexpect.Default(suite.T(), "").GET(path).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	suite.Run("invalid query parameters", func() {
+		resp := admin().GET(path).
+			WithQuery("before", "yesterday").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "invalid query parameters")
+	})
+
+	suite.Run("validation error", func() {
+		resp := admin().GET(path).
+			WithQuery("limit", "0").
+			Expect().
+			Status(http.StatusBadRequest).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.Value("errors").Array().Value(0).Object().HasValue("field", "limit")
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("ListAccessEvents", mock.Anything, "abc123", int64(0), defaultListLimit).
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := admin().GET(path).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "url not found")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ListAccessEvents", mock.Anything, "abc123", int64(0), defaultListLimit).
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		resp := admin().GET(path).
+			Expect().
+			Status(http.StatusInternalServerError).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.ContainsKey("message")
+	})
+
+	suite.Run("full page", func() {
+		suite.urlUseCaseMock.
+			On("ListAccessEvents", mock.Anything, "abc123", int64(10), 2).
+			Once().
+			Return([]entity.AccessEvent{
+				{ID: 9, IP: "203.0.113.1", Referrer: "https://google.com", AccessedAt: time.Now()},
+				{ID: 8, IP: "198.51.100.1", AccessedAt: time.Now()},
+			}, nil)
+
+		resp := admin().GET(path).
+			WithQuery("limit", "2").
+			WithQuery("before", "10").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("limit", 2)
+		resp.HasValue("next_before", 8)
+		resp.Value("events").Array().Length().IsEqual(2)
+		resp.Value("events").Array().Value(0).Object().
+			HasValue("id", 9).
+			HasValue("ip", "203.0.113.1").
+			HasValue("referrer", "https://google.com").
+			ContainsKey("accessed_at")
+	})
+
+	suite.Run("last page", func() {
+		suite.urlUseCaseMock.
+			On("ListAccessEvents", mock.Anything, "abc123", int64(0), defaultListLimit).
+			Once().
+			Return([]entity.AccessEvent{{ID: 1, IP: "203.0.113.1", AccessedAt: time.Now()}}, nil)
+
+		resp := admin().GET(path).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("limit", defaultListLimit)
+		resp.NotContainsKey("next_before")
+		resp.Value("events").Array().Length().IsEqual(1)
+	})
+}
+
+func (suite *HandlersTestSuite) TestGetCreator() {
+	const path = "/api/v1/shorten/abc123/creator"
+
+	admin := func() *httpexpect.Expect {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		return httpexpect.Default(suite.T(), "").Builder(func(req *httpexpect.Request) {
+			req.WithHandler(router).WithHeader("Authorization", "Bearer secret")
+		})
+	}
+
+	suite.Run("disabled", func() {
+		suite.e.GET(path).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	suite.Run("unauthorized", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		httpexpect.Default(suite.T(), "").GET(path).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("GetCreator", mock.Anything, "abc123").
+			Once().
+			Return(nil, entity.ErrURLNotFound)
+
+		resp := admin().GET(path).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "url not found")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("GetCreator", mock.Anything, "abc123").
+			Once().
+			Return(nil, errors.New("unknown error"))
+
+		admin().GET(path).
+			Expect().
+			Status(http.StatusInternalServerError)
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("GetCreator", mock.Anything, "abc123").
+			Once().
+			Return(&entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"}, nil)
+
+		resp := admin().GET(path).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object()
+
+		resp.HasValue("short_code", "abc123")
+		resp.HasValue("ip", "203.0.113.7")
+		resp.HasValue("user_agent", "curl/8.0")
+	})
+}
+
+func (suite *HandlersTestSuite) TestResetStats() {
+	const path = "/api/v1/shorten/abc123/stats/reset"
+
+	admin := func() *httpexpect.Expect {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		return httpexpect.Default(suite.T(), "").Builder(func(req *httpexpect.Request) {
+			req.WithHandler(router).WithHeader("Authorization", "Bearer secret")
+		})
+	}
+
+	suite.Run("disabled", func() {
+		suite.e.POST(path).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusNotFound)
+	})
+
+	suite.Run("unauthorized", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+
+		httpexpect.Default(suite.T(), "").POST(path).
+			WithHandler(router).
+			Expect().
+			Status(http.StatusUnauthorized)
+
+		suite.urlUseCaseMock.AssertNotCalled(suite.T(), "ResetStats", mock.Anything, mock.Anything)
+	})
+
+	suite.Run("url not found", func() {
+		suite.urlUseCaseMock.
+			On("ResetStats", mock.Anything, "abc123").
+			Once().
+			Return(entity.ErrURLNotFound)
+
+		resp := admin().POST(path).
+			Expect().
+			Status(http.StatusNotFound).
+			JSON().Object()
+
+		resp.HasValue("status", "error")
+		resp.HasValue("message", "url not found")
+	})
+
+	suite.Run("server error", func() {
+		suite.urlUseCaseMock.
+			On("ResetStats", mock.Anything, "abc123").
+			Once().
+			Return(errors.New("unknown error"))
+
+		admin().POST(path).
+			Expect().
+			Status(http.StatusInternalServerError)
+	})
+
+	suite.Run("success", func() {
+		suite.urlUseCaseMock.
+			On("ResetStats", mock.Anything, "abc123").
+			Once().
+			Return(nil)
+
+		admin().POST(path).
+			Expect().
+			Status(http.StatusNoContent).
+			Body().IsEmpty()
+	})
+}
+
+func (suite *HandlersTestSuite) TestGetSummary() {
+	const path = "/api/v1/admin/stats"
+
+	suite.Run("disabled", func() {
+		suite.e.GET(path).
+			WithHeader("Authorization", "Bearer secret").
 			Expect().
-			Status(http.StatusBadRequest).
-			JSON().Object()
+			Status(http.StatusNotFound)
+	})
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
-		resp.Value("errors").Array().Value(0).Object().
-			HasValue("field", "original_url").
-			ContainsKey("message")
+	suite.Run("unauthorized", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
+		for _, auth := range []string{"", "secret", "Bearer wrong"} {
+			resp := e.GET(path).
+				WithHandler(router).
+				WithHeader("Authorization", auth).
+				Expect().
+				Status(http.StatusUnauthorized)
+
+			resp.Header("WWW-Authenticate").IsEqual("Bearer")
+			resp.JSON().Object().HasValue("status", "error")
+		}
 	})
 
 	suite.Run("server error", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ShortenURL", mock.Anything, "https://example.com").
+			On("GetSummary", mock.Anything).
 			Once().
 			Return(nil, errors.New("unknown error"))
 
-		resp := suite.e.POST(path).
-			WithJSON(map[string]string{"original_url": "https://example.com"}).
+		resp := e.GET(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
 			Expect().
 			Status(http.StatusInternalServerError).
 			JSON().Object()
@@ -112,54 +3323,77 @@ func (suite *HandlersTestSuite) TestShortenURL() {
 	})
 
 	suite.Run("success", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ShortenURL", mock.Anything, "https://example.com").
+			On("GetSummary", mock.Anything).
 			Once().
-			Return(&entity.URL{
-				ShortCode:   "abc123",
-				OriginalURL: "https://example.com",
+			Return(&entity.Summary{
+				TotalURLs:      3,
+				ActiveURLs:     2,
+				TotalClicks:    10,
+				CreatedLastDay: 1,
+				TopURLs: []entity.URL{
+					{ShortCode: "abc123", OriginalURL: "https://example.com", URLStats: entity.URLStats{AccessCount: 7}},
+				},
 			}, nil)
 
-		resp := suite.e.POST(path).
-			WithJSON(map[string]string{"original_url": "https://example.com"}).
+		resp := e.GET(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
 			Expect().
-			Status(http.StatusCreated).
+			Status(http.StatusOK).
 			JSON().Object()
 
-		resp.ContainsKey("id")
-		resp.HasValue("short_code", "abc123")
-		resp.HasValue("original_url", "https://example.com")
-		resp.NotContainsKey("stats")
-		resp.ContainsKey("created_at")
-		resp.ContainsKey("updated_at")
+		resp.HasValue("total_urls", 3)
+		resp.HasValue("active_urls", 2)
+		resp.HasValue("total_clicks", 10)
+		resp.HasValue("created_last_24h", 1)
+		resp.Value("top_urls").Array().Length().IsEqual(1)
+		resp.Value("top_urls").Array().Value(0).Object().HasValue("access_count", 7)
 	})
 }
 
-func (suite *HandlersTestSuite) TestResolveShortCode() {
-	path := "/api/v1/shorten/%s"
-
-	suite.Run("url not found", func() {
-		suite.urlUseCaseMock.
-			On("ResolveShortCode", mock.Anything, "abc123").
-			Once().
-			Return(nil, entity.ErrURLNotFound)
+func (suite *HandlersTestSuite) TestGetDomainStats() {
+	const path = "/api/v1/admin/domains"
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+	suite.Run("disabled", func() {
+		suite.e.GET(path).
+			WithHeader("Authorization", "Bearer secret").
 			Expect().
-			Status(http.StatusNotFound).
-			JSON().Object()
+			Status(http.StatusNotFound)
+	})
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+	suite.Run("invalid query params", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
+		for _, limit := range []string{"abc", "0"} {
+			resp := e.GET(path).
+				WithHandler(router).
+				WithHeader("Authorization", "Bearer secret").
+				WithQuery("limit", limit).
+				Expect().
+				Status(http.StatusBadRequest).
+				JSON().Object()
+
+			resp.HasValue("status", "error")
+		}
 	})
 
 	suite.Run("server error", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ResolveShortCode", mock.Anything, "abc123").
+			On("GetDomainStats", mock.Anything, "", defaultListLimit).
 			Once().
 			Return(nil, errors.New("unknown error"))
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+		resp := e.GET(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
 			Expect().
 			Status(http.StatusInternalServerError).
 			JSON().Object()
@@ -169,230 +3403,524 @@ func (suite *HandlersTestSuite) TestResolveShortCode() {
 	})
 
 	suite.Run("success", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ResolveShortCode", mock.Anything, "abc123").
+			On("GetDomainStats", mock.Anything, "example.com", maxListLimit).
 			Once().
-			Return(&entity.URL{
-				ShortCode:   "abc123",
-				OriginalURL: "https://example.com",
-				URLStats: entity.URLStats{
-					AccessCount: 1,
-				},
+			Return([]entity.DomainStats{
+				{Host: "example.com", URLs: 2, Clicks: 7},
+				{Host: "docs.example.com", URLs: 1, Clicks: 3},
 			}, nil)
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+		resp := e.GET(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithQuery("domain", "example.com").
+			WithQuery("limit", 1000).
 			Expect().
 			Status(http.StatusOK).
 			JSON().Object()
 
-		resp.ContainsKey("id")
-		resp.HasValue("short_code", "abc123")
-		resp.HasValue("original_url", "https://example.com")
-		resp.NotContainsKey("stats")
-		resp.ContainsKey("created_at")
-		resp.ContainsKey("updated_at")
+		domains := resp.Value("domains").Array()
+		domains.Length().IsEqual(2)
+		domains.Value(0).Object().
+			HasValue("host", "example.com").
+			HasValue("urls", 2).
+			HasValue("clicks", 7)
 	})
 }
 
-func (suite *HandlersTestSuite) TestModifyURL() {
-	const path = "/api/v1/shorten/%s"
+func (suite *HandlersTestSuite) TestCheckLinks() {
+	const path = "/api/v1/admin/linkcheck"
 
-	suite.Run("empty request body", func() {
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			Expect().
-			Status(http.StatusBadRequest).
-			JSON().Object()
+	suite.Run("disabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{}).
+			Expect().
+			Status(http.StatusNotFound)
 	})
 
-	suite.Run("invalid request body", func() {
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON("invalid body").
-			Expect().
-			Status(http.StatusBadRequest).
-			JSON().Object()
+	suite.Run("unauthorized", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"), WithLinkCheck(true))
+		e := httpexpect.Default(suite.T(), "")
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]any{}).
+			Expect().
+			Status(http.StatusUnauthorized)
 	})
 
 	suite.Run("validation error", func() {
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "invalid url"}).
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"), WithLinkCheck(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{"short_codes": []string{"abc123", ""}}).
 			Expect().
 			Status(http.StatusBadRequest).
 			JSON().Object()
 
 		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
-		resp.Value("errors").Array().Value(0).Object().
-			HasValue("field", "original_url").
-			ContainsKey("message")
+		resp.HasValue("message", "validation error")
 	})
 
-	suite.Run("url not found", func() {
+	suite.Run("in progress", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"), WithLinkCheck(true))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ModifyURL", mock.Anything, "abc123", "https://new-example.com").
+			On("CheckLinks", mock.Anything, []string(nil)).
 			Once().
-			Return(nil, entity.ErrURLNotFound)
+			Return(nil, entity.ErrLinkCheckInProgress)
 
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+		resp := e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{}).
 			Expect().
-			Status(http.StatusNotFound).
-			JSON().Object()
+			Status(http.StatusTooManyRequests)
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		resp.Header("Retry-After").IsEqual("30")
+		resp.JSON().Object().HasValue("message", "link check in progress, try again later")
 	})
 
 	suite.Run("server error", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"), WithLinkCheck(true))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("ModifyURL", mock.Anything, "abc123", "https://new-example.com").
+			On("CheckLinks", mock.Anything, []string(nil)).
 			Once().
 			Return(nil, errors.New("unknown error"))
 
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+		e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{}).
 			Expect().
-			Status(http.StatusInternalServerError).
-			JSON().Object()
-
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+			Status(http.StatusInternalServerError)
 	})
 
 	suite.Run("success", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithAdminToken("secret"), WithLinkCheck(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		shortCodes := []string{"abc123", "gone12", "slow12", "unknown", "unknown"}
+
 		suite.urlUseCaseMock.
-			On("ModifyURL", mock.Anything, "abc123", "https://new-example.com").
+			On("CheckLinks", mock.Anything, shortCodes).
 			Once().
-			Return(&entity.URL{
-				ShortCode:   "abc123",
-				OriginalURL: "https://new-example.com",
+			Return([]entity.LinkCheck{
+				{ShortCode: "abc123", OriginalURL: "https://example.com", StatusCode: 200},
+				{ShortCode: "gone12", OriginalURL: "https://example.com/gone", StatusCode: 404, Dead: true},
+				{ShortCode: "slow12", OriginalURL: "https://slow.example.com", Error: "context deadline exceeded", Skipped: true},
 			}, nil)
 
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+		resp := e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{"short_codes": shortCodes}).
 			Expect().
 			Status(http.StatusOK).
 			JSON().Object()
 
-		resp.ContainsKey("id")
-		resp.HasValue("short_code", "abc123")
-		resp.HasValue("original_url", "https://new-example.com")
-		resp.NotContainsKey("stats")
-		resp.ContainsKey("created_at")
-		resp.ContainsKey("updated_at")
+		resp.HasValue("checked", 2)
+		resp.HasValue("dead", 1)
+		resp.HasValue("skipped", 1)
+		resp.Value("links").Array().Length().IsEqual(3)
+		resp.Value("links").Array().Value(1).Object().HasValue("status_code", 404).HasValue("dead", true)
+		resp.Value("links").Array().Value(2).Object().HasValue("skipped", true).HasValue("dead", false)
+		resp.HasValue("missing", []string{"unknown"})
 	})
 }
 
-func (suite *HandlersTestSuite) TestDeactivateURL() {
-	const path = "/api/v1/shorten/%s"
-
-	suite.Run("url not found", func() {
-		suite.urlUseCaseMock.
-			On("DeactivateURL", mock.Anything, "abc123").
-			Once().
-			Return(entity.ErrURLNotFound)
-
-		resp := suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+func (suite *HandlersTestSuite) TestGetConfig() {
+	suite.Run("defaults", func() {
+		resp := suite.e.GET("/api/v1/config").
 			Expect().
-			Status(http.StatusNotFound).
+			Status(http.StatusOK).
 			JSON().Object()
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		resp.HasValue("base_url", "")
+		resp.HasValue("max_batch_size", defaultMaxBatchSize)
+		resp.Value("features").Object().IsEqual(map[string]bool{
+			FeatureBatch:    true,
+			FeatureList:     true,
+			FeatureRedirect: true,
+			FeatureStats:    true,
+		})
 	})
 
-	suite.Run("server error", func() {
-		suite.urlUseCaseMock.
-			On("DeactivateURL", mock.Anything, "abc123").
-			Once().
-			Return(errors.New("unknown error"))
+	suite.Run("without secrets", func() {
+		verifier, err := jwt.New(context.Background(), jwt.WithHMACSecret([]byte("jwt-secret")))
+		suite.Require().NoError(err)
 
-		resp := suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+		router := NewRouter(suite.logger, suite.urlUseCaseMock,
+			WithBaseURL("https://sho.rt"),
+			WithShortCodeLength(6),
+			WithFeatures(map[string]bool{FeatureStats: false}),
+			WithAdminToken("admin-secret"),
+			WithJWTAuth(verifier, "shorten"),
+		)
+
+		resp := httpexpect.Default(suite.T(), "").GET("/api/v1/config").
+			WithHandler(router).
 			Expect().
-			Status(http.StatusInternalServerError).
-			JSON().Object()
+			Status(http.StatusOK)
+
+		resp.JSON().Object().IsEqual(map[string]any{
+			"base_url":          "https://sho.rt",
+			"short_code_length": 6,
+			"max_batch_size":    defaultMaxBatchSize,
+			"features": map[string]bool{
+				FeatureBatch:    true,
+				FeatureList:     true,
+				FeatureRedirect: true,
+				FeatureStats:    false,
+			},
+		})
+		resp.Body().NotContains("admin-secret").NotContains("jwt-secret")
+	})
+}
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+func (suite *HandlersTestSuite) TestFeatures() {
+	suite.Run("disabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithFeatures(map[string]bool{
+			FeatureStats:    false,
+			FeatureRedirect: false,
+			FeatureBatch:    false,
+			FeatureList:     false,
+		}))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.GET("/api/v1/shorten/abc123/stats").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/api/v1/shorten/abc123/redirect").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.POST("/api/v1/shorten/lookup").
+			WithHandler(router).
+			WithJSON(map[string]any{"short_codes": []string{"abc123"}}).
+			Expect().
+			Status(http.StatusNotFound)
+
+		e.GET("/api/v1/shorten").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNotFound)
 	})
 
-	suite.Run("success", func() {
+	suite.Run("enabled", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithFeatures(map[string]bool{FeatureRedirect: true}))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("DeactivateURL", mock.Anything, "abc123").
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
 			Once().
-			Return(nil)
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
 
-		suite.e.DELETE(fmt.Sprintf(path, "abc123")).
+		e.GET("/api/v1/shorten/abc123/redirect").
+			WithHandler(router).
+			WithRedirectPolicy(httpexpect.DontFollowRedirects).
 			Expect().
-			Status(http.StatusNoContent)
+			Status(http.StatusFound)
 	})
 }
 
-func (suite *HandlersTestSuite) TestGetURLStats() {
-	path := "/api/v1/shorten/%s/stats"
+// signToken creates a JWT with the subject and expiration time signed with HS256 using the secret.
+func signToken(secret, subject string, expiresAt time.Time) string {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	signed := encode(`{"alg":"HS256","typ":"JWT"}`) + "." +
+		encode(fmt.Sprintf(`{"sub":%q,"exp":%d}`, subject, expiresAt.Unix()))
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// shortenInput matches the input of ShortenURL. The creator is only compared if it is set in the expected input,
+// since it otherwise depends on the client of the test request.
+func shortenInput(want entity.ShortenInput) any {
+	return mock.MatchedBy(func(in entity.ShortenInput) bool {
+		if want.Creator == (entity.Creator{}) {
+			in.Creator = entity.Creator{}
+		}
+		return reflect.DeepEqual(want, in)
+	})
+}
+
+// ownedBy matches request contexts carrying the owner, see entity.OwnerFromContext.
+func ownedBy(owner string) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		got, ok := entity.OwnerFromContext(ctx)
+		return ok && got == owner
+	})
+}
+
+func (suite *HandlersTestSuite) TestJWTAuth() {
+	const path = "/api/v1/shorten"
+
+	verifier, err := jwt.New(context.Background(), jwt.WithHMACSecret([]byte("secret")))
+	suite.Require().NoError(err)
+
+	suite.Run("valid token", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithJWTAuth(verifier, "shorten"))
+		e := httpexpect.Default(suite.T(), "")
 
-	suite.Run("url not found", func() {
 		suite.urlUseCaseMock.
-			On("GetURLStats", mock.Anything, "abc123").
+			On("ShortenURL", ownedBy("user-1"), shortenInput(entity.ShortenInput{OriginalURL: "https://example.com"})).
 			Once().
-			Return(nil, entity.ErrURLNotFound)
+			Return(&entity.URL{ID: 1, ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+		e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer "+signToken("secret", "user-1", time.Now().Add(time.Hour))).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
 			Expect().
-			Status(http.StatusNotFound).
-			JSON().Object()
+			Status(http.StatusCreated)
+	})
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+	suite.Run("missing token", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithJWTAuth(verifier, "shorten"))
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.POST(path).
+			WithHandler(router).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+
+		resp.Header("WWW-Authenticate").IsEqual("Bearer")
+		resp.JSON().Object().HasValue("message", "unauthorized")
 	})
 
-	suite.Run("server error", func() {
+	suite.Run("expired token", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithJWTAuth(verifier, "shorten"))
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer "+signToken("secret", "user-1", time.Now().Add(-time.Minute))).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+
+		resp.Header("WWW-Authenticate").IsEqual(`Bearer error="invalid_token"`)
+	})
+
+	suite.Run("invalid token", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithJWTAuth(verifier, "shorten"))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.POST(path).
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer "+signToken("wrong", "user-1", time.Now().Add(time.Hour))).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusUnauthorized)
+	})
+
+	suite.Run("unprotected operation", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithJWTAuth(verifier, "shorten"))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("GetURLStats", mock.Anything, "abc123").
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
 			Once().
-			Return(nil, errors.New("unknown error"))
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+		e.GET(path + "/abc123").
+			WithHandler(router).
 			Expect().
-			Status(http.StatusInternalServerError).
+			Status(http.StatusOK)
+	})
+}
+
+func (suite *HandlersTestSuite) TestReadOnly() {
+	suite.Run("writes rejected", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadOnly(true))
+		e := httpexpect.Default(suite.T(), "")
+
+		resp := e.POST("/api/v1/shorten").
+			WithHandler(router).
+			WithJSON(map[string]string{"original_url": "https://example.com"}).
+			Expect().
+			Status(http.StatusServiceUnavailable).
 			JSON().Object()
 
 		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		resp.HasValue("message", "service is in read-only mode, try again later")
+
+		e.DELETE("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusServiceUnavailable)
 	})
 
-	suite.Run("success", func() {
+	suite.Run("reads allowed", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadOnly(true))
+		e := httpexpect.Default(suite.T(), "")
+
 		suite.urlUseCaseMock.
-			On("GetURLStats", mock.Anything, "abc123").
+			On("ResolveShortCode", mock.Anything, "abc123", mock.Anything).
 			Once().
-			Return(&entity.URL{
-				ShortCode:   "abc123",
-				OriginalURL: "https://example.com",
-				URLStats: entity.URLStats{
-					AccessCount: 1,
-				},
-			}, nil)
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.com"}, nil)
 
-		resp := suite.e.GET(fmt.Sprintf(path, "abc123")).
+		e.GET("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusOK)
+	})
+
+	suite.Run("toggle", func() {
+		router := NewRouter(suite.logger, suite.urlUseCaseMock, WithReadOnly(true), WithAdminToken("secret"))
+		e := httpexpect.Default(suite.T(), "")
+
+		e.PUT("/api/v1/admin/read-only").
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{}).
+			Expect().
+			Status(http.StatusBadRequest)
+
+		e.PUT("/api/v1/admin/read-only").
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			WithJSON(map[string]any{"read_only": false}).
 			Expect().
 			Status(http.StatusOK).
-			JSON().Object()
+			JSON().Object().HasValue("read_only", false)
 
-		resp.ContainsKey("id")
-		resp.HasValue("short_code", "abc123")
-		resp.HasValue("original_url", "https://example.com")
-		resp.Value("stats").Object().
-			HasValue("access_count", int64(1))
-		resp.ContainsKey("created_at")
-		resp.ContainsKey("updated_at")
+		e.GET("/api/v1/admin/read-only").
+			WithHandler(router).
+			WithHeader("Authorization", "Bearer secret").
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().HasValue("read_only", false)
+
+		suite.urlUseCaseMock.
+			On("DeactivateURL", mock.Anything, "abc123").
+			Once().
+			Return(nil)
+
+		e.DELETE("/api/v1/shorten/abc123").
+			WithHandler(router).
+			Expect().
+			Status(http.StatusNoContent)
 	})
 }
 
+func TestWantsCSV(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		accept string
+		want   bool
+	}{
+		{name: "default", target: "/", want: false},
+		{name: "format csv", target: "/?format=csv", want: true},
+		{name: "format json overrides accept", target: "/?format=json", accept: "text/csv", want: false},
+		{name: "accept csv", target: "/", accept: "text/csv", want: true},
+		{name: "accept json first", target: "/", accept: "application/json, text/csv", want: false},
+		{name: "accept csv first", target: "/", accept: "text/csv;q=0.9, */*;q=0.8", want: true},
+		{name: "accept anything", target: "/", accept: "*/*", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			assert.Equal(t, tt.want, wantsCSV(r))
+		})
+	}
+}
+
+func TestHTTPStatusFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantResp       errorResponse
+		wantRetryAfter string
+	}{
+		{name: "url not found", err: entity.ErrURLNotFound, wantStatus: http.StatusNotFound, wantResp: urlNotFoundResponse},
+		{name: "url deactivated", err: entity.ErrURLDeactivated, wantStatus: http.StatusGone, wantResp: urlGoneResponse},
+		{name: "url expired", err: entity.ErrURLExpired, wantStatus: http.StatusGone, wantResp: urlGoneResponse},
+		{name: "short code exists", err: entity.ErrShortCodeExists, wantStatus: http.StatusConflict, wantResp: shortCodeExistsResponse},
+		{name: "short code too short", err: entity.ErrShortCodeTooShort, wantStatus: http.StatusBadRequest, wantResp: shortCodeTooShortResponse},
+		{name: "unknown tier", err: entity.ErrUnknownTier, wantStatus: http.StatusBadRequest, wantResp: unknownTierResponse},
+		{name: "encoding not allowed", err: entity.ErrEncodingNotAllowed, wantStatus: http.StatusBadRequest, wantResp: encodingNotAllowedResponse},
+		{name: "domain not allowed", err: entity.ErrDomainNotAllowed, wantStatus: http.StatusBadRequest, wantResp: domainNotAllowedResponse},
+		{name: "self-referential url", err: entity.ErrSelfReferentialURL, wantStatus: http.StatusBadRequest, wantResp: selfReferentialURLResponse},
+		{
+			name:       "creation cooldown",
+			err:        entity.ErrCreationCooldown,
+			wantStatus: http.StatusTooManyRequests,
+			wantResp:   creationCooldownResponse,
+		},
+		{
+			name:           "link check in progress",
+			err:            entity.ErrLinkCheckInProgress,
+			wantStatus:     http.StatusTooManyRequests,
+			wantResp:       linkCheckInProgressResponse,
+			wantRetryAfter: linkCheckRetryAfter,
+		},
+		{
+			name:           "database unavailable",
+			err:            entity.ErrDatabaseUnavailable,
+			wantStatus:     http.StatusServiceUnavailable,
+			wantResp:       databaseUnavailableResponse,
+			wantRetryAfter: databaseUnavailableRetryAfter,
+		},
+		{name: "request canceled", err: context.Canceled, wantStatus: statusClientClosedRequest, wantResp: requestCanceledResponse},
+		{name: "request timed out", err: context.DeadlineExceeded, wantStatus: http.StatusServiceUnavailable, wantResp: requestTimeoutResponse},
+		{
+			name:       "wrapped error",
+			err:        fmt.Errorf("usecase.URLUseCase.GetURL: %w", entity.ErrURLNotFound),
+			wantStatus: http.StatusNotFound,
+			wantResp:   urlNotFoundResponse,
+		},
+		{name: "unknown error", err: errors.New("unknown error"), wantStatus: http.StatusInternalServerError, wantResp: serverErrorResponse},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := httpStatusFor(tt.err)
+
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, tt.wantResp, resp)
+			assert.Equal(t, tt.wantRetryAfter, retryAfterFor(tt.err))
+		})
+	}
+}
+
+func TestMustRegisterValidation(t *testing.T) {
+	validate := validator.New()
+
+	assert.NotPanics(t, func() { mustRegisterValidation(validate, "shortcode", validateShortCode) })
+	assert.Panics(t, func() { mustRegisterValidation(validate, "", validateShortCode) })
+}
+
 func TestURLHandler(t *testing.T) {
 	suite.Run(t, new(HandlersTestSuite))
 }
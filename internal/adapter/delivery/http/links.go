@@ -0,0 +1,141 @@
+package http
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// halMediaType is the media type clients list in the Accept header to get hypermedia links in URL responses.
+const halMediaType = "application/hal+json"
+
+// link represents a HAL link to a related resource.
+type link struct {
+	Href string `json:"href"`
+}
+
+// urlLinks represents the HAL links of a URL: the URL itself, its statistics and the redirect to its original URL.
+type urlLinks struct {
+	Self     link `json:"self"`
+	Stats    link `json:"stats"`
+	Redirect link `json:"redirect"`
+}
+
+// linker builds the links of URLs. Links are absolute if the base URL is set and root-relative otherwise.
+type linker struct {
+	baseURL string
+}
+
+// urlLinks returns the links of the URL with the given short code.
+func (l *linker) urlLinks(shortCode string) *urlLinks {
+	self := l.baseURL + urlLocation(shortCode)
+
+	return &urlLinks{
+		Self:     link{Href: self},
+		Stats:    link{Href: self + "/stats"},
+		Redirect: link{Href: self + "/redirect"},
+	}
+}
+
+// linkerFor returns the linker of URLs with the given base URL if the client asked for links by listing
+// application/hal+json in the Accept header, or nil otherwise, so that flat responses stay unchanged.
+func linkerFor(r *http.Request, baseURL string) *linker {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(accept)
+		if err == nil && mediaType == halMediaType {
+			return &linker{baseURL: strings.TrimSuffix(baseURL, "/")}
+		}
+	}
+
+	return nil
+}
+
+// pageLinks builds the Link header (RFC 8288) of a page of a list, so that standard clients can follow
+// pages without knowing the pagination parameters. The links keep the query parameters of the request,
+// e.g. search filters, and only change the pagination parameters. They are absolute if the base URL is set.
+type pageLinks struct {
+	r       *http.Request
+	baseURL string
+	links   []string
+}
+
+// newPageLinks creates the links of the page of the list requested with r.
+func newPageLinks(r *http.Request, baseURL string) *pageLinks {
+	return &pageLinks{r: r, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// add adds the link with the relation type rel to the page requested with the query parameters
+// of the request changed to params. Parameters set to an empty value are removed.
+func (p *pageLinks) add(rel string, params map[string]string) {
+	query := p.r.URL.Query()
+
+	for name, value := range params {
+		if value == "" {
+			query.Del(name)
+		} else {
+			query.Set(name, value)
+		}
+	}
+
+	target := p.baseURL + p.r.URL.Path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	p.links = append(p.links, fmt.Sprintf(`<%s>; rel="%s"`, target, rel))
+}
+
+// set sets the Link header of the response to the added links.
+func (p *pageLinks) set(w http.ResponseWriter) {
+	if len(p.links) > 0 {
+		w.Header().Set("Link", strings.Join(p.links, ", "))
+	}
+}
+
+// setURLListLinks sets the Link header of a page of listed URLs. With offset pagination, the first,
+// previous and next pages are linked; with cursor pagination, pages can only be followed forward,
+// so the first and next pages are linked. The next page is linked only if the page is full.
+func setURLListLinks(w http.ResponseWriter, r *http.Request, baseURL string, req listRequest, resp urlListResponse) {
+	links := newPageLinks(r, baseURL)
+	limit := strconv.Itoa(req.Limit)
+
+	if req.Pagination == paginationCursor {
+		links.add("first", map[string]string{"limit": limit, "cursor": ""})
+
+		if resp.NextCursor != nil {
+			links.add("next", map[string]string{"limit": limit, "cursor": strconv.FormatInt(*resp.NextCursor, 10)})
+		}
+
+		links.set(w)
+		return
+	}
+
+	links.add("first", map[string]string{"limit": limit, "offset": ""})
+
+	if req.Offset > 0 {
+		links.add("prev", map[string]string{"limit": limit, "offset": strconv.Itoa(max(req.Offset-req.Limit, 0))})
+	}
+
+	if len(resp.URLs) > 0 && len(resp.URLs) == req.Limit {
+		links.add("next", map[string]string{"limit": limit, "offset": strconv.Itoa(req.Offset + req.Limit)})
+	}
+
+	links.set(w)
+}
+
+// setAccessEventsLinks sets the Link header of a page of access events, linking the first page
+// and the next page if the page is full.
+func setAccessEventsLinks(w http.ResponseWriter, r *http.Request, baseURL string, req eventsRequest, resp accessEventsResponse) {
+	links := newPageLinks(r, baseURL)
+	limit := strconv.Itoa(req.Limit)
+
+	links.add("first", map[string]string{"limit": limit, "before": ""})
+
+	if resp.NextBefore != nil {
+		links.add("next", map[string]string{"limit": limit, "before": strconv.FormatInt(*resp.NextBefore, 10)})
+	}
+
+	links.set(w)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// unknownOperation labels the metrics of requests not served by a named operation, e.g. unmatched routes.
+const unknownOperation = "unknown"
+
+// httpMetrics holds the Prometheus histograms observing the requests served by the routers.
+type httpMetrics struct {
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+}
+
+// newHTTPMetrics creates the request duration and response size histograms and registers them with reg.
+// It panics if histograms with the same names are already registered with reg.
+func newHTTPMetrics(reg prometheus.Registerer) *httpMetrics {
+	labels := []string{"operation", "code"}
+
+	return &httpMetrics{
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		// The buckets range from 100 B to 10 MB, covering redirects with empty bodies as well as large exports.
+		size: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP response bodies in bytes.",
+			Buckets: prometheus.ExponentialBuckets(100, 10, 6),
+		}, labels),
+	}
+}
+
+// operationKey is the context key of the operation name set by the operation middleware for the metrics.
+type operationKey struct{}
+
+// setMetricsOperation sets the operation the metrics of the request are labeled with, if they are observed.
+func setMetricsOperation(ctx context.Context, name string) {
+	if op, ok := ctx.Value(operationKey{}).(*string); ok {
+		*op = name
+	}
+}
+
+// middleware observes the duration of each request and the number of bytes written as its response body,
+// labeled by the operation named by the operation middleware and the status code. The body size is
+// counted as written to the client, i.e. after compression, so that it reflects the bandwidth used.
+func (m *httpMetrics) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		op := unknownOperation
+		mw := &metricsResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(mw, r.WithContext(context.WithValue(r.Context(), operationKey{}, &op)))
+
+		code := strconv.Itoa(mw.statusCode)
+		m.duration.WithLabelValues(op, code).Observe(time.Since(start).Seconds())
+		m.size.WithLabelValues(op, code).Observe(float64(mw.bytesWritten))
+	})
+}
+
+// metricsResponseWriter records the status code and counts the bytes of the response body.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	statusCode   int
+	bytesWritten int64
+	wroteHeader  bool
+}
+
+// WriteHeader records the status code of the response and writes it.
+func (w *metricsResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.statusCode = statusCode
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes p and adds the number of bytes written to the count.
+func (w *metricsResponseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+
+	n, err := w.ResponseWriter.Write(p)
+	w.bytesWritten += int64(n)
+
+	return n, err
+}
+
+// Unwrap returns the wrapped response writer, so that http.ResponseController can reach it.
+func (w *metricsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
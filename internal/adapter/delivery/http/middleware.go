@@ -0,0 +1,397 @@
+package http
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/httplog/v2"
+	"github.com/go-chi/render"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+	"github.com/vadimbarashkov/url-shortener/pkg/jwt"
+	"github.com/vadimbarashkov/url-shortener/pkg/servertiming"
+)
+
+// tokenVerifier defines the interface for verifying bearer tokens issued by an identity provider.
+type tokenVerifier interface {
+	Verify(ctx context.Context, token string) (*jwt.Claims, error)
+}
+
+// recoverer is a middleware that recovers from panics in handlers. The panic is logged with its
+// stack trace and the request ID, while the client only gets a generic 500 Internal Server Error
+// carrying the request ID, so that the panic can be found in the logs.
+func recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+
+			if rvr == http.ErrAbortHandler {
+				// Let the server abort the response as intended.
+				panic(rvr)
+			}
+
+			ctx := r.Context()
+			httplog.LogEntrySetField(ctx, "panic", slog.StringValue(fmt.Sprint(rvr)))
+			httplog.LogEntrySetField(ctx, "stack", slog.StringValue(string(debug.Stack())))
+
+			render.Status(r, http.StatusInternalServerError)
+			renderJSON(w, r, withRequestID(r, serverErrorResponse))
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// timeout returns a middleware that cancels the request context after the given duration, so that
+// queries still running are aborted. Handlers answer the resulting context.DeadlineExceeded errors
+// with 503 Service Unavailable like any other error, see httpStatusFor. Unlike http.TimeoutHandler,
+// it doesn't buffer responses, so that exports are streamed to the client.
+func timeout(d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// adminAuth returns a middleware that only lets through requests carrying
+// the given token in the Authorization header as a bearer token.
+func adminAuth(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				render.Status(r, http.StatusUnauthorized)
+				renderJSON(w, r, withRequestID(r, unauthorizedResponse))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// jwtAuth returns a middleware that requires a bearer token accepted by the verifier, e.g. a JWT issued
+// by an identity provider, rejecting missing, invalid and expired tokens with 401 Unauthorized.
+// The subject of the token identifies the owner of the request: it is added to the request context,
+// see entity.OwnerFromContext, and to the request log line.
+func jwtAuth(verifier tokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				render.Status(r, http.StatusUnauthorized)
+				renderJSON(w, r, withRequestID(r, unauthorizedResponse))
+				return
+			}
+
+			claims, err := verifier.Verify(r.Context(), token)
+			if err != nil {
+				httplog.LogEntrySetField(r.Context(), "auth_error", slog.StringValue(err.Error()))
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				render.Status(r, http.StatusUnauthorized)
+				renderJSON(w, r, withRequestID(r, unauthorizedResponse))
+				return
+			}
+
+			httplog.LogEntrySetField(r.Context(), "owner", slog.StringValue(claims.Subject))
+
+			next.ServeHTTP(w, r.WithContext(entity.ContextWithOwner(r.Context(), claims.Subject)))
+		})
+	}
+}
+
+// realIP returns a middleware that sets the remote address of the request to the client IP
+// reported by the X-Real-IP or X-Forwarded-For headers. The headers are only honored if the
+// request comes directly from one of the trusted proxies, since otherwise clients could spoof them.
+func realIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip, ok := forwardedIP(r, trustedProxies); ok {
+				r.RemoteAddr = ip.String()
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedIP returns the client IP reported by the forwarding headers of a request
+// that comes from a trusted proxy. In X-Forwarded-For, the rightmost address that doesn't
+// belong to a trusted proxy is the client, because the addresses to its left could have been
+// set by the client itself.
+func forwardedIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer, err := netip.ParseAddr(host)
+	if err != nil || !isTrustedProxy(peer, trustedProxies) {
+		return netip.Addr{}, false
+	}
+
+	if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return ip.Unmap(), true
+	}
+
+	var client netip.Addr
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+
+		client = ip.Unmap()
+		if !isTrustedProxy(client, trustedProxies) {
+			break
+		}
+	}
+
+	return client, client.IsValid()
+}
+
+// isTrustedProxy reports whether the IP belongs to one of the trusted proxy ranges.
+func isTrustedProxy(ip netip.Addr, trustedProxies []netip.Prefix) bool {
+	ip = ip.Unmap()
+
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestIDHeader is a middleware that returns the ID assigned to the request
+// by middleware.RequestID in the X-Request-ID response header.
+func requestIDHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			w.Header().Set(middleware.RequestIDHeader, id)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// serverTiming is a middleware that reports how long the request took to serve in the Server-Timing
+// response header, along with the time spent in the database, which the repository adds to the timings
+// carried by the request context, e.g. "db;dur=1.25, total;dur=3.5".
+func serverTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, timings := servertiming.NewContext(r.Context())
+		tw := &serverTimingResponseWriter{ResponseWriter: w, timings: timings, start: time.Now()}
+
+		next.ServeHTTP(tw, r.WithContext(ctx))
+	})
+}
+
+// serverTimingResponseWriter sets the Server-Timing header right before the response header is written,
+// so that the total duration covers as much of the handler as possible.
+type serverTimingResponseWriter struct {
+	http.ResponseWriter
+	timings     *servertiming.Timings
+	start       time.Time
+	wroteHeader bool
+}
+
+// WriteHeader sets the Server-Timing header and writes the status code.
+func (w *serverTimingResponseWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Server-Timing", w.timings.Header(time.Since(w.start)))
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the 200 OK header first if it hasn't been written yet, and then p.
+func (w *serverTimingResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the wrapped response writer, so that http.ResponseController can reach it.
+func (w *serverTimingResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// rejectWritesIf returns a middleware that responds with 503 Service Unavailable to requests
+// with methods that modify data while the read-only flag is set. Reads keep working.
+func rejectWritesIf(readOnly *atomic.Bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if readOnly.Load() {
+					render.Status(r, http.StatusServiceUnavailable)
+					renderJSON(w, r, withRequestID(r, readOnlyResponse))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validShortCode is a middleware that responds with 400 Bad Request if the shortCode URL parameter
+// can't be a stored short code, so that malformed codes are rejected without querying the database.
+func validShortCode(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		shortCode := chi.URLParam(r, "shortCode")
+
+		if len(shortCode) > maxShortCodeLength || !shortCodeRegexp.MatchString(shortCode) {
+			render.Status(r, http.StatusBadRequest)
+			renderJSON(w, r, withRequestID(r, invalidShortCodeResponse))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// operation returns a middleware that adds the name of the logical operation served by the route,
+// e.g. shorten or resolve, to the request log line, along with the short code if the route has one,
+// so that logs can be filtered by operation rather than by path. The name also labels the request metrics.
+func operation(name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			httplog.LogEntrySetField(ctx, "operation", slog.StringValue(name))
+			setMetricsOperation(ctx, name)
+
+			if shortCode := chi.URLParam(r, "shortCode"); shortCode != "" {
+				httplog.LogEntrySetField(ctx, "short_code", slog.StringValue(shortCode))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// concurrencyLimit returns a middleware that serves at most n requests at a time. Requests over
+// the limit are rejected with 503 Service Unavailable rather than queued, which applies backpressure
+// to clients before the database connection pool is exhausted.
+func concurrencyLimit(n int) func(http.Handler) http.Handler {
+	sem := make(chan struct{}, n)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			default:
+				w.Header().Set("Retry-After", serverBusyRetryAfter)
+				render.Status(r, http.StatusServiceUnavailable)
+				renderJSON(w, r, withRequestID(r, serverBusyResponse))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// discardHandler is a slog.Handler that drops all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// logFields is a middleware that adds the method, path, status code, number of bytes written, duration
+// in milliseconds, request ID and remote IP of each request to its log line as top-level fields, so that
+// log pipelines can rely on the same schema whatever the httplog options are. It must be used right after
+// httplog.RequestLogger, whose response writer reports the status and size of the response.
+func logFields(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		next.ServeHTTP(w, r)
+
+		status, bytes := http.StatusOK, 0
+		if ww, ok := w.(chimiddleware.WrapResponseWriter); ok {
+			// The status is only unknown if nothing was written, in which case net/http responds with 200 OK.
+			if ww.Status() > 0 {
+				status = ww.Status()
+			}
+			bytes = ww.BytesWritten()
+		}
+
+		remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			// The remote address set by realIP has no port.
+			remoteIP = r.RemoteAddr
+		}
+
+		ctx := r.Context()
+		httplog.LogEntrySetField(ctx, "method", slog.StringValue(r.Method))
+		httplog.LogEntrySetField(ctx, "path", slog.StringValue(r.URL.Path))
+		httplog.LogEntrySetField(ctx, "status", slog.IntValue(status))
+		httplog.LogEntrySetField(ctx, "bytes", slog.IntValue(bytes))
+		httplog.LogEntrySetField(ctx, "duration_ms", slog.Float64Value(float64(time.Since(start).Microseconds())/1000))
+		httplog.LogEntrySetField(ctx, "request_id", slog.StringValue(middleware.GetReqID(ctx)))
+		httplog.LogEntrySetField(ctx, "remote_ip", slog.StringValue(remoteIP))
+	})
+}
+
+// sampleLogs returns a middleware that logs only 1 in n successful GET and HEAD requests, e.g. redirects
+// and resolves, which make up most of the traffic. Requests failing with a 4xx or 5xx status and requests
+// that modify data are always logged. It must be used right after httplog.RequestLogger, whose log entry
+// it silences once the status of the response is known.
+func sampleLogs(n int) func(http.Handler) http.Handler {
+	var count atomic.Uint64
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				return
+			}
+
+			ww, ok := w.(interface{ Status() int })
+			if !ok || ww.Status() <= 0 || ww.Status() >= http.StatusBadRequest {
+				return
+			}
+
+			// The first of every n successful requests is logged.
+			if count.Add(1)%uint64(n) == 1%uint64(n) {
+				return
+			}
+
+			if entry, ok := chimiddleware.GetLogEntry(r).(*httplog.RequestLoggerEntry); ok {
+				entry.Logger = slog.New(discardHandler{})
+			}
+		})
+	}
+}
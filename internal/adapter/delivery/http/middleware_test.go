@@ -0,0 +1,387 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vadimbarashkov/url-shortener/pkg/servertiming"
+)
+
+func TestRealIP(t *testing.T) {
+	trustedProxies := []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "untrusted peer",
+			remoteAddr: "203.0.113.1:1234",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.1", "X-Forwarded-For": "198.51.100.1"},
+			want:       "203.0.113.1:1234",
+		},
+		{
+			name:       "trusted peer without headers",
+			remoteAddr: "10.0.0.1:1234",
+			want:       "10.0.0.1:1234",
+		},
+		{
+			name:       "trusted peer with x-real-ip",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Real-IP": "198.51.100.1"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "trusted peer with x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "192.0.2.1, 198.51.100.1, 10.0.0.2"},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "trusted peer with invalid x-forwarded-for",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-For": "unknown"},
+			want:       "10.0.0.1:1234",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+
+			h := realIP(trustedProxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: ""},
+		{header: "br", want: ""},
+		{header: "gzip", want: "gzip"},
+		{header: "deflate, gzip;q=0.8", want: "gzip"},
+		{header: "deflate", want: "deflate"},
+		{header: "gzip;q=0, deflate", want: "deflate"},
+		{header: "GZIP;q=0.0", want: ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, acceptedEncoding(tt.header), tt.header)
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := `{"urls":["` + strings.Repeat("https://example.com", 100) + `"]}`
+	small := `{"status":"ok"}`
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		contentType    string
+		status         int
+		body           string
+		wantEncoding   string
+	}{
+		{
+			name:           "large json with gzip",
+			acceptEncoding: "gzip, deflate",
+			contentType:    "application/json",
+			status:         http.StatusCreated,
+			body:           large,
+			wantEncoding:   "gzip",
+		},
+		{
+			name:           "large json with deflate",
+			acceptEncoding: "deflate",
+			contentType:    "application/json",
+			status:         http.StatusOK,
+			body:           large,
+			wantEncoding:   "deflate",
+		},
+		{
+			name:           "small json",
+			acceptEncoding: "gzip",
+			contentType:    "application/json",
+			status:         http.StatusNotFound,
+			body:           small,
+		},
+		{
+			name:        "large json without accept-encoding",
+			contentType: "application/json",
+			status:      http.StatusOK,
+			body:        large,
+		},
+		{
+			name:           "large incompressible body",
+			acceptEncoding: "gzip",
+			contentType:    "image/png",
+			status:         http.StatusOK,
+			body:           large,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := compress(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+
+				// Write in chunks, so that the body crosses the minimum size in the middle of a write.
+				for chunk := range slices.Chunk([]byte(tt.body), 300) {
+					w.Write(chunk)
+				}
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				r.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			h.ServeHTTP(w, r)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.wantEncoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept-Encoding", w.Header().Get("Vary"))
+
+			var body io.Reader = w.Body
+			switch tt.wantEncoding {
+			case "gzip":
+				zr, err := gzip.NewReader(w.Body)
+				if !assert.NoError(t, err) {
+					return
+				}
+				body = zr
+			case "deflate":
+				zr, err := zlib.NewReader(w.Body)
+				if !assert.NoError(t, err) {
+					return
+				}
+				body = zr
+			}
+
+			got, err := io.ReadAll(body)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.body, string(got))
+		})
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantHeader *regexp.Regexp
+	}{
+		{
+			name: "with db time",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				servertiming.Add(r.Context(), "db", 1250*time.Microsecond)
+				servertiming.Add(r.Context(), "db", 250*time.Microsecond)
+				w.WriteHeader(http.StatusCreated)
+			},
+			wantHeader: regexp.MustCompile(`^db;dur=1\.5, total;dur=\d+(\.\d+)?$`),
+		},
+		{
+			name: "without db time",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("ok"))
+			},
+			wantHeader: regexp.MustCompile(`^total;dur=\d+(\.\d+)?$`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			serverTiming(tt.handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Regexp(t, tt.wantHeader, w.Header().Get("Server-Timing"))
+		})
+	}
+}
+
+func TestLogFields(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := &httplog.Logger{
+		Logger:  slog.New(slog.NewJSONHandler(&buf, nil)),
+		Options: httplog.Options{JSON: true, Concise: true},
+	}
+
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(httplog.RequestLogger(logger))
+	r.Use(logFields)
+	r.Post("/shorten", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	r.Get("/empty", func(http.ResponseWriter, *http.Request) {})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus float64
+		wantBytes  float64
+	}{
+		{name: "response with body", method: http.MethodPost, path: "/shorten", wantStatus: 201, wantBytes: 5},
+		{name: "empty response", method: http.MethodGet, path: "/empty", wantStatus: 200, wantBytes: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.RemoteAddr = "203.0.113.1:1234"
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			var line map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+
+			assert.Equal(t, tt.method, line["method"])
+			assert.Equal(t, tt.path, line["path"])
+			assert.Equal(t, tt.wantStatus, line["status"])
+			assert.Equal(t, tt.wantBytes, line["bytes"])
+			assert.IsType(t, float64(0), line["duration_ms"])
+			assert.NotEmpty(t, line["request_id"])
+			assert.Equal(t, "203.0.113.1", line["remote_ip"])
+		})
+	}
+}
+
+func TestSampleLogs(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger := &httplog.Logger{
+		Logger:  slog.New(slog.NewJSONHandler(&buf, nil)),
+		Options: httplog.Options{JSON: true, Concise: true},
+	}
+
+	r := chi.NewRouter()
+	r.Use(httplog.RequestLogger(logger))
+	r.Use(sampleLogs(10))
+	r.Get("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusFound)
+	})
+	r.Get("/fail", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	r.Post("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	countLines := func(method, path string, n int) int {
+		buf.Reset()
+
+		for range n {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+		}
+
+		return strings.Count(buf.String(), `"msg":"Response: `)
+	}
+
+	assert.InDelta(t, 100, countLines(http.MethodGet, "/ok", 1000), 1, "successful reads are sampled")
+	assert.Equal(t, 100, countLines(http.MethodGet, "/fail", 100), "failed requests are always logged")
+	assert.Equal(t, 100, countLines(http.MethodPost, "/ok", 100), "writes are always logged")
+}
+
+func TestHTTPMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := newHTTPMetrics(registry)
+
+	r := chi.NewRouter()
+	r.Use(m.middleware)
+	r.With(operation("shorten")).Post("/shorten", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+		w.Write([]byte(", world"))
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/shorten", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/shorten", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/unknown", nil))
+
+	// histogram returns the histogram of the family with the given labels, or nil if there are no observations.
+	histogram := func(name, operation, code string) *dto.Histogram {
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		for _, family := range families {
+			if family.GetName() != name {
+				continue
+			}
+
+			for _, metric := range family.GetMetric() {
+				labels := make(map[string]string)
+				for _, label := range metric.GetLabel() {
+					labels[label.GetName()] = label.GetValue()
+				}
+
+				if labels["operation"] == operation && labels["code"] == code {
+					return metric.GetHistogram()
+				}
+			}
+		}
+
+		return nil
+	}
+
+	size := histogram("http_response_size_bytes", "shorten", "201")
+	if assert.NotNil(t, size) {
+		assert.Equal(t, uint64(2), size.GetSampleCount())
+		assert.Equal(t, float64(24), size.GetSampleSum())
+	}
+
+	duration := histogram("http_request_duration_seconds", "shorten", "201")
+	if assert.NotNil(t, duration) {
+		assert.Equal(t, uint64(2), duration.GetSampleCount())
+	}
+
+	notFound := histogram("http_response_size_bytes", unknownOperation, "404")
+	if assert.NotNil(t, notFound) {
+		assert.Equal(t, uint64(1), notFound.GetSampleCount())
+	}
+}
@@ -0,0 +1,44 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// prettyJSONCtxKey is the context key that marks requests whose JSON responses are indented.
+type prettyJSONCtxKey struct{}
+
+// prettyJSON is a middleware that marks requests so that renderJSON indents their responses.
+func prettyJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), prettyJSONCtxKey{}, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// renderJSON marshals v to JSON and writes it with the status set by render.Status, like render.JSON.
+// The JSON is indented if the request is marked by the prettyJSON middleware.
+func renderJSON(w http.ResponseWriter, r *http.Request, v any) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(true)
+
+	if pretty, _ := r.Context().Value(prettyJSONCtxKey{}).(bool); pretty {
+		enc.SetIndent("", "  ")
+	}
+
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if status, ok := r.Context().Value(render.StatusCtxKey).(int); ok {
+		w.WriteHeader(status)
+	}
+	w.Write(buf.Bytes()) //nolint:errcheck
+}
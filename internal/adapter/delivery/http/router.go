@@ -4,57 +4,581 @@
 package http
 
 import (
+	"context"
 	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 	"github.com/go-chi/httplog/v2"
 	"github.com/go-playground/validator/v10"
+	"github.com/prometheus/client_golang/prometheus"
 	httpSwagger "github.com/swaggo/http-swagger"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
 )
 
+const swaggerSpecPath = "/docs/swagger.yml"
+
+// Features of the API that can be disabled to expose a minimal surface.
+const (
+	// FeatureStats covers the endpoint serving the statistics of a URL.
+	FeatureStats = "stats"
+	// FeatureRedirect covers the endpoint redirecting short codes to their original URLs.
+	FeatureRedirect = "redirect"
+	// FeatureBatch covers the endpoints looking up the URLs and access counts of several short codes at once.
+	FeatureBatch = "batch"
+	// FeatureList covers the endpoint listing URLs.
+	FeatureList = "list"
+)
+
+// RouterOption defines a functional option for configuring the router.
+type RouterOption func(*routerOptions)
+
+// routerOptions holds the configurable parameters of the router.
+type routerOptions struct {
+	swaggerEnabled bool
+	swaggerPath    string
+	requestTimeout time.Duration
+	adminToken     string
+	trustedProxies []netip.Prefix
+
+	urlHandlerConfig
+	rootRedirectURL string
+	shortCodeLength int
+	readOnly        bool
+	prettyJSON      bool
+	compression     bool
+	serverTiming    bool
+
+	maxConcurrentRequests int
+	logSampleRate         int
+	linkCheck             bool
+	features              map[string]bool
+	tokenVerifier         tokenVerifier
+	authOperations        []string
+	corsMaxAge            time.Duration
+	corsExposedHeaders    []string
+	metricsHandler        http.Handler
+	metricsRegisterer     prometheus.Registerer
+	readinessCheck        func(ctx context.Context) error
+}
+
+// defaultRouterOptions provides default configuration values for the router.
+var defaultRouterOptions = routerOptions{
+	swaggerEnabled:     true,
+	swaggerPath:        "/swagger",
+	requestTimeout:     8 * time.Second,
+	corsMaxAge:         24 * time.Hour,
+	corsExposedHeaders: []string{"Location", "Link", middleware.RequestIDHeader},
+	urlHandlerConfig: urlHandlerConfig{
+		maxBatchSize: defaultMaxBatchSize,
+		listLimit:    defaultListLimit,
+		listSort:     string(entity.URLSortOldest),
+	},
+}
+
+// WithSwagger enables or disables the Swagger UI and sets the path it is mounted on.
+func WithSwagger(enabled bool, path string) RouterOption {
+	return func(o *routerOptions) {
+		o.swaggerEnabled = enabled
+		o.swaggerPath = path
+	}
+}
+
+// WithRequestTimeout sets the maximum duration of request handling.
+// A non-positive duration disables the timeout.
+func WithRequestTimeout(d time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.requestTimeout = d
+	}
+}
+
+// WithMaxConcurrentRequests sets the maximum number of requests served at a time.
+// Requests over the limit are rejected with 503 Service Unavailable. A non-positive limit disables it.
+func WithMaxConcurrentRequests(n int) RouterOption {
+	return func(o *routerOptions) {
+		o.maxConcurrentRequests = n
+	}
+}
+
+// WithLogSampleRate logs only 1 in n successful GET and HEAD requests, such as redirects and resolves,
+// to reduce the log volume of high traffic. Failed requests and requests modifying data are always logged.
+// A rate of 1 or less logs every request.
+func WithLogSampleRate(n int) RouterOption {
+	return func(o *routerOptions) {
+		o.logSampleRate = n
+	}
+}
+
+// WithCORSMaxAge sets how long browsers may cache the responses to CORS preflight requests.
+// A non-positive duration leaves caching up to the browser.
+func WithCORSMaxAge(d time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.corsMaxAge = d
+	}
+}
+
+// WithCORSExposedHeaders sets the response headers that browser scripts of other origins may read,
+// besides the CORS-safelisted ones. By default, Location, Link and X-Request-ID are exposed.
+func WithCORSExposedHeaders(headers ...string) RouterOption {
+	return func(o *routerOptions) {
+		o.corsExposedHeaders = headers
+	}
+}
+
+// WithMetricsHandler sets the handler serving metrics on /metrics, e.g. promhttp.Handler().
+// The endpoint is disabled if the handler is nil.
+func WithMetricsHandler(h http.Handler) RouterOption {
+	return func(o *routerOptions) {
+		o.metricsHandler = h
+	}
+}
+
+// WithMetricsRegisterer observes the duration of requests and the size of responses in the
+// http_request_duration_seconds and http_response_size_bytes histograms registered with reg,
+// labeled by operation and status code. Requests aren't observed if reg is nil.
+func WithMetricsRegisterer(reg prometheus.Registerer) RouterOption {
+	return func(o *routerOptions) {
+		o.metricsRegisterer = reg
+	}
+}
+
+// WithReadinessCheck sets the check run on /readyz, e.g. pinging the database. The endpoint responds
+// with 503 Service Unavailable while the check fails, and with 200 OK if it passes or isn't set.
+func WithReadinessCheck(check func(ctx context.Context) error) RouterOption {
+	return func(o *routerOptions) {
+		o.readinessCheck = check
+	}
+}
+
+// WithLinkCheck sets whether the admin endpoint checking whether the original URLs are reachable is served.
+// It is disabled by default, since every check sends requests to the destinations of the checked URLs.
+func WithLinkCheck(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.linkCheck = enabled
+	}
+}
+
+// WithFeatures enables or disables features of the API, e.g. FeatureRedirect, by name.
+// Features are enabled unless they are disabled here. Disabled features respond with 404 Not Found.
+func WithFeatures(features map[string]bool) RouterOption {
+	return func(o *routerOptions) {
+		o.features = features
+	}
+}
+
+// WithJWTAuth requires a bearer token accepted by the verifier, e.g. a JWT issued by an identity provider,
+// on the routes of the given operations, e.g. shorten. The operations are named as in the request logs.
+func WithJWTAuth(verifier tokenVerifier, operations ...string) RouterOption {
+	return func(o *routerOptions) {
+		o.tokenVerifier = verifier
+		o.authOperations = operations
+	}
+}
+
+// WithAdminToken sets the bearer token required to access the admin endpoints.
+// The admin endpoints are disabled if the token is empty.
+func WithAdminToken(token string) RouterOption {
+	return func(o *routerOptions) {
+		o.adminToken = token
+	}
+}
+
+// WithTrustedProxies sets the address ranges of the proxies whose X-Real-IP and
+// X-Forwarded-For headers are trusted to report the client IP.
+// By default, the forwarding headers are ignored.
+func WithTrustedProxies(prefixes ...netip.Prefix) RouterOption {
+	return func(o *routerOptions) {
+		o.trustedProxies = prefixes
+	}
+}
+
+// WithNotFoundRedirect sets the URL requests to follow unknown short codes are redirected to.
+// If the URL is empty, such requests are answered with 404 Not Found, as are requests to resolve them
+// with the JSON API. Deactivated and expired short codes are answered with 410 Gone either way.
+func WithNotFoundRedirect(url string) RouterOption {
+	return func(o *routerOptions) {
+		o.notFoundRedirectURL = url
+	}
+}
+
+// WithExpiredRedirect sets the URL requests to follow expired short codes are redirected to,
+// e.g. a page explaining that the link has expired. It takes precedence over WithNotFoundRedirect
+// and WithExpiredPage for expired short codes.
+func WithExpiredRedirect(url string) RouterOption {
+	return func(o *routerOptions) {
+		o.expiredRedirectURL = url
+	}
+}
+
+// WithExpiredPage enables answering requests to follow expired short codes with a small
+// "this link has expired" HTML page and 410 Gone, instead of a JSON error or the not found redirect.
+func WithExpiredPage(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.expiredPage = enabled
+	}
+}
+
+// WithInterstitial enables answering requests to follow short codes with an HTML page naming
+// the original URL and linking to it, e.g. for advertising or compliance, instead of an immediate redirect.
+// The page redirects to the original URL after the delay if it is positive; otherwise visitors have to
+// follow the link. The click is counted once either way.
+func WithInterstitial(enabled bool, delay time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.interstitial = enabled
+		o.interstitialDelay = delay
+	}
+}
+
+// WithRootRedirect sets the URL requests to the root path are redirected to, e.g. a landing page.
+// If the URL is empty, the root path responds with a JSON banner describing the service.
+func WithRootRedirect(url string) RouterOption {
+	return func(o *routerOptions) {
+		o.rootRedirectURL = url
+	}
+}
+
+// WithRedirectCacheMaxAge sets how long redirects to original URLs may be cached by clients and CDNs.
+// A non-positive duration disables caching of redirects.
+func WithRedirectCacheMaxAge(d time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.redirectCacheMaxAge = d
+	}
+}
+
+// WithBaseURL sets the public URL of the service, e.g. https://sho.rt, which the links of URLs
+// requested by hypermedia clients are built from. Links are root-relative if the URL is empty.
+func WithBaseURL(url string) RouterOption {
+	return func(o *routerOptions) {
+		o.baseURL = url
+	}
+}
+
+// WithShortCodeLength sets the length of generated short codes reported to clients by the config endpoint.
+func WithShortCodeLength(n int) RouterOption {
+	return func(o *routerOptions) {
+		o.shortCodeLength = n
+	}
+}
+
+// WithMaxBatchSize sets the maximum number of short codes of the batch lookup and access count requests,
+// which bounds the memory and database work of a single request. Larger batches are rejected with 400 Bad Request.
+func WithMaxBatchSize(n int) RouterOption {
+	return func(o *routerOptions) {
+		o.maxBatchSize = n
+	}
+}
+
+// WithListDefaults sets the number of URLs listed if the limit isn't specified, capped at 100,
+// and the order they are listed in if the sort isn't specified: oldest, newest or short_code.
+// By default, 20 URLs are listed, oldest first.
+func WithListDefaults(limit int, sort string) RouterOption {
+	return func(o *routerOptions) {
+		o.listLimit = limit
+		o.listSort = sort
+	}
+}
+
+// WithReadOnly sets whether the router starts in read-only mode, in which the write endpoints
+// respond with 503 Service Unavailable. The mode can be toggled at runtime through the admin endpoints.
+func WithReadOnly(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.readOnly = enabled
+	}
+}
+
+// WithPrettyJSON sets whether JSON responses are indented for readability, e.g. in development.
+// JSON responses are compact by default.
+func WithPrettyJSON(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.prettyJSON = enabled
+	}
+}
+
+// WithCompression sets whether JSON and text responses of at least 1 KiB are compressed
+// with gzip or deflate for clients that accept it. Responses are uncompressed by default.
+func WithCompression(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.compression = enabled
+	}
+}
+
+// WithServerTiming sets whether responses carry a Server-Timing header with the time spent in the database
+// and the total time spent serving the request. The header is omitted by default, since it reveals
+// the internal timings of the service to every client. When enabled, it is also exposed to browser scripts
+// of other origins, see WithCORSExposedHeaders.
+func WithServerTiming(enabled bool) RouterOption {
+	return func(o *routerOptions) {
+		o.serverTiming = enabled
+	}
+}
+
 // NewRouter initializes and returns a new Chi router configured with middleware and routes for the URL shortener API.
-func NewRouter(logger *httplog.Logger, urlUseCase urlUseCase) *chi.Mux {
+func NewRouter(logger *httplog.Logger, urlUseCase urlUseCase, opts ...RouterOption) *chi.Mux {
+	r, _ := newRouters(logger, urlUseCase, false, opts...)
+	return r
+}
+
+// NewRouters initializes and returns separate routers for the public API and the internal endpoints,
+// so that the internal endpoints can be served on a port that isn't exposed to the public.
+// The admin router serves the metrics on /metrics, health checks on /healthz and /readyz and the admin endpoints
+// on /admin/*, none of which are served by the public router.
+func NewRouters(logger *httplog.Logger, urlUseCase urlUseCase, opts ...RouterOption) (public, admin *chi.Mux) {
+	return newRouters(logger, urlUseCase, true, opts...)
+}
+
+// newRouters creates the public router along with the admin router if separateAdmin is set.
+// Otherwise, the metrics and admin endpoints are served by the public router and the admin router is nil.
+func newRouters(
+	logger *httplog.Logger,
+	urlUseCase urlUseCase,
+	separateAdmin bool,
+	opts ...RouterOption,
+) (public, admin *chi.Mux) {
+	o := defaultRouterOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// feature returns the handler of the feature's endpoint, or a handler responding with 404 Not Found
+	// if the feature is disabled. Disabled endpoints stay mounted, since their paths may match other routes
+	// serving other methods, e.g. /{shortCode}, which would respond with 405 Method Not Allowed instead.
+	feature := func(name string, handler http.HandlerFunc) http.HandlerFunc {
+		if on, ok := o.features[name]; ok && !on {
+			return handleNotFound
+		}
+		return handler
+	}
+
+	// operation names the operation served by the route like the operation middleware, and additionally
+	// requires a valid token if the operation requires authentication.
+	operation := func(name string) func(http.Handler) http.Handler {
+		if o.tokenVerifier == nil || !slices.Contains(o.authOperations, name) {
+			return operation(name)
+		}
+
+		return func(next http.Handler) http.Handler {
+			return operation(name)(jwtAuth(o.tokenVerifier)(next))
+		}
+	}
+
+	validate := validator.New()
+	h := newURLHandler(urlUseCase, validate, o.urlHandlerConfig)
+
+	// The histograms are shared by both routers, since they can only be registered once.
+	var m *httpMetrics
+	if o.metricsRegisterer != nil {
+		m = newHTTPMetrics(o.metricsRegisterer)
+	}
+
+	readOnly := new(atomic.Bool)
+	readOnly.Store(o.readOnly)
+	ah := newAdminHandler(validate, readOnly)
+
+	adminRoutes := func(r chi.Router) {
+		if o.adminToken == "" {
+			return
+		}
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(adminAuth(o.adminToken))
+
+			r.With(operation("get_summary")).Get("/stats", h.getSummary)
+			r.With(operation("get_domain_stats")).Get("/domains", h.getDomainStats)
+			r.With(operation("get_read_only")).Get("/read-only", ah.getReadOnly)
+			r.With(operation("set_read_only")).Put("/read-only", ah.setReadOnly)
+
+			if o.linkCheck {
+				r.With(operation("check_links")).Post("/linkcheck", h.checkLinks)
+			}
+		})
+	}
+
+	metricsRoute := func(r chi.Router) {
+		if o.metricsHandler != nil {
+			r.Method(http.MethodGet, "/metrics", o.metricsHandler)
+		}
+	}
+
+	readinessRoute := func(r chi.Router) {
+		r.With(operation("readyz")).Get("/readyz", handleReadyz(o.readinessCheck))
+	}
+
+	exposedHeaders := o.corsExposedHeaders
+	if o.serverTiming && !slices.ContainsFunc(exposedHeaders, func(h string) bool { return strings.EqualFold(h, "Server-Timing") }) {
+		exposedHeaders = append(slices.Clip(exposedHeaders), "Server-Timing")
+	}
+
 	r := chi.NewRouter()
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"https://*"},
-		AllowedMethods:   []string{"POST", "GET", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Accept"},
+		AllowedMethods:   []string{"POST", "GET", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Accept", "Authorization"},
+		ExposedHeaders:   exposedHeaders,
 		AllowCredentials: false,
-		MaxAge:           84600,
+		MaxAge:           int(o.corsMaxAge.Seconds()),
 	}))
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(httplog.RequestLogger(logger))
-	r.Use(middleware.Recoverer)
+	useCommonMiddleware(r, logger, m, o)
 
-	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("/docs/swagger.yml"),
-	))
+	var docsURL string
 
-	r.Get("/docs/swagger.yml", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "./docs/swagger.yml")
-	})
+	if o.swaggerEnabled {
+		docsURL = o.swaggerPath + "/index.html"
+
+		r.Get(o.swaggerPath+"/*", httpSwagger.Handler(
+			httpSwagger.URL(swaggerSpecPath),
+		))
+
+		r.Get(swaggerSpecPath, handleSwaggerSpec)
+	}
+
+	r.With(operation("root")).Get("/", handleRoot(o.rootRedirectURL, docsURL))
+
+	if !separateAdmin {
+		metricsRoute(r)
+		readinessRoute(r)
+	}
 
 	r.Route("/api/v1", func(r chi.Router) {
-		r.Get("/ping", handlePing)
+		r.With(operation("ping")).Get("/ping", handlePing)
+		r.With(operation("get_config")).Get("/config", handleConfig(configResponse{
+			BaseURL:         o.baseURL,
+			ShortCodeLength: o.shortCodeLength,
+			MaxBatchSize:    o.maxBatchSize,
+			Features:        enabledFeatures(o.features),
+		}))
 
 		r.Route("/shorten", func(r chi.Router) {
-			validate := validator.New()
-			h := newURLHandler(urlUseCase, validate)
+			// The lookups only read URLs, so they keep working in read-only mode despite being POST requests.
+			r.With(operation("lookup")).Post("/lookup", feature(FeatureBatch, h.lookupURLs))
+			r.With(operation("get_access_counts")).Post("/stats", feature(FeatureBatch, h.getAccessCounts))
+
+			r.Group(func(r chi.Router) {
+				r.Use(rejectWritesIf(readOnly))
+
+				r.With(operation("list")).Get("/", feature(FeatureList, h.listURLs))
+				r.With(operation("shorten")).Post("/", h.shortenURL)
+				r.With(operation("reserve")).Post("/reserve", h.reserveShortCode)
+
+				r.Route("/{shortCode}", func(r chi.Router) {
+					r.Use(validShortCode)
 
-			r.Post("/", h.shortenURL)
+					r.With(operation("resolve")).Get("/", h.resolveShortCode)
+					r.With(operation("exists")).Head("/", h.shortCodeExists)
+					r.With(operation("redirect")).Get("/redirect", feature(FeatureRedirect, h.redirectShortCode))
+					r.With(operation("redirect")).Head("/redirect", feature(FeatureRedirect, h.redirectShortCode))
+					r.With(operation("upsert")).Put("/", h.upsertURL)
+					r.With(operation("update")).Patch("/", h.updateURL)
+					r.With(operation("deactivate")).Delete("/", h.deactivateURL)
+					r.With(operation("rename")).Patch("/code", h.renameShortCode)
+					r.With(operation("clone")).Post("/clone", h.cloneURL)
+					r.With(operation("add_alias")).Post("/aliases", h.addAlias)
+					r.With(operation("get_stats")).Get("/stats", feature(FeatureStats, h.getURLStats))
 
-			r.Route("/{shortCode}", func(r chi.Router) {
-				r.Get("/", h.resolveShortCode)
-				r.Put("/", h.modifyURL)
-				r.Delete("/", h.deactivateURL)
-				r.Get("/stats", h.getURLStats)
+					// Access events and creators expose the IP addresses of clients, and resetting statistics
+					// discards them, so they are only served to admins.
+					if o.adminToken != "" {
+						r.With(operation("list_access_events"), adminAuth(o.adminToken)).Get("/events", h.getAccessEvents)
+						r.With(operation("get_creator"), adminAuth(o.adminToken)).Get("/creator", h.getCreator)
+						r.With(operation("reset_stats"), adminAuth(o.adminToken)).Post("/stats/reset", h.resetStats)
+					}
+				})
 			})
 		})
+
+		if !separateAdmin {
+			adminRoutes(r)
+		}
 	})
 
-	return r
+	handleUnmatchedRoutes(r)
+
+	if !separateAdmin {
+		return r, nil
+	}
+
+	a := chi.NewRouter()
+
+	useCommonMiddleware(a, logger, m, o)
+
+	a.With(operation("healthz")).Get("/healthz", handleHealthz)
+	readinessRoute(a)
+	metricsRoute(a)
+	adminRoutes(a)
+
+	handleUnmatchedRoutes(a)
+
+	return r, a
+}
+
+// enabledFeatures reports for each feature of the API whether it is enabled, given the features
+// enabled or disabled by WithFeatures. Features are enabled unless they are disabled.
+func enabledFeatures(features map[string]bool) map[string]bool {
+	enabled := make(map[string]bool)
+
+	for _, name := range []string{FeatureBatch, FeatureList, FeatureRedirect, FeatureStats} {
+		on, ok := features[name]
+		enabled[name] = on || !ok
+	}
+
+	return enabled
+}
+
+// handleUnmatchedRoutes sets the handlers of requests that match no route of the router, which respond
+// with JSON like the rest of the API. It must be called once all routes of the router are registered.
+func handleUnmatchedRoutes(r *chi.Mux) {
+	r.NotFound(handleNotFound)
+	r.MethodNotAllowed(handleMethodNotAllowed(r))
+}
+
+// useCommonMiddleware sets up the middleware shared by the public and admin routers.
+// Requests are observed by m unless it is nil.
+func useCommonMiddleware(r chi.Router, logger *httplog.Logger, m *httpMetrics, o routerOptions) {
+	// Trailing slashes are stripped rather than redirected, so that both forms of a URL
+	// are served directly, e.g. /api/v1/shorten/abc123 and /api/v1/shorten/abc123/,
+	// without an extra round trip for API clients that don't follow redirects.
+	r.Use(middleware.StripSlashes)
+	r.Use(middleware.RequestID)
+	r.Use(requestIDHeader)
+
+	if o.serverTiming {
+		r.Use(serverTiming)
+	}
+
+	if m != nil {
+		r.Use(m.middleware)
+	}
+
+	r.Use(realIP(o.trustedProxies))
+	r.Use(httplog.RequestLogger(logger))
+	r.Use(logFields)
+
+	if o.logSampleRate > 1 {
+		r.Use(sampleLogs(o.logSampleRate))
+	}
+
+	r.Use(recoverer)
+
+	if o.maxConcurrentRequests > 0 {
+		r.Use(concurrencyLimit(o.maxConcurrentRequests))
+	}
+
+	if o.prettyJSON {
+		r.Use(prettyJSON)
+	}
+
+	if o.compression {
+		r.Use(compress)
+	}
+
+	if o.requestTimeout > 0 {
+		r.Use(timeout(o.requestTimeout))
+	}
 }
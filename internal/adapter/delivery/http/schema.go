@@ -1,37 +1,422 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/go-playground/validator/v10"
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
 )
 
 const statusError = "error"
 
+// shortCodeRegexp matches short codes consisting of the characters used for generated codes.
+var shortCodeRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// maxShortCodeLength is the maximum length of short codes that can be stored in the database.
+const maxShortCodeLength = 50
+
 // urlRequest represents the structure for a request to shorten or modifying a URL.
+// The note, tags, UTM parameters, tier and encoding are only set when the URL is shortened.
 type urlRequest struct {
-	OriginalURL string `json:"original_url" validate:"required,url"`
+	OriginalURL string      `json:"original_url" validate:"required,url,httpurl"`
+	Note        string      `json:"note" validate:"max=255"`
+	Tags        []string    `json:"tags" validate:"max=10,dive,required,max=50"`
+	UTM         *utmRequest `json:"utm"`
+	Tier        string      `json:"tier" validate:"max=50"`
+	Encoding    string      `json:"encoding" validate:"max=50"`
+}
+
+// utmRequest represents the UTM parameters set on the original URL when it is shortened.
+type utmRequest struct {
+	Source   string `json:"source" validate:"max=255"`
+	Medium   string `json:"medium" validate:"max=255"`
+	Campaign string `json:"campaign" validate:"max=255"`
+	Term     string `json:"term" validate:"max=255"`
+	Content  string `json:"content" validate:"max=255"`
+}
+
+// trimSpace trims the whitespace surrounding the original URL, e.g. a trailing newline it was pasted with,
+// which would otherwise fail validation.
+func (req *urlRequest) trimSpace() {
+	req.OriginalURL = strings.TrimSpace(req.OriginalURL)
+}
+
+// toUTM converts the UTM parameters of the request to an entity.UTM, which is empty if none are given.
+func (req urlRequest) toUTM() entity.UTM {
+	if req.UTM == nil {
+		return entity.UTM{}
+	}
+
+	return entity.UTM{
+		Source:   req.UTM.Source,
+		Medium:   req.UTM.Medium,
+		Campaign: req.UTM.Campaign,
+		Term:     req.UTM.Term,
+		Content:  req.UTM.Content,
+	}
+}
+
+const (
+	// defaultListLimit is the number of items listed if the limit isn't specified, unless set by WithListDefaults
+	// for listing URLs.
+	defaultListLimit = 20
+	// maxListLimit caps the number of URLs listed by a single request.
+	maxListLimit = 100
+)
+
+// Pagination modes of listing URLs.
+const (
+	paginationOffset = "offset"
+	paginationCursor = "cursor"
+)
+
+// listRequest represents the query parameters of a request to list URLs.
+// URLs are paged by offset in the requested order unless cursor pagination is requested, in which case they
+// are listed newest first regardless of the order and the next page starts after the URL with the ID given
+// as the cursor.
+type listRequest struct {
+	Query      string   `json:"q" validate:"max=255"`
+	Tags       []string `json:"tag" validate:"max=10,dive,required,max=50"`
+	Limit      int      `json:"limit" validate:"min=1"`
+	Offset     int      `json:"offset" validate:"min=0"`
+	Sort       string   `json:"sort" validate:"oneof=oldest newest short_code"`
+	Pagination string   `json:"pagination" validate:"oneof=offset cursor"`
+	Cursor     int64    `json:"cursor" validate:"min=0"`
+}
+
+// decodeQuery decodes the query parameters into the fields of listRequest. The tag parameter may be repeated.
+// Missing parameters leave the corresponding fields unchanged and the limit is capped at maxListLimit.
+func decodeQuery(values url.Values, req *listRequest) error {
+	req.Query = strings.TrimSpace(values.Get("q"))
+	req.Tags = values["tag"]
+
+	if values.Has("pagination") {
+		req.Pagination = values.Get("pagination")
+	}
+
+	if values.Has("sort") {
+		req.Sort = values.Get("sort")
+	}
+
+	if values.Has("cursor") {
+		n, err := strconv.ParseInt(values.Get("cursor"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid cursor: %w", err)
+		}
+
+		req.Cursor = n
+	}
+
+	for name, field := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+		if !values.Has(name) {
+			continue
+		}
+
+		n, err := strconv.Atoi(values.Get(name))
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+
+		*field = n
+	}
+
+	req.Limit = min(req.Limit, maxListLimit)
+
+	return nil
+}
+
+// eventsRequest represents the query parameters of a request to list the access events of a URL.
+type eventsRequest struct {
+	Limit  int   `json:"limit" validate:"min=1"`
+	Before int64 `json:"before" validate:"min=0"`
+}
+
+// decodeEventsQuery decodes the query parameters into the fields of eventsRequest.
+// Missing parameters leave the corresponding fields unchanged and the limit is capped at maxListLimit.
+func decodeEventsQuery(values url.Values, req *eventsRequest) error {
+	if values.Has("limit") {
+		n, err := strconv.Atoi(values.Get("limit"))
+		if err != nil {
+			return fmt.Errorf("invalid limit: %w", err)
+		}
+
+		req.Limit = n
+	}
+
+	if values.Has("before") {
+		n, err := strconv.ParseInt(values.Get("before"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid before: %w", err)
+		}
+
+		req.Before = n
+	}
+
+	req.Limit = min(req.Limit, maxListLimit)
+
+	return nil
+}
+
+// domainsRequest represents the query parameters of a request to retrieve the statistics of destination hosts.
+type domainsRequest struct {
+	Domain string `json:"domain" validate:"max=253"`
+	Limit  int    `json:"limit" validate:"min=1"`
+}
+
+// decodeDomainsQuery decodes the query parameters into the fields of domainsRequest.
+// Missing parameters leave the corresponding fields unchanged and the limit is capped at maxListLimit.
+func decodeDomainsQuery(values url.Values, req *domainsRequest) error {
+	req.Domain = strings.TrimSpace(values.Get("domain"))
+
+	if values.Has("limit") {
+		n, err := strconv.Atoi(values.Get("limit"))
+		if err != nil {
+			return fmt.Errorf("invalid limit: %w", err)
+		}
+
+		req.Limit = n
+	}
+
+	req.Limit = min(req.Limit, maxListLimit)
+
+	return nil
+}
+
+// defaultMaxBatchSize is the maximum number of short codes of a lookupRequest unless set by WithMaxBatchSize.
+const defaultMaxBatchSize = 100
+
+// lookupRequest represents the structure for a request to retrieve the URLs or access counts of several
+// short codes at once. The number of short codes is limited by the handler, since the limit is configurable.
+type lookupRequest struct {
+	ShortCodes []string `json:"short_codes" validate:"required,min=1,dive,required,max=50,shortcode"`
+}
+
+// shortCodeRequest represents the structure for a request to change the short code of a URL.
+type shortCodeRequest struct {
+	ShortCode string `json:"short_code" validate:"required,max=50,shortcode"`
+}
+
+// updateRequest represents the structure for a request to update several fields of a URL at once.
+// Fields missing from the request, or set to null, are left unchanged.
+type updateRequest struct {
+	OriginalURL *string    `json:"original_url" validate:"omitnil,required,url,httpurl"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	Active      *bool      `json:"active"`
+	Tags        *[]string  `json:"tags" validate:"omitnil,max=10,dive,required,max=50"`
+	Note        *string    `json:"note" validate:"omitnil,max=255"`
+}
+
+// trimSpace trims the whitespace surrounding the original URL, if it is set, like urlRequest.trimSpace.
+func (req *updateRequest) trimSpace() {
+	if req.OriginalURL != nil {
+		originalURL := strings.TrimSpace(*req.OriginalURL)
+		req.OriginalURL = &originalURL
+	}
+}
+
+// toURLUpdate converts the request to an entity.URLUpdate.
+func (req updateRequest) toURLUpdate() entity.URLUpdate {
+	return entity.URLUpdate{
+		OriginalURL: req.OriginalURL,
+		ExpiresAt:   req.ExpiresAt,
+		Active:      req.Active,
+		Tags:        req.Tags,
+		Note:        req.Note,
+	}
 }
 
 // urlResponse represents the structure for a response containing shortened URL information.
 type urlResponse struct {
-	ID          int64     `json:"id"`
-	ShortCode   string    `json:"short_code"`
-	OriginalURL string    `json:"original_url"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          int64      `json:"id"`
+	ShortCode   string     `json:"short_code"`
+	OriginalURL string     `json:"original_url"`
+	Active      bool       `json:"active"`
+	Tags        []string   `json:"tags"`
+	Note        string     `json:"note"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Links       *urlLinks  `json:"_links,omitempty"`
 }
 
-// toURLResponse converts an entity.URL to a urlResponse.
-func toURLResponse(url *entity.URL) urlResponse {
-	return urlResponse{
+// toURLResponse converts an entity.URL to a urlResponse. URLs without tags have an empty list of tags.
+// The links of the URL are built with l unless it is nil.
+func toURLResponse(url *entity.URL, l *linker) urlResponse {
+	tags := url.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	resp := urlResponse{
 		ID:          url.ID,
 		ShortCode:   url.ShortCode,
 		OriginalURL: url.OriginalURL,
+		Active:      url.Active,
+		Tags:        tags,
+		Note:        url.Note,
 		CreatedAt:   url.CreatedAt,
 		UpdatedAt:   url.UpdatedAt,
 	}
+
+	if !url.ExpiresAt.IsZero() {
+		resp.ExpiresAt = &url.ExpiresAt
+	}
+
+	if l != nil {
+		resp.Links = l.urlLinks(url.ShortCode)
+	}
+
+	return resp
+}
+
+// urlListResponse represents the structure for a response containing a page of URLs.
+// NextCursor is the value of the cursor parameter retrieving the next page with cursor pagination,
+// set only if the page is full.
+type urlListResponse struct {
+	URLs       []urlResponse `json:"urls"`
+	Limit      int           `json:"limit"`
+	Offset     int           `json:"offset"`
+	NextCursor *int64        `json:"next_cursor,omitempty"`
+}
+
+// toURLListResponse converts a slice of entity.URL listed for the request to a urlListResponse.
+func toURLListResponse(urls []entity.URL, req listRequest, l *linker) urlListResponse {
+	resp := urlListResponse{
+		URLs:   make([]urlResponse, 0, len(urls)),
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+
+	for i := range urls {
+		resp.URLs = append(resp.URLs, toURLResponse(&urls[i], l))
+	}
+
+	if req.Pagination == paginationCursor && len(urls) > 0 && len(urls) == req.Limit {
+		resp.NextCursor = &urls[len(urls)-1].ID
+	}
+
+	return resp
+}
+
+// accessEventResponse represents the structure for a recorded access to a URL.
+type accessEventResponse struct {
+	ID         int64     `json:"id"`
+	IP         string    `json:"ip"`
+	Referrer   string    `json:"referrer"`
+	AccessedAt time.Time `json:"accessed_at"`
+}
+
+// accessEventsResponse represents the structure for a response containing a page of access events.
+// NextBefore is the value of the before parameter retrieving the next page, set only if the page is full.
+type accessEventsResponse struct {
+	Events     []accessEventResponse `json:"events"`
+	Limit      int                   `json:"limit"`
+	NextBefore *int64                `json:"next_before,omitempty"`
+}
+
+// toAccessEventsResponse converts a slice of entity.AccessEvent listed for the request to an accessEventsResponse.
+func toAccessEventsResponse(events []entity.AccessEvent, req eventsRequest) accessEventsResponse {
+	resp := accessEventsResponse{
+		Events: make([]accessEventResponse, 0, len(events)),
+		Limit:  req.Limit,
+	}
+
+	for _, event := range events {
+		resp.Events = append(resp.Events, accessEventResponse{
+			ID:         event.ID,
+			IP:         event.IP,
+			Referrer:   event.Referrer,
+			AccessedAt: event.AccessedAt,
+		})
+	}
+
+	if len(events) > 0 && len(events) == req.Limit {
+		resp.NextBefore = &events[len(events)-1].ID
+	}
+
+	return resp
+}
+
+// creatorResponse represents the structure for the client that created a URL. The IP address is hashed
+// if IP hashing is enabled, and both fields are empty if creator tracking was disabled when the URL was created.
+type creatorResponse struct {
+	ShortCode string `json:"short_code"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+// lookupResponse represents the structure for a response containing the URLs of the requested short codes.
+// Both the URLs and the missing short codes are listed in the order they were requested.
+type lookupResponse struct {
+	URLs    []urlResponse `json:"urls"`
+	Missing []string      `json:"missing"`
+}
+
+// toAccessCountsResponse maps each of the requested short codes to its access count,
+// or to nil if the short code is unknown, so that it is serialized as null.
+func toAccessCountsResponse(counts map[string]int64, shortCodes []string) map[string]*int64 {
+	resp := make(map[string]*int64, len(shortCodes))
+
+	for _, code := range shortCodes {
+		if count, ok := counts[code]; ok {
+			resp[code] = &count
+		} else {
+			resp[code] = nil
+		}
+	}
+
+	return resp
+}
+
+// toLookupResponse converts the URLs found for the requested short codes to a lookupResponse.
+// Short codes requested more than once are only listed once.
+func toLookupResponse(urls map[string]*entity.URL, shortCodes []string, l *linker) lookupResponse {
+	resp := lookupResponse{
+		URLs:    make([]urlResponse, 0, len(urls)),
+		Missing: make([]string, 0),
+	}
+
+	seen := make(map[string]struct{}, len(shortCodes))
+
+	for _, code := range shortCodes {
+		if _, ok := seen[code]; ok {
+			continue
+		}
+		seen[code] = struct{}{}
+
+		if url, ok := urls[code]; ok {
+			resp.URLs = append(resp.URLs, toURLResponse(url, l))
+		} else {
+			resp.Missing = append(resp.Missing, code)
+		}
+	}
+
+	return resp
+}
+
+// reservationResponse represents the structure for a response containing a reserved short code.
+type reservationResponse struct {
+	ShortCode string    `json:"short_code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// toReservationResponse converts an entity.URL to a reservationResponse.
+func toReservationResponse(url *entity.URL) reservationResponse {
+	return reservationResponse{
+		ShortCode: url.ShortCode,
+		ExpiresAt: url.ExpiresAt,
+	}
 }
 
 // urlStatsResponse represents the structure for a response containing URL statistics.
@@ -45,8 +430,66 @@ type urlStatsResponse struct {
 }
 
 // urlStats represents the statistics for a URL.
+// AccessCountStr duplicates AccessCount as a string, because JavaScript clients
+// lose precision on integers above 2^53.
 type urlStats struct {
-	AccessCount int64 `json:"access_count"`
+	AccessCount    int64        `json:"access_count"`
+	AccessCountStr string       `json:"access_count_str"`
+	TopReferrers   []statCount  `json:"top_referrers"`
+	UserAgents     []statCount  `json:"user_agents"`
+	Countries      []statCount  `json:"countries"`
+	Aliases        []aliasStats `json:"aliases"`
+}
+
+// aliasStats represents the number of accesses through an alias of a URL, which are included
+// in the access count of the URL.
+type aliasStats struct {
+	ShortCode   string `json:"short_code"`
+	AccessCount int64  `json:"access_count"`
+}
+
+// toAliasStats converts a slice of entity.URLAlias to a non-nil slice of aliasStats.
+func toAliasStats(aliases []entity.URLAlias) []aliasStats {
+	res := make([]aliasStats, 0, len(aliases))
+	for _, a := range aliases {
+		res = append(res, aliasStats{ShortCode: a.ShortCode, AccessCount: a.AccessCount})
+	}
+
+	return res
+}
+
+// aliasResponse represents the structure for a response containing an alias of a URL.
+type aliasResponse struct {
+	ShortCode          string    `json:"short_code"`
+	CanonicalShortCode string    `json:"canonical_short_code"`
+	AccessCount        int64     `json:"access_count"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// toAliasResponse converts an entity.URLAlias to an aliasResponse.
+func toAliasResponse(alias *entity.URLAlias) aliasResponse {
+	return aliasResponse{
+		ShortCode:          alias.ShortCode,
+		CanonicalShortCode: alias.CanonicalShortCode,
+		AccessCount:        alias.AccessCount,
+		CreatedAt:          alias.CreatedAt,
+	}
+}
+
+// statCount represents the number of clicks sharing the same value, e.g. the same referrer.
+type statCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// toStatCounts converts a slice of entity.StatCount to a non-nil slice of statCount.
+func toStatCounts(counts []entity.StatCount) []statCount {
+	res := make([]statCount, 0, len(counts))
+	for _, c := range counts {
+		res = append(res, statCount{Value: c.Value, Count: c.Count})
+	}
+
+	return res
 }
 
 // toURLStatsResponse converts an entity.URL to a urlStatsResponse.
@@ -56,13 +499,203 @@ func toURLStatsResponse(url *entity.URL) urlStatsResponse {
 		ShortCode:   url.ShortCode,
 		OriginalURL: url.OriginalURL,
 		Stats: urlStats{
-			AccessCount: url.URLStats.AccessCount,
+			AccessCount:    url.URLStats.AccessCount,
+			AccessCountStr: strconv.FormatInt(url.URLStats.AccessCount, 10),
+			TopReferrers:   toStatCounts(url.URLStats.TopReferrers),
+			UserAgents:     toStatCounts(url.URLStats.UserAgents),
+			Countries:      toStatCounts(url.URLStats.Countries),
+			Aliases:        toAliasStats(url.URLStats.Aliases),
 		},
 		CreatedAt: url.CreatedAt,
 		UpdatedAt: url.UpdatedAt,
 	}
 }
 
+// toURLStatsCSV converts the statistics of an entity.URL to CSV records with a header row.
+// Each record holds a statistics dimension, its value and the number of clicks.
+func toURLStatsCSV(url *entity.URL) [][]string {
+	records := [][]string{
+		{"dimension", "value", "count"},
+		{"access_count", "", strconv.FormatInt(url.AccessCount, 10)},
+	}
+
+	dimensions := []struct {
+		name   entity.ClickDimension
+		counts []entity.StatCount
+	}{
+		{entity.ClickDimensionReferrer, url.TopReferrers},
+		{entity.ClickDimensionUserAgent, url.UserAgents},
+		{entity.ClickDimensionCountry, url.Countries},
+	}
+
+	for _, d := range dimensions {
+		for _, c := range d.counts {
+			records = append(records, []string{string(d.name), c.Value, strconv.FormatInt(c.Count, 10)})
+		}
+	}
+
+	for _, a := range url.Aliases {
+		records = append(records, []string{"alias", a.ShortCode, strconv.FormatInt(a.AccessCount, 10)})
+	}
+
+	return records
+}
+
+// summaryResponse represents the structure for a response containing aggregate statistics across all URLs.
+type summaryResponse struct {
+	TotalURLs      int64        `json:"total_urls"`
+	ActiveURLs     int64        `json:"active_urls"`
+	TotalClicks    int64        `json:"total_clicks"`
+	CreatedLastDay int64        `json:"created_last_24h"`
+	TopURLs        []summaryURL `json:"top_urls"`
+}
+
+// summaryURL represents a URL listed in the summary along with its access count.
+type summaryURL struct {
+	ShortCode   string `json:"short_code"`
+	OriginalURL string `json:"original_url"`
+	AccessCount int64  `json:"access_count"`
+}
+
+// toSummaryResponse converts an entity.Summary to a summaryResponse.
+func toSummaryResponse(summary *entity.Summary) summaryResponse {
+	topURLs := make([]summaryURL, 0, len(summary.TopURLs))
+	for _, url := range summary.TopURLs {
+		topURLs = append(topURLs, summaryURL{
+			ShortCode:   url.ShortCode,
+			OriginalURL: url.OriginalURL,
+			AccessCount: url.AccessCount,
+		})
+	}
+
+	return summaryResponse{
+		TotalURLs:      summary.TotalURLs,
+		ActiveURLs:     summary.ActiveURLs,
+		TotalClicks:    summary.TotalClicks,
+		CreatedLastDay: summary.CreatedLastDay,
+		TopURLs:        topURLs,
+	}
+}
+
+// domainListResponse represents the structure for a response containing the statistics of destination hosts.
+type domainListResponse struct {
+	Domains []domainStats `json:"domains"`
+}
+
+// domainStats represents the number of URLs pointing at a destination host and their clicks.
+type domainStats struct {
+	Host   string `json:"host"`
+	URLs   int64  `json:"urls"`
+	Clicks int64  `json:"clicks"`
+}
+
+// toDomainListResponse converts a slice of entity.DomainStats to a domainListResponse.
+func toDomainListResponse(stats []entity.DomainStats) domainListResponse {
+	domains := make([]domainStats, 0, len(stats))
+	for _, s := range stats {
+		domains = append(domains, domainStats{Host: s.Host, URLs: s.URLs, Clicks: s.Clicks})
+	}
+
+	return domainListResponse{Domains: domains}
+}
+
+// linkCheckRequest represents the structure for a request to check whether the original URLs of up to
+// 1000 short codes are reachable. The original URLs of all URLs are checked if no short codes are given.
+type linkCheckRequest struct {
+	ShortCodes []string `json:"short_codes" validate:"max=1000,dive,required,max=50,shortcode"`
+}
+
+// linkCheckResponse represents the structure for a report of a link check. The unknown requested short codes
+// are listed as missing. The links that weren't checked in time are counted as skipped rather than checked.
+type linkCheckResponse struct {
+	Checked int             `json:"checked"`
+	Dead    int             `json:"dead"`
+	Skipped int             `json:"skipped"`
+	Links   []linkCheckItem `json:"links"`
+	Missing []string        `json:"missing"`
+}
+
+// linkCheckItem represents the result of checking whether the original URL of a short code is reachable.
+type linkCheckItem struct {
+	ShortCode   string `json:"short_code"`
+	OriginalURL string `json:"original_url"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Dead        bool   `json:"dead"`
+	Skipped     bool   `json:"skipped,omitempty"`
+}
+
+// toLinkCheckResponse converts the results of a link check of the requested short codes to a linkCheckResponse.
+func toLinkCheckResponse(checks []entity.LinkCheck, shortCodes []string) linkCheckResponse {
+	resp := linkCheckResponse{
+		Links:   make([]linkCheckItem, 0, len(checks)),
+		Missing: make([]string, 0),
+	}
+
+	checked := make(map[string]struct{}, len(checks))
+
+	for _, check := range checks {
+		checked[check.ShortCode] = struct{}{}
+
+		switch {
+		case check.Skipped:
+			resp.Skipped++
+		case check.Dead:
+			resp.Checked++
+			resp.Dead++
+		default:
+			resp.Checked++
+		}
+
+		resp.Links = append(resp.Links, linkCheckItem{
+			ShortCode:   check.ShortCode,
+			OriginalURL: check.OriginalURL,
+			StatusCode:  check.StatusCode,
+			Error:       check.Error,
+			Dead:        check.Dead,
+			Skipped:     check.Skipped,
+		})
+	}
+
+	// Short codes requested more than once are only listed once.
+	for _, code := range shortCodes {
+		if _, ok := checked[code]; ok {
+			continue
+		}
+		checked[code] = struct{}{}
+
+		resp.Missing = append(resp.Missing, code)
+	}
+
+	return resp
+}
+
+// bannerResponse represents the structure for a response describing the service on the root path.
+type bannerResponse struct {
+	Service string `json:"service"`
+	Version string `json:"version"`
+	DocsURL string `json:"docs_url,omitempty"`
+}
+
+// configResponse represents the structure for a response describing the settings of the service clients
+// can adapt to, e.g. the number of short codes they may look up at once. It must never carry secrets.
+type configResponse struct {
+	BaseURL         string          `json:"base_url"`
+	ShortCodeLength int             `json:"short_code_length"`
+	MaxBatchSize    int             `json:"max_batch_size"`
+	Features        map[string]bool `json:"features"`
+}
+
+// readOnlyRequest represents the structure for a request to toggle the read-only mode.
+type readOnlyRequest struct {
+	ReadOnly *bool `json:"read_only" validate:"required"`
+}
+
+// readOnlyStateResponse represents the structure for a response containing the state of the read-only mode.
+type readOnlyStateResponse struct {
+	ReadOnly bool `json:"read_only"`
+}
+
 // validationError represents an individual validation error.
 type validationError struct {
 	Field   string `json:"field"`
@@ -71,9 +704,17 @@ type validationError struct {
 
 // errorResponse represents a structured error response.
 type errorResponse struct {
-	Status  string            `json:"status"`
-	Message string            `json:"message"`
-	Errors  []validationError `json:"errors,omitempty"`
+	Status    string            `json:"status"`
+	Message   string            `json:"message"`
+	Errors    []validationError `json:"errors,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+}
+
+// withRequestID returns a copy of the error response with the ID of the request set,
+// so that the error reported by a client can be traced to the logs.
+func withRequestID(r *http.Request, resp errorResponse) errorResponse {
+	resp.RequestID = middleware.GetReqID(r.Context())
+	return resp
 }
 
 // Predefined error responses for common scenarios.
@@ -88,17 +729,139 @@ var (
 		Message: "invalid request body",
 	}
 
+	emptyUpdateResponse = errorResponse{
+		Status:  statusError,
+		Message: "no fields to update",
+	}
+
+	invalidQueryParamsResponse = errorResponse{
+		Status:  statusError,
+		Message: "invalid query parameters",
+	}
+
+	notFoundResponse = errorResponse{
+		Status:  statusError,
+		Message: "not found",
+	}
+
+	methodNotAllowedResponse = errorResponse{
+		Status:  statusError,
+		Message: "method not allowed",
+	}
+
 	urlNotFoundResponse = errorResponse{
 		Status:  statusError,
 		Message: "url not found",
 	}
 
+	urlGoneResponse = errorResponse{
+		Status:  statusError,
+		Message: "url is no longer available",
+	}
+
+	shortCodeExistsResponse = errorResponse{
+		Status:  statusError,
+		Message: "short code exists",
+	}
+
 	serverErrorResponse = errorResponse{
 		Status:  statusError,
 		Message: "server error occurred",
 	}
+
+	databaseUnavailableResponse = errorResponse{
+		Status:  statusError,
+		Message: "service temporarily unavailable",
+	}
+
+	unauthorizedResponse = errorResponse{
+		Status:  statusError,
+		Message: "unauthorized",
+	}
+
+	readOnlyResponse = errorResponse{
+		Status:  statusError,
+		Message: "service is in read-only mode, try again later",
+	}
+
+	invalidShortCodeResponse = errorResponse{
+		Status:  statusError,
+		Message: "invalid short code",
+	}
+
+	shortCodeTooShortResponse = errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "short_code", Message: "short code is too short"}},
+	}
+
+	domainNotAllowedResponse = errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "original_url", Message: "domain is not allowed"}},
+	}
+
+	selfReferentialURLResponse = errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "original_url", Message: "must not point at this service"}},
+	}
+
+	notReadyResponse = errorResponse{
+		Status:  statusError,
+		Message: "service not ready",
+	}
+
+	creationCooldownResponse = errorResponse{
+		Status:  statusError,
+		Message: "too many urls shortened from this ip address, try again later",
+	}
+
+	linkCheckInProgressResponse = errorResponse{
+		Status:  statusError,
+		Message: "link check in progress, try again later",
+	}
+
+	unknownTierResponse = errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "tier", Message: "tier is unknown"}},
+	}
+
+	encodingNotAllowedResponse = errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "encoding", Message: "encoding is not allowed"}},
+	}
+
+	serverBusyResponse = errorResponse{
+		Status:  statusError,
+		Message: "server is busy, try again later",
+	}
+
+	requestTimeoutResponse = errorResponse{
+		Status:  statusError,
+		Message: "request timeout",
+	}
+
+	requestCanceledResponse = errorResponse{
+		Status:  statusError,
+		Message: "request canceled",
+	}
 )
 
+// batchTooLargeResponse returns the error response to a batch request with more than max short codes.
+func batchTooLargeResponse(max int) errorResponse {
+	return errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors: []validationError{{
+			Field:   "short_codes",
+			Message: fmt.Sprintf("at most %d short codes are allowed", max),
+		}},
+	}
+}
+
 // messageForTag returns a user-friendly message based on the validation tag.
 func messageForTag(tag string) string {
 	switch tag {
@@ -106,11 +869,34 @@ func messageForTag(tag string) string {
 		return "this field is required"
 	case "url":
 		return "invalid url"
+	case "httpurl":
+		return "Only http and https URLs are allowed."
+	case "max":
+		return "value is too long"
+	case "min":
+		return "value is too small"
+	case "shortcode":
+		return "only letters, digits, '_' and '-' are allowed"
 	default:
 		return "invalid value"
 	}
 }
 
+// validateHTTPURL reports whether the field is a URL with an http or https scheme and a non-empty host.
+func validateHTTPURL(fl validator.FieldLevel) bool {
+	u, err := url.Parse(fl.Field().String())
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// validateShortCode reports whether the field contains only the characters allowed in short codes.
+func validateShortCode(fl validator.FieldLevel) bool {
+	return shortCodeRegexp.MatchString(fl.Field().String())
+}
+
 // getValidationErrors processes validation errors and returns a list of validationError.
 func getValidationErrors(err error) []validationError {
 	var validationErrs []validationError
@@ -136,3 +922,60 @@ func validationErrorResponse(err error) errorResponse {
 		Errors:  getValidationErrors(err),
 	}
 }
+
+// unknownFieldResponse creates the error response for a fields query parameter listing a field
+// that the response doesn't have.
+func unknownFieldResponse(err error) errorResponse {
+	return errorResponse{
+		Status:  statusError,
+		Message: "validation error",
+		Errors:  []validationError{{Field: "fields", Message: err.Error()}},
+	}
+}
+
+// decodeErrorResponse creates the error response for a request body that couldn't be decoded.
+// Malformed JSON is reported with the offset at which it was detected, and values of the wrong type
+// with the field they were set for and the JSON type it expects, without exposing Go types.
+func decodeErrorResponse(err error) errorResponse {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return emptyRequestBodyResponse
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		resp := invalidRequestBodyResponse
+		resp.Message += ": unexpected end of JSON input"
+		return resp
+	case errors.As(err, &syntaxErr):
+		resp := invalidRequestBodyResponse
+		resp.Message += fmt.Sprintf(": malformed JSON at offset %d", syntaxErr.Offset)
+		return resp
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		resp := invalidRequestBodyResponse
+		resp.Errors = []validationError{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type)}}
+		return resp
+	default:
+		return invalidRequestBodyResponse
+	}
+}
+
+// jsonTypeName returns the name of the JSON type that a value of the Go type is decoded from, with an article.
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonTypeName(t.Elem())
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
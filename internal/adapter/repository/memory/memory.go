@@ -0,0 +1,966 @@
+// Package memory implements the persistence layer for URL entities in memory. It defines the URLRepository
+// struct, which behaves like the PostgreSQL repository without needing a database, so that the service can
+// be evaluated without any dependencies and the use case can be exercised in tests. Nothing is persisted
+// across restarts.
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+)
+
+// summaryTopURLsLimit is the number of most accessed URLs included in the summary.
+const summaryTopURLsLimit = 10
+
+// shortCodeIDBlockSize is the number of short code IDs reserved by a single call of NextIDBlock.
+const shortCodeIDBlockSize = 100
+
+// txKey is the context key under which the repository holding the lock for the current transaction is stored.
+type txKey struct{}
+
+// clickKey identifies a click counter of a URL.
+type clickKey struct {
+	urlID     int64
+	dimension entity.ClickDimension
+	value     string
+}
+
+// accessEvent is a recorded access to the URL with the ID urlID.
+type accessEvent struct {
+	urlID int64
+	entity.AccessEvent
+}
+
+// urlAlias is an alias of the URL with the ID urlID.
+type urlAlias struct {
+	urlID int64
+	entity.URLAlias
+}
+
+// state holds the data of the repository. The mutations made within a transaction are recorded in an undo log,
+// which is replayed to restore the state if the transaction is rolled back.
+// Reserved short codes are stored as URLs without an original URL. Aliases are keyed by their short code,
+// which shares a namespace with the short codes of URLs.
+type state struct {
+	urls       map[string]*entity.URL
+	aliases    map[string]*urlAlias
+	clickStats map[clickKey]int64
+	events     []accessEvent
+}
+
+// cloneURL returns a copy of the URL, so that stored URLs can't be modified by callers.
+func cloneURL(url *entity.URL) *entity.URL {
+	c := *url
+	c.Tags = slices.Clone(url.Tags)
+	return &c
+}
+
+// isExpired reports whether the URL has an expiration time that has passed at now.
+func isExpired(url *entity.URL, now time.Time) bool {
+	return !url.ExpiresAt.IsZero() && !url.ExpiresAt.After(now)
+}
+
+// isReserved reports whether the URL is a reserved short code without an original URL.
+func isReserved(url *entity.URL) bool {
+	return url.OriginalURL == ""
+}
+
+// URLRepository provides methods to store URLs in memory. It is safe for concurrent use.
+// Like the rows of a database, the IDs of URLs and access events are never reused.
+type URLRepository struct {
+	mu          sync.Mutex
+	state       state
+	lastURLID   int64
+	lastEventID int64
+	lastIDBlock uint64
+
+	// inTx reports whether a transaction is running, in which case the functions reverting its mutations
+	// are appended to undo.
+	inTx bool
+	undo []func()
+}
+
+// NewURLRepository creates a new instance of URLRepository without any URLs.
+func NewURLRepository() *URLRepository {
+	return &URLRepository{
+		state: state{
+			urls:       make(map[string]*entity.URL),
+			aliases:    make(map[string]*urlAlias),
+			clickStats: make(map[clickKey]int64),
+		},
+	}
+}
+
+// lock locks the repository unless ctx carries a transaction of the repository, which already holds the lock,
+// and returns the function unlocking it.
+func (r *URLRepository) lock(ctx context.Context) func() {
+	if ctx.Value(txKey{}) == r {
+		return func() {}
+	}
+
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// WithTx runs fn within a transaction. Repository methods called with the context passed to fn participate
+// in the transaction. The changes made by fn are rolled back if it returns an error. If ctx already carries
+// a transaction, fn joins it. Transactions are serialized, and like database transactions, the context
+// passed to fn must not be used concurrently.
+func (r *URLRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) == r {
+		return fn(ctx)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inTx = true
+	defer func() {
+		r.inTx = false
+		r.undo = nil
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, r)); err != nil {
+		for i := len(r.undo) - 1; i >= 0; i-- {
+			r.undo[i]()
+		}
+		return err
+	}
+
+	return nil
+}
+
+// record appends the function reverting a mutation to the undo log if a transaction is running.
+func (r *URLRepository) record(undo func()) {
+	if r.inTx {
+		r.undo = append(r.undo, undo)
+	}
+}
+
+// recordEntry records the current entry of the key in the map, so that it is restored on rollback.
+func recordEntry[K comparable, V any](r *URLRepository, m map[K]V, key K) {
+	if !r.inTx {
+		return
+	}
+
+	prev, ok := m[key]
+	r.record(func() {
+		if ok {
+			m[key] = prev
+		} else {
+			delete(m, key)
+		}
+	})
+}
+
+// setEntry sets the entry of the key in the map, recording its previous entry.
+func setEntry[K comparable, V any](r *URLRepository, m map[K]V, key K, value V) {
+	recordEntry(r, m, key)
+	m[key] = value
+}
+
+// deleteEntry deletes the entry of the key from the map, recording its previous entry.
+func deleteEntry[K comparable, V any](r *URLRepository, m map[K]V, key K) {
+	recordEntry(r, m, key)
+	delete(m, key)
+}
+
+// recordURL records the fields of the stored URL before it is modified in place, so that they are restored on rollback.
+func (r *URLRepository) recordURL(url *entity.URL) {
+	if !r.inTx {
+		return
+	}
+
+	prev := cloneURL(url)
+	r.record(func() { *url = *prev })
+}
+
+// recordAlias records the fields of the stored alias before it is modified in place, so that they are restored on rollback.
+func (r *URLRepository) recordAlias(alias *urlAlias) {
+	if !r.inTx {
+		return
+	}
+
+	prev := *alias
+	r.record(func() { *alias = prev })
+}
+
+// insert stores a new URL with the provided short code, which must not exist yet.
+func (r *URLRepository) insert(shortCode, originalURL, note string, tags []string, owner string, expiresAt time.Time) *entity.URL {
+	now := time.Now()
+	r.lastURLID++
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	url := &entity.URL{
+		ID:          r.lastURLID,
+		ShortCode:   shortCode,
+		OriginalURL: originalURL,
+		Active:      true,
+		Tags:        slices.Clone(tags),
+		Note:        note,
+		Owner:       owner,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		ExpiresAt:   expiresAt,
+	}
+	setEntry(r, r.state.urls, shortCode, url)
+
+	return cloneURL(url)
+}
+
+// taken reports whether the short code is stored for a URL, a reservation or an alias.
+func (r *URLRepository) taken(shortCode string) bool {
+	_, url := r.state.urls[shortCode]
+	_, alias := r.state.aliases[shortCode]
+	return url || alias
+}
+
+// delete removes the URL with the provided short code along with its aliases, click counters and access events.
+func (r *URLRepository) delete(shortCode string) {
+	url := r.state.urls[shortCode]
+	deleteEntry(r, r.state.urls, shortCode)
+
+	for alias, a := range r.state.aliases {
+		if a.urlID == url.ID {
+			deleteEntry(r, r.state.aliases, alias)
+		}
+	}
+	r.deleteStats(url.ID)
+}
+
+// deleteStats deletes the click statistics and access events of the URL with the provided ID.
+func (r *URLRepository) deleteStats(urlID int64) {
+	for key := range r.state.clickStats {
+		if key.urlID == urlID {
+			deleteEntry(r, r.state.clickStats, key)
+		}
+	}
+
+	matches := func(e accessEvent) bool {
+		return e.urlID == urlID
+	}
+	if !slices.ContainsFunc(r.state.events, matches) {
+		return
+	}
+
+	// The events are deleted from a copy within a transaction, so that the recorded slice stays intact.
+	events := r.state.events
+	if r.inTx {
+		prev := events
+		r.record(func() { r.state.events = prev })
+		events = slices.Clone(events)
+	}
+	r.state.events = slices.DeleteFunc(events, matches)
+}
+
+// Save stores a new URL with the provided short code, original URL, note, tags and owner.
+// If a short code already exists, it returns an entity.ErrShortCodeExists error.
+func (r *URLRepository) Save(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.Save"
+
+	defer r.lock(ctx)()
+
+	if r.taken(shortCode) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	return r.insert(shortCode, originalURL, note, tags, owner, time.Time{}), nil
+}
+
+// Upsert saves a new URL with the provided short code, or updates the original URL of the URL associated with it
+// like Update if the short code already exists, and reports whether the URL was created. The note, tags and owner
+// are only set for new URLs. Existing URLs are only updated if they have the same owner, and otherwise it returns
+// an entity.ErrShortCodeExists error. If the short code exists but has expired, it returns an entity.ErrShortCodeExists
+// error until the expired URL is deleted.
+func (r *URLRepository) Upsert(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, bool, error) {
+	const op = "adapter.repository.memory.URLRepository.Upsert"
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.state.aliases[shortCode]; ok {
+		return nil, false, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	url, ok := r.state.urls[shortCode]
+	if !ok {
+		return r.insert(shortCode, originalURL, note, tags, owner, time.Time{}), true, nil
+	}
+
+	if isExpired(url, time.Now()) || url.Owner != owner {
+		return nil, false, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	return r.update(url, originalURL), false, nil
+}
+
+// Reserve stores a short code without an original URL for the owner, which expires at the provided time
+// unless an original URL is set for it before. If a short code already exists, it returns an entity.ErrShortCodeExists error.
+func (r *URLRepository) Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.Reserve"
+
+	defer r.lock(ctx)()
+
+	if r.taken(shortCode) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	return r.insert(shortCode, "", "", nil, owner, expiresAt), nil
+}
+
+// DeleteExpired deletes the URLs and reserved short codes that expired before the provided time
+// and returns the number of deleted URLs.
+func (r *URLRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	defer r.lock(ctx)()
+
+	var total int64
+
+	for shortCode, url := range r.state.urls {
+		if !url.ExpiresAt.IsZero() && url.ExpiresAt.Before(now) {
+			r.delete(shortCode)
+			total++
+		}
+	}
+
+	return total, nil
+}
+
+// RetrieveByShortCode retrieves the URL associated with the provided short code.
+// Reserved short codes are not retrieved. If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.RetrieveByShortCode"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isReserved(url) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	return cloneURL(url), nil
+}
+
+// RetrieveManyByShortCodes retrieves the URLs associated with the provided short codes, keyed by short code.
+// Reserved and unknown short codes are missing from the result.
+func (r *URLRepository) RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	defer r.lock(ctx)()
+
+	urls := make(map[string]*entity.URL, len(shortCodes))
+	for _, shortCode := range shortCodes {
+		if url, ok := r.state.urls[shortCode]; ok && !isReserved(url) {
+			urls[shortCode] = cloneURL(url)
+		}
+	}
+
+	return urls, nil
+}
+
+// Exists reports whether the short code is stored, either for a URL, a reservation or an alias.
+func (r *URLRepository) Exists(ctx context.Context, shortCode string) (bool, error) {
+	defer r.lock(ctx)()
+
+	return r.taken(shortCode), nil
+}
+
+// List retrieves up to limit URLs in the given order, skipping the first offset ones. Unknown orders list URLs
+// by ID, oldest first. If query is not empty, only the URLs whose original URL, short code or note contain it,
+// ignoring case, are retrieved. If tags are given, only the URLs having all of them are retrieved.
+// Pending reservations are not retrieved.
+func (r *URLRepository) List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error) {
+	defer r.lock(ctx)()
+
+	matched := r.matching(query, tags)
+
+	slices.SortFunc(matched, func(a, b *entity.URL) int {
+		switch sort {
+		case entity.URLSortNewest:
+			return cmp.Compare(b.ID, a.ID)
+		case entity.URLSortShortCode:
+			return cmp.Or(cmp.Compare(a.ShortCode, b.ShortCode), cmp.Compare(a.ID, b.ID))
+		default:
+			return cmp.Compare(a.ID, b.ID)
+		}
+	})
+
+	urls := make([]entity.URL, 0, min(limit, max(len(matched)-offset, 0)))
+	for i := offset; i < len(matched) && len(urls) < limit; i++ {
+		urls = append(urls, *cloneURL(matched[i]))
+	}
+
+	return urls, nil
+}
+
+// ListBefore retrieves up to limit URLs ordered by ID, newest first. If before is positive,
+// only the URLs with a lower ID are retrieved. Filtering by query and tags works like in List.
+func (r *URLRepository) ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	defer r.lock(ctx)()
+
+	matched := r.matching(query, tags)
+
+	slices.SortFunc(matched, func(a, b *entity.URL) int {
+		return cmp.Compare(b.ID, a.ID)
+	})
+
+	urls := make([]entity.URL, 0, min(limit, len(matched)))
+	for _, url := range matched {
+		if len(urls) == limit {
+			break
+		}
+
+		if before <= 0 || url.ID < before {
+			urls = append(urls, *cloneURL(url))
+		}
+	}
+
+	return urls, nil
+}
+
+// matching returns the URLs whose original URL, short code or note contain the query, ignoring case,
+// and that have all of the tags. Pending reservations are left out.
+func (r *URLRepository) matching(query string, tags []string) []*entity.URL {
+	query = strings.ToLower(query)
+
+	var matched []*entity.URL
+
+	for _, url := range r.state.urls {
+		if isReserved(url) {
+			continue
+		}
+
+		if !strings.Contains(strings.ToLower(url.OriginalURL), query) &&
+			!strings.Contains(strings.ToLower(url.ShortCode), query) &&
+			!strings.Contains(strings.ToLower(url.Note), query) {
+			continue
+		}
+
+		if !hasAllTags(url, tags) {
+			continue
+		}
+
+		matched = append(matched, url)
+	}
+
+	return matched
+}
+
+// hasAllTags reports whether the URL has all of the given tags.
+func hasAllTags(url *entity.URL, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(url.Tags, tag) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RetrieveAndUpdateStats retrieves the URL associated with the provided short code and increments its access count.
+// Reserved short codes and disabled URLs are not retrieved.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.RetrieveAndUpdateStats"
+
+	defer r.lock(ctx)()
+
+	now := time.Now()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isReserved(url) || !url.Active || isExpired(url, now) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.recordURL(url)
+
+	// The access count saturates at the maximum int64 value instead of overflowing.
+	url.AccessCount = min(url.AccessCount, math.MaxInt64-1) + 1
+	url.UpdatedAt = now
+
+	return cloneURL(url), nil
+}
+
+// IncrementClickStats increments the number of clicks with the given value of the click dimension
+// for the URL with the provided ID, creating the counter if it doesn't exist yet.
+func (r *URLRepository) IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error {
+	defer r.lock(ctx)()
+
+	key := clickKey{urlID: urlID, dimension: dimension, value: value}
+	setEntry(r, r.state.clickStats, key, min(r.state.clickStats[key], math.MaxInt64-1)+1)
+
+	return nil
+}
+
+// RetrieveClickStats retrieves up to limit click counters of the given dimension for the URL
+// with the provided ID, ordered by the number of clicks in descending order.
+func (r *URLRepository) RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error) {
+	defer r.lock(ctx)()
+
+	stats := make([]entity.StatCount, 0)
+	for key, count := range r.state.clickStats {
+		if key.urlID == urlID && key.dimension == dimension {
+			stats = append(stats, entity.StatCount{Value: key.value, Count: count})
+		}
+	}
+
+	slices.SortFunc(stats, func(a, b entity.StatCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Value, b.Value))
+	})
+
+	return stats[:min(limit, len(stats))], nil
+}
+
+// SaveAccessEvent records an access to the URL with the provided ID from the given IP address and referrer.
+func (r *URLRepository) SaveAccessEvent(ctx context.Context, urlID int64, ip, referrer string) error {
+	defer r.lock(ctx)()
+
+	if r.inTx {
+		n := len(r.state.events)
+		r.record(func() { r.state.events = r.state.events[:n] })
+	}
+
+	r.lastEventID++
+	r.state.events = append(r.state.events, accessEvent{
+		urlID: urlID,
+		AccessEvent: entity.AccessEvent{
+			ID:         r.lastEventID,
+			IP:         ip,
+			Referrer:   referrer,
+			AccessedAt: time.Now(),
+		},
+	})
+
+	return nil
+}
+
+// ListAccessEvents retrieves up to limit recorded accesses to the URL with the provided ID, most recent first.
+// If before is positive, only the events with a lower ID are retrieved, so that the ID of the last event
+// of a page can be used to retrieve the next one.
+func (r *URLRepository) ListAccessEvents(ctx context.Context, urlID, before int64, limit int) ([]entity.AccessEvent, error) {
+	defer r.lock(ctx)()
+
+	events := make([]entity.AccessEvent, 0)
+
+	// Events are stored in the order of their IDs.
+	for i := len(r.state.events) - 1; i >= 0 && len(events) < limit; i-- {
+		e := r.state.events[i]
+		if e.urlID == urlID && (before <= 0 || e.ID < before) {
+			events = append(events, e.AccessEvent)
+		}
+	}
+
+	return events, nil
+}
+
+// CountAccessEvents counts the access events recorded for each of the URLs with the provided IDs, keyed by URL ID.
+// URLs without any events are missing from the result.
+func (r *URLRepository) CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error) {
+	defer r.lock(ctx)()
+
+	counts := make(map[int64]int64)
+	for _, e := range r.state.events {
+		if slices.Contains(urlIDs, e.urlID) {
+			counts[e.urlID]++
+		}
+	}
+
+	return counts, nil
+}
+
+// Update modifies the original URL associated with the provided short code. If the short code is reserved,
+// the reservation is committed and no longer expires. If the short code is not found or its reservation
+// has expired, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) Update(ctx context.Context, shortCode, originalURL string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.Update"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isExpired(url, time.Now()) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	return r.update(url, originalURL), nil
+}
+
+// update sets the original URL of the stored URL, committing it if it is a reservation.
+func (r *URLRepository) update(url *entity.URL, originalURL string) *entity.URL {
+	r.recordURL(url)
+
+	if isReserved(url) {
+		url.ExpiresAt = time.Time{}
+	}
+
+	url.OriginalURL = originalURL
+	url.UpdatedAt = time.Now()
+
+	return cloneURL(url)
+}
+
+// Rename changes the short code of the URL associated with the provided short code.
+// If the new short code already exists, it returns an entity.ErrShortCodeExists error.
+// If the old short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) Rename(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.Rename"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[oldShortCode]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	if r.taken(newShortCode) && newShortCode != oldShortCode {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	r.recordURL(url)
+	deleteEntry(r, r.state.urls, oldShortCode)
+	url.ShortCode = newShortCode
+	url.UpdatedAt = time.Now()
+	setEntry(r, r.state.urls, newShortCode, url)
+
+	for _, alias := range r.state.aliases {
+		if alias.urlID == url.ID {
+			r.recordAlias(alias)
+			alias.CanonicalShortCode = newShortCode
+		}
+	}
+
+	return cloneURL(url), nil
+}
+
+// UpdateFields applies the fields set in the update to the URL associated with the provided short code
+// and returns the updated URL. A zero expiration timestamp removes the expiration. If the short code
+// is not found or is reserved, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	const op = "adapter.repository.memory.URLRepository.UpdateFields"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isReserved(url) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.recordURL(url)
+
+	if update.OriginalURL != nil {
+		url.OriginalURL = *update.OriginalURL
+	}
+	if update.ExpiresAt != nil {
+		url.ExpiresAt = *update.ExpiresAt
+	}
+	if update.Active != nil {
+		url.Active = *update.Active
+	}
+	if update.Tags != nil {
+		url.Tags = slices.Clone(*update.Tags)
+	}
+	if update.Note != nil {
+		url.Note = *update.Note
+	}
+
+	url.UpdatedAt = time.Now()
+
+	return cloneURL(url), nil
+}
+
+// SetCreator records the client that created the URL associated with the provided short code.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error {
+	const op = "adapter.repository.memory.URLRepository.SetCreator"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.recordURL(url)
+	url.Creator = creator
+
+	return nil
+}
+
+// ResetStats resets the access counts of the URL associated with the provided short code and of its aliases
+// to zero and deletes its click statistics and access events. Reserved short codes are not reset.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) ResetStats(ctx context.Context, shortCode string) error {
+	const op = "adapter.repository.memory.URLRepository.ResetStats"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isReserved(url) {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.recordURL(url)
+	url.AccessCount = 0
+	r.deleteStats(url.ID)
+
+	for _, alias := range r.state.aliases {
+		if alias.urlID == url.ID {
+			r.recordAlias(alias)
+			alias.AccessCount = 0
+		}
+	}
+
+	return nil
+}
+
+// SaveAlias stores an alias for the URL associated with the provided short code. Reserved short codes
+// can't have aliases. If the alias already exists as a short code of a URL or an alias, it returns
+// an entity.ErrShortCodeExists error. If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) SaveAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error) {
+	const op = "adapter.repository.memory.URLRepository.SaveAlias"
+
+	defer r.lock(ctx)()
+
+	url, ok := r.state.urls[shortCode]
+	if !ok || isReserved(url) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	if r.taken(alias) {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+	}
+
+	a := &urlAlias{
+		urlID: url.ID,
+		URLAlias: entity.URLAlias{
+			ShortCode:          alias,
+			CanonicalShortCode: url.ShortCode,
+			CreatedAt:          time.Now(),
+		},
+	}
+	setEntry(r, r.state.aliases, alias, a)
+
+	res := a.URLAlias
+	return &res, nil
+}
+
+// RetrieveAlias retrieves the alias with the provided short code along with the short code of its URL.
+// If the alias is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error) {
+	const op = "adapter.repository.memory.URLRepository.RetrieveAlias"
+
+	defer r.lock(ctx)()
+
+	a, ok := r.state.aliases[alias]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	res := a.URLAlias
+	return &res, nil
+}
+
+// IncrementAliasStats increments the access count of the alias with the provided short code.
+// If the alias is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) IncrementAliasStats(ctx context.Context, alias string) error {
+	const op = "adapter.repository.memory.URLRepository.IncrementAliasStats"
+
+	defer r.lock(ctx)()
+
+	a, ok := r.state.aliases[alias]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.recordAlias(a)
+
+	// The access count saturates at the maximum int64 value instead of overflowing.
+	a.AccessCount = min(a.AccessCount, math.MaxInt64-1) + 1
+
+	return nil
+}
+
+// ListAliases retrieves the aliases of the URL with the provided ID, oldest first.
+func (r *URLRepository) ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error) {
+	defer r.lock(ctx)()
+
+	aliases := make([]entity.URLAlias, 0)
+	for _, a := range r.state.aliases {
+		if a.urlID == urlID {
+			aliases = append(aliases, a.URLAlias)
+		}
+	}
+
+	slices.SortFunc(aliases, func(a, b entity.URLAlias) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ShortCode, b.ShortCode))
+	})
+
+	return aliases, nil
+}
+
+// Remove deletes the URL associated with the provided short code.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) Remove(ctx context.Context, shortCode string) error {
+	const op = "adapter.repository.memory.URLRepository.Remove"
+
+	defer r.lock(ctx)()
+
+	if _, ok := r.state.urls[shortCode]; !ok {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	r.delete(shortCode)
+
+	return nil
+}
+
+// Summary retrieves aggregate statistics across all shortened URLs, including the most accessed ones.
+// Pending reservations are not taken into account.
+func (r *URLRepository) Summary(ctx context.Context) (*entity.Summary, error) {
+	defer r.lock(ctx)()
+
+	return r.summary(func(url *entity.URL) int64 {
+		return url.AccessCount
+	}), nil
+}
+
+// SummaryFromEvents retrieves the same statistics as Summary, but with the number of clicks
+// aggregated from the access events rather than the access counts of the URLs.
+func (r *URLRepository) SummaryFromEvents(ctx context.Context) (*entity.Summary, error) {
+	defer r.lock(ctx)()
+
+	counts := make(map[int64]int64)
+	for _, e := range r.state.events {
+		counts[e.urlID]++
+	}
+
+	return r.summary(func(url *entity.URL) int64 {
+		return counts[url.ID]
+	}), nil
+}
+
+// summary computes the summary with the access count of each URL returned by accessCount.
+func (r *URLRepository) summary(accessCount func(url *entity.URL) int64) *entity.Summary {
+	now := time.Now()
+	summary := &entity.Summary{}
+
+	var urls []entity.URL
+
+	for _, url := range r.state.urls {
+		if isReserved(url) {
+			continue
+		}
+
+		c := cloneURL(url)
+		c.AccessCount = accessCount(url)
+		urls = append(urls, *c)
+
+		summary.TotalURLs++
+		summary.TotalClicks += c.AccessCount
+
+		if url.Active && !isExpired(url, now) {
+			summary.ActiveURLs++
+		}
+
+		if url.CreatedAt.After(now.Add(-24 * time.Hour)) {
+			summary.CreatedLastDay++
+		}
+	}
+
+	slices.SortFunc(urls, func(a, b entity.URL) int {
+		return cmp.Or(cmp.Compare(b.AccessCount, a.AccessCount), cmp.Compare(a.ID, b.ID))
+	})
+
+	summary.TopURLs = append(make([]entity.URL, 0), urls[:min(summaryTopURLsLimit, len(urls))]...)
+
+	return summary
+}
+
+// DomainStats retrieves the number of URLs and clicks per destination host, most clicked first, limited to limit hosts.
+// If domain isn't empty, only the domain and its subdomains are included.
+func (r *URLRepository) DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	defer r.lock(ctx)()
+
+	return r.domainStats(domain, limit, func(url *entity.URL) int64 {
+		return url.AccessCount
+	}), nil
+}
+
+// DomainStatsFromEvents retrieves the same statistics as DomainStats, but with the number of clicks
+// aggregated from the access events rather than the access counts of the URLs.
+func (r *URLRepository) DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	defer r.lock(ctx)()
+
+	counts := make(map[int64]int64)
+	for _, e := range r.state.events {
+		counts[e.urlID]++
+	}
+
+	return r.domainStats(domain, limit, func(url *entity.URL) int64 {
+		return counts[url.ID]
+	}), nil
+}
+
+// domainStats computes the statistics of destination hosts with the access count of each URL returned by accessCount.
+func (r *URLRepository) domainStats(domain string, limit int, accessCount func(url *entity.URL) int64) []entity.DomainStats {
+	byHost := make(map[string]*entity.DomainStats)
+
+	for _, url := range r.state.urls {
+		if isReserved(url) {
+			continue
+		}
+
+		host := hostOf(url.OriginalURL)
+		if host == "" || (domain != "" && host != domain && !strings.HasSuffix(host, "."+domain)) {
+			continue
+		}
+
+		s, ok := byHost[host]
+		if !ok {
+			s = &entity.DomainStats{Host: host}
+			byHost[host] = s
+		}
+
+		s.URLs++
+		s.Clicks += accessCount(url)
+	}
+
+	stats := make([]entity.DomainStats, 0, len(byHost))
+	for _, s := range byHost {
+		stats = append(stats, *s)
+	}
+
+	slices.SortFunc(stats, func(a, b entity.DomainStats) int {
+		return cmp.Or(cmp.Compare(b.Clicks, a.Clicks), cmp.Compare(b.URLs, a.URLs), cmp.Compare(a.Host, b.Host))
+	})
+
+	return stats[:min(limit, len(stats))]
+}
+
+// hostOf returns the lowercase host of the original URL without the port, like the host column of the database.
+func hostOf(originalURL string) string {
+	u, err := neturl.Parse(originalURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.ToLower(u.Hostname())
+}
+
+// NextIDBlock reserves the next block of IDs for sequential short codes. Like a database sequence,
+// the blocks are never handed out again, even if the transaction they were reserved in is rolled back.
+func (r *URLRepository) NextIDBlock(ctx context.Context) (first, size uint64, err error) {
+	defer r.lock(ctx)()
+
+	first = r.lastIDBlock*shortCodeIDBlockSize + 1
+	r.lastIDBlock++
+
+	return first, shortCodeIDBlockSize, nil
+}
@@ -0,0 +1,801 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+)
+
+type URLRepositoryTestSuite struct {
+	suite.Suite
+	errUnknown error
+	repo       *URLRepository
+}
+
+func (suite *URLRepositoryTestSuite) SetupSuite() {
+	suite.errUnknown = errors.New("unknown error")
+}
+
+func (suite *URLRepositoryTestSuite) SetupSubTest() {
+	suite.repo = NewURLRepository()
+}
+
+// save saves a URL with the given short code and original URL, failing the test on error.
+func (suite *URLRepositoryTestSuite) save(shortCode, originalURL string) *entity.URL {
+	url, err := suite.repo.Save(context.Background(), shortCode, originalURL, "", nil, "")
+	suite.Require().NoError(err)
+	return url
+}
+
+func (suite *URLRepositoryTestSuite) TestWithTx() {
+	suite.Run("rollback", func() {
+		suite.save("abc123", "https://example.com")
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			if _, err := suite.repo.Save(ctx, "def456", "https://example.org", "", nil, ""); err != nil {
+				return err
+			}
+
+			if _, err := suite.repo.RetrieveAndUpdateStats(ctx, "abc123"); err != nil {
+				return err
+			}
+
+			return suite.errUnknown
+		})
+
+		suite.ErrorIs(err, suite.errUnknown)
+
+		exists, err := suite.repo.Exists(context.Background(), "def456")
+		suite.NoError(err)
+		suite.False(exists)
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		suite.NoError(err)
+		suite.Zero(url.AccessCount)
+	})
+
+	suite.Run("rollback of modified and deleted data", func() {
+		ctx := context.Background()
+		url := suite.save("abc123", "https://example.com")
+		_, err := suite.repo.SaveAlias(ctx, "abc123", "xyz789")
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.repo.IncrementClickStats(ctx, url.ID, entity.ClickDimensionReferrer, "example.org"))
+		suite.Require().NoError(suite.repo.SaveAccessEvent(ctx, url.ID, "127.0.0.1", "example.org"))
+
+		err = suite.repo.WithTx(ctx, func(ctx context.Context) error {
+			if _, err := suite.repo.RetrieveAndUpdateStats(ctx, "abc123"); err != nil {
+				return err
+			}
+			if err := suite.repo.IncrementAliasStats(ctx, "xyz789"); err != nil {
+				return err
+			}
+			if err := suite.repo.IncrementClickStats(ctx, url.ID, entity.ClickDimensionReferrer, "example.org"); err != nil {
+				return err
+			}
+			if err := suite.repo.SaveAccessEvent(ctx, url.ID, "127.0.0.1", "example.org"); err != nil {
+				return err
+			}
+			if _, err := suite.repo.Rename(ctx, "abc123", "def456"); err != nil {
+				return err
+			}
+			if err := suite.repo.Remove(ctx, "def456"); err != nil {
+				return err
+			}
+
+			return suite.errUnknown
+		})
+
+		suite.ErrorIs(err, suite.errUnknown)
+
+		got, err := suite.repo.RetrieveByShortCode(ctx, "abc123")
+		suite.NoError(err)
+		suite.Equal(url, got)
+
+		alias, err := suite.repo.RetrieveAlias(ctx, "xyz789")
+		suite.NoError(err)
+		suite.Equal("abc123", alias.CanonicalShortCode)
+		suite.Zero(alias.AccessCount)
+
+		stats, err := suite.repo.RetrieveClickStats(ctx, url.ID, entity.ClickDimensionReferrer, 10)
+		suite.NoError(err)
+		suite.Equal([]entity.StatCount{{Value: "example.org", Count: 1}}, stats)
+
+		events, err := suite.repo.ListAccessEvents(ctx, url.ID, 0, 10)
+		suite.NoError(err)
+		suite.Len(events, 1)
+	})
+
+	suite.Run("commit", func() {
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			// Nested transactions join the outer one instead of deadlocking.
+			return suite.repo.WithTx(ctx, func(ctx context.Context) error {
+				_, err := suite.repo.Save(ctx, "abc123", "https://example.com", "", nil, "")
+				return err
+			})
+		})
+
+		suite.NoError(err)
+
+		exists, err := suite.repo.Exists(context.Background(), "abc123")
+		suite.NoError(err)
+		suite.True(exists)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSave() {
+	suite.Run("short code exists", func() {
+		suite.save("abc123", "https://example.com")
+
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.org", "", nil, "")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(url)
+	})
+
+	suite.Run("success", func() {
+		first := suite.save("abc123", "https://example.com")
+
+		url, err := suite.repo.Save(context.Background(), "def456", "https://example.org", "spring", []string{"campaign"}, "")
+
+		suite.NoError(err)
+		suite.Greater(url.ID, first.ID)
+		suite.Equal("def456", url.ShortCode)
+		suite.Equal("https://example.org", url.OriginalURL)
+		suite.Equal("spring", url.Note)
+		suite.Equal([]string{"campaign"}, url.Tags)
+		suite.True(url.Active)
+		suite.False(url.CreatedAt.IsZero())
+	})
+
+	suite.Run("returned url is a copy", func() {
+		url := suite.save("abc123", "https://example.com")
+		url.OriginalURL = "https://example.org"
+
+		stored, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal("https://example.com", stored.OriginalURL)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveByShortCode() {
+	suite.Run("url not found", func() {
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("reserved short code", func() {
+		_, err := suite.repo.Reserve(context.Background(), "abc123", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveAndUpdateStats() {
+	suite.Run("url not found", func() {
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("deactivated url", func() {
+		suite.save("abc123", "https://example.com")
+		_, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Active: new(bool)})
+		suite.Require().NoError(err)
+
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("concurrent accesses", func() {
+		suite.save("abc123", "https://example.com")
+
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+				suite.NoError(err)
+			}()
+		}
+		wg.Wait()
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal(int64(50), url.AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestReserve() {
+	suite.Run("short code exists", func() {
+		suite.save("abc123", "https://example.com")
+
+		url, err := suite.repo.Reserve(context.Background(), "abc123", time.Now().Add(time.Minute), "")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(url)
+	})
+
+	suite.Run("committed by update", func() {
+		_, err := suite.repo.Reserve(context.Background(), "abc123", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://example.com")
+
+		suite.NoError(err)
+		suite.Equal("https://example.com", url.OriginalURL)
+		suite.True(url.ExpiresAt.IsZero())
+	})
+
+	suite.Run("expired reservation", func() {
+		_, err := suite.repo.Reserve(context.Background(), "abc123", time.Now().Add(-time.Minute), "")
+		suite.Require().NoError(err)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://example.com")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+
+		_, _, err = suite.repo.Upsert(context.Background(), "abc123", "https://example.com", "", nil, "")
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+
+		deleted, err := suite.repo.DeleteExpired(context.Background(), time.Now())
+		suite.NoError(err)
+		suite.Equal(int64(1), deleted)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestUpsert() {
+	suite.Run("created", func() {
+		url, created, err := suite.repo.Upsert(context.Background(), "abc123", "https://example.com", "spring", nil, "")
+
+		suite.NoError(err)
+		suite.True(created)
+		suite.Equal("spring", url.Note)
+	})
+
+	suite.Run("updated", func() {
+		suite.save("abc123", "https://example.com")
+
+		url, created, err := suite.repo.Upsert(context.Background(), "abc123", "https://example.org", "spring", nil, "")
+
+		suite.NoError(err)
+		suite.False(created)
+		suite.Equal("https://example.org", url.OriginalURL)
+		suite.Empty(url.Note)
+	})
+
+	suite.Run("another owner", func() {
+		_, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "user-1")
+		suite.Require().NoError(err)
+
+		url, created, err := suite.repo.Upsert(context.Background(), "abc123", "https://example.org", "", nil, "user-2")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.False(created)
+		suite.Nil(url)
+
+		url, err = suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal("https://example.com", url.OriginalURL)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestList() {
+	suite.Run("filtered and paginated", func() {
+		suite.save("abc123", "https://example.com/a")
+		suite.save("def456", "https://example.org")
+		suite.save("ghi789", "https://EXAMPLE.com/b")
+		_, err := suite.repo.Save(context.Background(), "jkl012", "https://example.com/c", "", []string{"spring"}, "")
+		suite.Require().NoError(err)
+		_, err = suite.repo.Reserve(context.Background(), "mno345", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		urls, err := suite.repo.List(context.Background(), "example.com", nil, entity.URLSortOldest, 2, 1)
+
+		suite.NoError(err)
+		suite.Len(urls, 2)
+		suite.Equal("ghi789", urls[0].ShortCode)
+		suite.Equal("jkl012", urls[1].ShortCode)
+
+		urls, err = suite.repo.List(context.Background(), "", []string{"spring"}, entity.URLSortOldest, 10, 0)
+
+		suite.NoError(err)
+		suite.Len(urls, 1)
+		suite.Equal("jkl012", urls[0].ShortCode)
+
+		urls, err = suite.repo.List(context.Background(), "", nil, entity.URLSortOldest, 10, 10)
+
+		suite.NoError(err)
+		suite.Empty(urls)
+	})
+
+	suite.Run("sorted", func() {
+		suite.save("ghi789", "https://example.com/a")
+		suite.save("abc123", "https://example.com/b")
+		suite.save("def456", "https://example.com/c")
+
+		tests := []struct {
+			sort entity.URLSort
+			want []string
+		}{
+			{sort: entity.URLSortOldest, want: []string{"ghi789", "abc123", "def456"}},
+			{sort: entity.URLSortNewest, want: []string{"def456", "abc123", "ghi789"}},
+			{sort: entity.URLSortShortCode, want: []string{"abc123", "def456", "ghi789"}},
+			{sort: "unknown", want: []string{"ghi789", "abc123", "def456"}},
+		}
+
+		for _, tt := range tests {
+			urls, err := suite.repo.List(context.Background(), "", nil, tt.sort, 10, 0)
+
+			suite.NoError(err)
+
+			shortCodes := make([]string, 0, len(urls))
+			for _, url := range urls {
+				shortCodes = append(shortCodes, url.ShortCode)
+			}
+
+			suite.Equal(tt.want, shortCodes, tt.sort)
+		}
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestListBefore() {
+	suite.Run("stable iteration", func() {
+		for i := range 5 {
+			suite.save(fmt.Sprintf("code%d", i), "https://example.com")
+		}
+
+		var (
+			seen   []string
+			cursor int64
+		)
+
+		for {
+			urls, err := suite.repo.ListBefore(context.Background(), "", nil, cursor, 2)
+			suite.Require().NoError(err)
+
+			for _, url := range urls {
+				seen = append(seen, url.ShortCode)
+			}
+
+			if len(urls) < 2 {
+				break
+			}
+			cursor = urls[len(urls)-1].ID
+
+			// URLs created while iterating neither shift the following pages nor show up in them.
+			suite.save(fmt.Sprintf("new%d", cursor), "https://example.com")
+		}
+
+		suite.Equal([]string{"code4", "code3", "code2", "code1", "code0"}, seen)
+	})
+
+	suite.Run("filtered", func() {
+		suite.save("abc123", "https://example.com")
+		_, err := suite.repo.Save(context.Background(), "def456", "https://example.com", "", []string{"spring"}, "")
+		suite.Require().NoError(err)
+		suite.save("ghi789", "https://example.org")
+
+		urls, err := suite.repo.ListBefore(context.Background(), "example.com", []string{"spring"}, 0, 10)
+
+		suite.NoError(err)
+		suite.Len(urls, 1)
+		suite.Equal("def456", urls[0].ShortCode)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRename() {
+	suite.Run("url not found", func() {
+		url, err := suite.repo.Rename(context.Background(), "abc123", "def456")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("short code exists", func() {
+		suite.save("abc123", "https://example.com")
+		suite.save("def456", "https://example.org")
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "def456")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(url)
+	})
+
+	suite.Run("success", func() {
+		saved := suite.save("abc123", "https://example.com")
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "def456")
+
+		suite.NoError(err)
+		suite.Equal(saved.ID, url.ID)
+		suite.Equal("def456", url.ShortCode)
+
+		exists, err := suite.repo.Exists(context.Background(), "abc123")
+		suite.NoError(err)
+		suite.False(exists)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestUpdateFields() {
+	suite.Run("url not found", func() {
+		active := false
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Active: &active})
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("reserved short code", func() {
+		_, err := suite.repo.Reserve(context.Background(), "abc123", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		note := "launch"
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Note: &note})
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("partial update", func() {
+		_, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "launch", []string{"spring"}, "")
+		suite.Require().NoError(err)
+
+		active := false
+		tags := []string{}
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Active: &active, Tags: &tags})
+
+		suite.NoError(err)
+		suite.Equal("https://example.com", url.OriginalURL)
+		suite.False(url.Active)
+		suite.Empty(url.Tags)
+		suite.Equal("launch", url.Note)
+		suite.Zero(url.ExpiresAt)
+	})
+
+	suite.Run("all fields", func() {
+		suite.save("abc123", "https://example.com")
+
+		originalURL := "https://example.org"
+		expiresAt := time.Now().Add(time.Hour)
+		active := true
+		tags := []string{"summer"}
+		note := ""
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{
+			OriginalURL: &originalURL,
+			ExpiresAt:   &expiresAt,
+			Active:      &active,
+			Tags:        &tags,
+			Note:        &note,
+		})
+
+		suite.NoError(err)
+		suite.Equal(originalURL, url.OriginalURL)
+		suite.Equal(expiresAt, url.ExpiresAt)
+		suite.True(url.Active)
+		suite.Equal(tags, url.Tags)
+		suite.Empty(url.Note)
+
+		stored, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		suite.NoError(err)
+		suite.Equal(originalURL, stored.OriginalURL)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSetCreator() {
+	creator := entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"}
+
+	suite.Run("url not found", func() {
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		suite.save("abc123", "https://example.com")
+
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+		suite.NoError(err)
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal(creator, url.Creator)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestResetStats() {
+	suite.Run("url not found", func() {
+		err := suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		url := suite.save("abc123", "https://example.com")
+		_, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.repo.IncrementClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, "example.org"))
+		suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), url.ID, "127.0.0.1", ""))
+
+		err = suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.NoError(err)
+
+		url, err = suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		suite.NoError(err)
+		suite.Zero(url.AccessCount)
+
+		stats, err := suite.repo.RetrieveClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, 10)
+		suite.NoError(err)
+		suite.Empty(stats)
+
+		counts, err := suite.repo.CountAccessEvents(context.Background(), []int64{url.ID})
+		suite.NoError(err)
+		suite.Empty(counts)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRemove() {
+	suite.Run("url not found", func() {
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("removes statistics", func() {
+		url := suite.save("abc123", "https://example.com")
+		suite.Require().NoError(suite.repo.IncrementClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, "example.org"))
+		suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), url.ID, "127.0.0.1", ""))
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.NoError(err)
+
+		stats, err := suite.repo.RetrieveClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, 10)
+		suite.NoError(err)
+		suite.Empty(stats)
+
+		counts, err := suite.repo.CountAccessEvents(context.Background(), []int64{url.ID})
+		suite.NoError(err)
+		suite.Empty(counts)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestAliases() {
+	suite.Run("url not found", func() {
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(alias)
+	})
+
+	suite.Run("short code exists", func() {
+		suite.save("abc123", "https://example.com")
+		suite.save("def456", "https://example.org")
+
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "def456")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(alias)
+
+		_, err = suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+		suite.Require().NoError(err)
+
+		_, err = suite.repo.Save(context.Background(), "alias1", "https://example.net", "", nil, "")
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+
+		exists, err := suite.repo.Exists(context.Background(), "alias1")
+		suite.NoError(err)
+		suite.True(exists)
+	})
+
+	suite.Run("success", func() {
+		url := suite.save("abc123", "https://example.com")
+
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+
+		suite.NoError(err)
+		suite.Equal("alias1", alias.ShortCode)
+		suite.Equal("abc123", alias.CanonicalShortCode)
+
+		suite.Require().NoError(suite.repo.IncrementAliasStats(context.Background(), "alias1"))
+
+		_, err = suite.repo.Rename(context.Background(), "abc123", "def456")
+		suite.Require().NoError(err)
+
+		alias, err = suite.repo.RetrieveAlias(context.Background(), "alias1")
+
+		suite.NoError(err)
+		suite.Equal("def456", alias.CanonicalShortCode)
+		suite.Equal(int64(1), alias.AccessCount)
+
+		aliases, err := suite.repo.ListAliases(context.Background(), url.ID)
+
+		suite.NoError(err)
+		suite.Equal([]entity.URLAlias{*alias}, aliases)
+	})
+
+	suite.Run("reset statistics", func() {
+		suite.save("abc123", "https://example.com")
+		_, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.repo.IncrementAliasStats(context.Background(), "alias1"))
+
+		suite.Require().NoError(suite.repo.ResetStats(context.Background(), "abc123"))
+
+		alias, err := suite.repo.RetrieveAlias(context.Background(), "alias1")
+		suite.NoError(err)
+		suite.Zero(alias.AccessCount)
+	})
+
+	suite.Run("removed with url", func() {
+		suite.save("abc123", "https://example.com")
+		_, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+		suite.Require().NoError(err)
+
+		suite.Require().NoError(suite.repo.Remove(context.Background(), "abc123"))
+
+		_, err = suite.repo.RetrieveAlias(context.Background(), "alias1")
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+
+		err = suite.repo.IncrementAliasStats(context.Background(), "alias1")
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestClickStats() {
+	suite.Run("ordered by count", func() {
+		url := suite.save("abc123", "https://example.com")
+
+		for _, value := range []string{"b.example", "a.example", "c.example", "c.example", "a.example"} {
+			suite.Require().NoError(suite.repo.IncrementClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, value))
+		}
+		suite.Require().NoError(suite.repo.IncrementClickStats(context.Background(), url.ID, entity.ClickDimensionCountry, "DE"))
+
+		stats, err := suite.repo.RetrieveClickStats(context.Background(), url.ID, entity.ClickDimensionReferrer, 2)
+
+		suite.NoError(err)
+		suite.Equal([]entity.StatCount{{Value: "a.example", Count: 2}, {Value: "c.example", Count: 2}}, stats)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestAccessEvents() {
+	suite.Run("paginated and counted", func() {
+		first := suite.save("abc123", "https://example.com")
+		second := suite.save("def456", "https://example.org")
+
+		for _, referrer := range []string{"a", "b", "c"} {
+			suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), first.ID, "127.0.0.1", referrer))
+		}
+		suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), second.ID, "127.0.0.1", "d"))
+
+		events, err := suite.repo.ListAccessEvents(context.Background(), first.ID, 0, 2)
+
+		suite.NoError(err)
+		suite.Len(events, 2)
+		suite.Equal("c", events[0].Referrer)
+		suite.Equal("b", events[1].Referrer)
+
+		events, err = suite.repo.ListAccessEvents(context.Background(), first.ID, events[1].ID, 2)
+
+		suite.NoError(err)
+		suite.Len(events, 1)
+		suite.Equal("a", events[0].Referrer)
+
+		counts, err := suite.repo.CountAccessEvents(context.Background(), []int64{first.ID, second.ID})
+
+		suite.NoError(err)
+		suite.Equal(map[int64]int64{first.ID: 3, second.ID: 1}, counts)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSummary() {
+	suite.Run("success", func() {
+		first := suite.save("abc123", "https://example.com")
+		suite.save("def456", "https://example.org")
+		_, err := suite.repo.UpdateFields(context.Background(), "def456", entity.URLUpdate{Active: new(bool)})
+		suite.Require().NoError(err)
+		_, err = suite.repo.Reserve(context.Background(), "ghi789", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		for range 2 {
+			_, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+			suite.Require().NoError(err)
+		}
+		suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), first.ID, "127.0.0.1", ""))
+
+		summary, err := suite.repo.Summary(context.Background())
+
+		suite.NoError(err)
+		suite.Equal(int64(2), summary.TotalURLs)
+		suite.Equal(int64(1), summary.ActiveURLs)
+		suite.Equal(int64(2), summary.TotalClicks)
+		suite.Equal(int64(2), summary.CreatedLastDay)
+		suite.Len(summary.TopURLs, 2)
+		suite.Equal("abc123", summary.TopURLs[0].ShortCode)
+
+		summary, err = suite.repo.SummaryFromEvents(context.Background())
+
+		suite.NoError(err)
+		suite.Equal(int64(1), summary.TotalClicks)
+		suite.Equal(int64(1), summary.TopURLs[0].AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestDomainStats() {
+	suite.Run("success", func() {
+		first := suite.save("abc123", "https://Example.com/a")
+		suite.save("def456", "https://example.com:8443/b")
+		suite.save("ghi789", "https://docs.example.com")
+		suite.save("jkl012", "https://notexample.com")
+		_, err := suite.repo.Reserve(context.Background(), "mno345", time.Now().Add(time.Minute), "")
+		suite.Require().NoError(err)
+
+		_, err = suite.repo.RetrieveAndUpdateStats(context.Background(), "ghi789")
+		suite.Require().NoError(err)
+		suite.Require().NoError(suite.repo.SaveAccessEvent(context.Background(), first.ID, "127.0.0.1", ""))
+
+		stats, err := suite.repo.DomainStats(context.Background(), "", 10)
+
+		suite.NoError(err)
+		suite.Equal([]entity.DomainStats{
+			{Host: "docs.example.com", URLs: 1, Clicks: 1},
+			{Host: "example.com", URLs: 2},
+			{Host: "notexample.com", URLs: 1},
+		}, stats)
+
+		stats, err = suite.repo.DomainStats(context.Background(), "example.com", 1)
+
+		suite.NoError(err)
+		suite.Equal([]entity.DomainStats{{Host: "docs.example.com", URLs: 1, Clicks: 1}}, stats)
+
+		stats, err = suite.repo.DomainStatsFromEvents(context.Background(), "example.com", 10)
+
+		suite.NoError(err)
+		suite.Equal([]entity.DomainStats{
+			{Host: "example.com", URLs: 2, Clicks: 1},
+			{Host: "docs.example.com", URLs: 1},
+		}, stats)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestNextIDBlock() {
+	suite.Run("consecutive blocks", func() {
+		first, size, err := suite.repo.NextIDBlock(context.Background())
+		suite.NoError(err)
+		suite.Equal(uint64(1), first)
+		suite.Equal(uint64(shortCodeIDBlockSize), size)
+
+		first, _, err = suite.repo.NextIDBlock(context.Background())
+		suite.NoError(err)
+		suite.Equal(uint64(shortCodeIDBlockSize+1), first)
+	})
+}
+
+func TestURLRepository(t *testing.T) {
+	suite.Run(t, new(URLRepositoryTestSuite))
+}
@@ -0,0 +1,267 @@
+// Package metrics implements a URL repository decorator that observes the duration of the calls
+// to the wrapped repository in the db_query_duration_seconds Prometheus histogram, labeled by operation.
+// The durations are also added to the db metric of the Server-Timing timings of the request, if any.
+// Since it wraps the repository interface rather than a concrete implementation, it can be composed
+// with other decorators of the same interface.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+	"github.com/vadimbarashkov/url-shortener/pkg/servertiming"
+)
+
+// urlRepository defines the interface of the decorated URL repository. It mirrors the repository
+// interface of the use case, along with NextIDBlock used by sequential short code generation.
+type urlRepository interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Save(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, error)
+	Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+	RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error)
+	RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error)
+	Exists(ctx context.Context, shortCode string) (bool, error)
+	RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error)
+	List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error)
+	ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error)
+	IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error
+	RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error)
+	SaveAccessEvent(ctx context.Context, urlID int64, ip, referrer string) error
+	ListAccessEvents(ctx context.Context, urlID, before int64, limit int) ([]entity.AccessEvent, error)
+	CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error)
+	Update(ctx context.Context, shortCode, originalURL string) (*entity.URL, error)
+	Upsert(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, bool, error)
+	Rename(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error)
+	UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error)
+	SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error
+	ResetStats(ctx context.Context, shortCode string) error
+	SaveAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error)
+	RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error)
+	IncrementAliasStats(ctx context.Context, alias string) error
+	ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error)
+	Remove(ctx context.Context, shortCode string) error
+	Summary(ctx context.Context) (*entity.Summary, error)
+	SummaryFromEvents(ctx context.Context) (*entity.Summary, error)
+	DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error)
+	DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error)
+	NextIDBlock(ctx context.Context) (first, size uint64, err error)
+}
+
+// URLRepository decorates a URL repository with query duration metrics.
+// Each method is observed under the operation label named after it in snake case, e.g. save,
+// retrieve_by_short_code, update and remove.
+type URLRepository struct {
+	repo     urlRepository
+	duration *prometheus.HistogramVec
+}
+
+// NewURLRepository creates a new instance of URLRepository wrapping the provided repository and registers
+// its histogram with reg. It panics if a histogram with the same name is already registered with reg.
+func NewURLRepository(repo urlRepository, reg prometheus.Registerer) *URLRepository {
+	return &URLRepository{
+		repo: repo,
+		duration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of URL repository calls in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation"}),
+	}
+}
+
+// observe records the time elapsed since start under the given operation
+// and adds it to the db metric of the Server-Timing timings carried by ctx.
+func (r *URLRepository) observe(ctx context.Context, operation string, start time.Time) {
+	elapsed := time.Since(start)
+	r.duration.WithLabelValues(operation).Observe(elapsed.Seconds())
+	servertiming.Add(ctx, "db", elapsed)
+}
+
+// WithTx runs fn within a transaction of the wrapped repository. The transaction itself is not observed,
+// since its duration includes the work done by fn, but the repository calls made within it are.
+func (r *URLRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.repo.WithTx(ctx, fn)
+}
+
+// Save observes the duration of saving a URL.
+func (r *URLRepository) Save(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, error) {
+	defer r.observe(ctx, "save", time.Now())
+	return r.repo.Save(ctx, shortCode, originalURL, note, tags, owner)
+}
+
+// Reserve observes the duration of reserving a short code.
+func (r *URLRepository) Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error) {
+	defer r.observe(ctx, "reserve", time.Now())
+	return r.repo.Reserve(ctx, shortCode, expiresAt, owner)
+}
+
+// DeleteExpired observes the duration of deleting expired reservations.
+func (r *URLRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	defer r.observe(ctx, "delete_expired", time.Now())
+	return r.repo.DeleteExpired(ctx, now)
+}
+
+// RetrieveByShortCode observes the duration of retrieving a URL by its short code.
+func (r *URLRepository) RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+	defer r.observe(ctx, "retrieve_by_short_code", time.Now())
+	return r.repo.RetrieveByShortCode(ctx, shortCode)
+}
+
+// RetrieveManyByShortCodes observes the duration of retrieving URLs by their short codes.
+func (r *URLRepository) RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	defer r.observe(ctx, "retrieve_many_by_short_codes", time.Now())
+	return r.repo.RetrieveManyByShortCodes(ctx, shortCodes)
+}
+
+// Exists observes the duration of checking whether a short code is taken.
+func (r *URLRepository) Exists(ctx context.Context, shortCode string) (bool, error) {
+	defer r.observe(ctx, "exists", time.Now())
+	return r.repo.Exists(ctx, shortCode)
+}
+
+// RetrieveAndUpdateStats observes the duration of retrieving a URL and counting an access to it.
+func (r *URLRepository) RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error) {
+	defer r.observe(ctx, "retrieve_and_update_stats", time.Now())
+	return r.repo.RetrieveAndUpdateStats(ctx, shortCode)
+}
+
+// List observes the duration of listing URLs.
+func (r *URLRepository) List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error) {
+	defer r.observe(ctx, "list", time.Now())
+	return r.repo.List(ctx, query, tags, sort, limit, offset)
+}
+
+// ListBefore observes the duration of listing URLs by cursor.
+func (r *URLRepository) ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	defer r.observe(ctx, "list_before", time.Now())
+	return r.repo.ListBefore(ctx, query, tags, before, limit)
+}
+
+// IncrementClickStats observes the duration of incrementing a click counter.
+func (r *URLRepository) IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error {
+	defer r.observe(ctx, "increment_click_stats", time.Now())
+	return r.repo.IncrementClickStats(ctx, urlID, dimension, value)
+}
+
+// RetrieveClickStats observes the duration of retrieving click counters.
+func (r *URLRepository) RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error) {
+	defer r.observe(ctx, "retrieve_click_stats", time.Now())
+	return r.repo.RetrieveClickStats(ctx, urlID, dimension, limit)
+}
+
+// SaveAccessEvent observes the duration of recording an access event.
+func (r *URLRepository) SaveAccessEvent(ctx context.Context, urlID int64, ip, referrer string) error {
+	defer r.observe(ctx, "save_access_event", time.Now())
+	return r.repo.SaveAccessEvent(ctx, urlID, ip, referrer)
+}
+
+// ListAccessEvents observes the duration of listing access events.
+func (r *URLRepository) ListAccessEvents(ctx context.Context, urlID, before int64, limit int) ([]entity.AccessEvent, error) {
+	defer r.observe(ctx, "list_access_events", time.Now())
+	return r.repo.ListAccessEvents(ctx, urlID, before, limit)
+}
+
+// CountAccessEvents observes the duration of counting access events.
+func (r *URLRepository) CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error) {
+	defer r.observe(ctx, "count_access_events", time.Now())
+	return r.repo.CountAccessEvents(ctx, urlIDs)
+}
+
+// Update observes the duration of updating the original URL of a short code.
+func (r *URLRepository) Update(ctx context.Context, shortCode, originalURL string) (*entity.URL, error) {
+	defer r.observe(ctx, "update", time.Now())
+	return r.repo.Update(ctx, shortCode, originalURL)
+}
+
+// Upsert observes the duration of creating or updating the URL of a short code.
+func (r *URLRepository) Upsert(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, bool, error) {
+	defer r.observe(ctx, "upsert", time.Now())
+	return r.repo.Upsert(ctx, shortCode, originalURL, note, tags, owner)
+}
+
+// Rename observes the duration of renaming a short code.
+func (r *URLRepository) Rename(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error) {
+	defer r.observe(ctx, "rename", time.Now())
+	return r.repo.Rename(ctx, oldShortCode, newShortCode)
+}
+
+// UpdateFields observes the duration of updating several fields of a URL at once.
+func (r *URLRepository) UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	defer r.observe(ctx, "update_fields", time.Now())
+	return r.repo.UpdateFields(ctx, shortCode, update)
+}
+
+// SetCreator observes the duration of recording the client that created a URL.
+func (r *URLRepository) SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error {
+	defer r.observe(ctx, "set_creator", time.Now())
+	return r.repo.SetCreator(ctx, shortCode, creator)
+}
+
+// ResetStats observes the duration of resetting the statistics of a URL.
+func (r *URLRepository) ResetStats(ctx context.Context, shortCode string) error {
+	defer r.observe(ctx, "reset_stats", time.Now())
+	return r.repo.ResetStats(ctx, shortCode)
+}
+
+// SaveAlias observes the duration of saving an alias of a URL.
+func (r *URLRepository) SaveAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error) {
+	defer r.observe(ctx, "save_alias", time.Now())
+	return r.repo.SaveAlias(ctx, shortCode, alias)
+}
+
+// RetrieveAlias observes the duration of retrieving an alias.
+func (r *URLRepository) RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error) {
+	defer r.observe(ctx, "retrieve_alias", time.Now())
+	return r.repo.RetrieveAlias(ctx, alias)
+}
+
+// IncrementAliasStats observes the duration of incrementing the access count of an alias.
+func (r *URLRepository) IncrementAliasStats(ctx context.Context, alias string) error {
+	defer r.observe(ctx, "increment_alias_stats", time.Now())
+	return r.repo.IncrementAliasStats(ctx, alias)
+}
+
+// ListAliases observes the duration of listing the aliases of a URL.
+func (r *URLRepository) ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error) {
+	defer r.observe(ctx, "list_aliases", time.Now())
+	return r.repo.ListAliases(ctx, urlID)
+}
+
+// Remove observes the duration of removing a URL.
+func (r *URLRepository) Remove(ctx context.Context, shortCode string) error {
+	defer r.observe(ctx, "remove", time.Now())
+	return r.repo.Remove(ctx, shortCode)
+}
+
+// Summary observes the duration of computing the summary statistics.
+func (r *URLRepository) Summary(ctx context.Context) (*entity.Summary, error) {
+	defer r.observe(ctx, "summary", time.Now())
+	return r.repo.Summary(ctx)
+}
+
+// SummaryFromEvents observes the duration of computing the summary statistics from access events.
+func (r *URLRepository) SummaryFromEvents(ctx context.Context) (*entity.Summary, error) {
+	defer r.observe(ctx, "summary_from_events", time.Now())
+	return r.repo.SummaryFromEvents(ctx)
+}
+
+// DomainStats observes the duration of computing the statistics of destination hosts.
+func (r *URLRepository) DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	defer r.observe(ctx, "domain_stats", time.Now())
+	return r.repo.DomainStats(ctx, domain, limit)
+}
+
+// DomainStatsFromEvents observes the duration of computing the statistics of destination hosts from access events.
+func (r *URLRepository) DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	defer r.observe(ctx, "domain_stats_from_events", time.Now())
+	return r.repo.DomainStatsFromEvents(ctx, domain, limit)
+}
+
+// NextIDBlock observes the duration of reserving a block of short code IDs.
+func (r *URLRepository) NextIDBlock(ctx context.Context) (first, size uint64, err error) {
+	defer r.observe(ctx, "next_id_block", time.Now())
+	return r.repo.NextIDBlock(ctx)
+}
@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+	"github.com/vadimbarashkov/url-shortener/pkg/servertiming"
+
+	mocks "github.com/vadimbarashkov/url-shortener/mocks/metrics"
+)
+
+type URLRepositoryTestSuite struct {
+	suite.Suite
+	errUnknown  error
+	urlRepoMock *mocks.MockUrlRepository
+	registry    *prometheus.Registry
+	repo        *URLRepository
+}
+
+func (suite *URLRepositoryTestSuite) SetupSuite() {
+	suite.errUnknown = errors.New("unknown error")
+}
+
+func (suite *URLRepositoryTestSuite) SetupSubTest() {
+	suite.urlRepoMock = mocks.NewMockUrlRepository(suite.T())
+	suite.registry = prometheus.NewRegistry()
+	suite.repo = NewURLRepository(suite.urlRepoMock, suite.registry)
+}
+
+// sampleCount returns the number of observations of the histogram under the given operation.
+func (suite *URLRepositoryTestSuite) sampleCount(operation string) uint64 {
+	families, err := suite.registry.Gather()
+	suite.Require().NoError(err)
+
+	for _, family := range families {
+		if family.GetName() != "db_query_duration_seconds" {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "operation" && label.GetValue() == operation {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	return 0
+}
+
+func (suite *URLRepositoryTestSuite) TestSave() {
+	suite.Run("error", func() {
+		suite.urlRepoMock.
+			On("Save", context.Background(), "abc123", "https://example.com", "", []string(nil), "").
+			Once().
+			Return(nil, suite.errUnknown)
+
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
+
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(url)
+		suite.Equal(uint64(1), suite.sampleCount("save"))
+	})
+
+	suite.Run("success", func() {
+		suite.urlRepoMock.
+			On("Save", context.Background(), "abc123", "https://example.com", "", []string(nil), "").
+			Twice().
+			Return(&entity.URL{ShortCode: "abc123"}, nil)
+
+		for range 2 {
+			url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
+
+			suite.NoError(err)
+			suite.Equal("abc123", url.ShortCode)
+		}
+
+		suite.Equal(uint64(2), suite.sampleCount("save"))
+		suite.Zero(suite.sampleCount("retrieve_by_short_code"))
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveByShortCode() {
+	suite.Run("server timing", func() {
+		ctx, timings := servertiming.NewContext(context.Background())
+
+		suite.urlRepoMock.
+			On("RetrieveByShortCode", ctx, "abc123").
+			Once().
+			Return(&entity.URL{ShortCode: "abc123"}, nil)
+
+		_, err := suite.repo.RetrieveByShortCode(ctx, "abc123")
+
+		suite.NoError(err)
+		suite.Regexp(`^db;dur=\d+(\.\d+)?, total;dur=0$`, timings.Header(0))
+	})
+
+	suite.Run("success", func() {
+		suite.urlRepoMock.
+			On("RetrieveByShortCode", context.Background(), "abc123").
+			Once().
+			Return(&entity.URL{ShortCode: "abc123"}, nil)
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal("abc123", url.ShortCode)
+		suite.Equal(uint64(1), suite.sampleCount("retrieve_by_short_code"))
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestUpdate() {
+	suite.Run("success", func() {
+		suite.urlRepoMock.
+			On("Update", context.Background(), "abc123", "https://example.org").
+			Once().
+			Return(&entity.URL{ShortCode: "abc123", OriginalURL: "https://example.org"}, nil)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://example.org")
+
+		suite.NoError(err)
+		suite.Equal("https://example.org", url.OriginalURL)
+		suite.Equal(uint64(1), suite.sampleCount("update"))
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRemove() {
+	suite.Run("success", func() {
+		suite.urlRepoMock.
+			On("Remove", context.Background(), "abc123").
+			Once().
+			Return(nil)
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal(uint64(1), suite.sampleCount("remove"))
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestWithTx() {
+	suite.Run("observes calls within the transaction", func() {
+		suite.urlRepoMock.
+			On("WithTx", context.Background(), mock.Anything).
+			Once().
+			Return(func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(ctx)
+			})
+		suite.urlRepoMock.
+			On("Remove", context.Background(), "abc123").
+			Once().
+			Return(nil)
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			return suite.repo.Remove(ctx, "abc123")
+		})
+
+		suite.NoError(err)
+		suite.Equal(uint64(1), suite.sampleCount("remove"))
+	})
+}
+
+func TestURLRepository(t *testing.T) {
+	suite.Run(t, new(URLRepositoryTestSuite))
+}
@@ -6,16 +6,39 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
 )
 
-const uniqueViolationErrCode = "23505"
+const (
+	uniqueViolationErrCode      = "23505"
+	cannotConnectNowErrCode     = "57P03"
+	connectionExceptionErrClass = "08"
+)
+
+// deleteExpiredBatchSize is the maximum number of rows deleted by a single statement in DeleteExpired,
+// which keeps the locks held by each statement short.
+const deleteExpiredBatchSize = 1000
+
+// likeEscaper escapes the characters that have a special meaning in LIKE patterns,
+// so that user input is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// summaryTopURLsLimit is the number of most accessed URLs included in the summary.
+const summaryTopURLsLimit = 10
+
+// shortCodeIDBlockSize is the number of short code IDs reserved by a single value
+// of short_code_id_seq. It must match the increment of the sequence.
+const shortCodeIDBlockSize = 100
 
 // isUniqueViolationError checks if an error is a PostgreSQL unique constraint violation.
 // This is used to detect cases where a short code already exists in the database.
@@ -24,14 +47,50 @@ func isUniqueViolationError(err error) bool {
 	return ok && pgErr.SQLState() == uniqueViolationErrCode
 }
 
+// isConnectionError checks if an error indicates that the database is unreachable,
+// either because the connection is broken or because the server refuses to accept it.
+// Context errors are not connection errors, even though context.DeadlineExceeded satisfies net.Error.
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.SQLState(), connectionExceptionErrClass) ||
+			pgErr.SQLState() == cannotConnectNowErrCode
+	}
+
+	return false
+}
+
 // urlDB is a representation of a URL entity in the database. It maps to the columns in the `urls` table.
+// The original URL is NULL for reserved short codes, and the expiration time is NULL for URLs that never expire.
+// The host is generated from the original URL by the database.
 type urlDB struct {
-	ID          int64     `db:"id"`
-	ShortCode   string    `db:"short_code"`
-	OriginalURL string    `db:"original_url"`
-	AccessCount int64     `db:"access_count"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
+	ID               int64          `db:"id"`
+	ShortCode        string         `db:"short_code"`
+	OriginalURL      sql.NullString `db:"original_url"`
+	AccessCount      int64          `db:"access_count"`
+	CreatedAt        time.Time      `db:"created_at"`
+	UpdatedAt        time.Time      `db:"updated_at"`
+	ExpiresAt        sql.NullTime   `db:"expires_at"`
+	IsActive         bool           `db:"is_active"`
+	Tags             pq.StringArray `db:"tags"`
+	Note             string         `db:"note"`
+	CreatorIP        string         `db:"creator_ip"`
+	CreatorUserAgent string         `db:"creator_user_agent"`
+	Host             sql.NullString `db:"host"`
+	OwnerID          string         `db:"owner_id"`
 }
 
 // toEntity converts a urlDB struct to the entity URL.
@@ -39,15 +98,81 @@ func (u *urlDB) toEntity() *entity.URL {
 	return &entity.URL{
 		ID:          u.ID,
 		ShortCode:   u.ShortCode,
-		OriginalURL: u.OriginalURL,
+		OriginalURL: u.OriginalURL.String,
+		Active:      u.IsActive,
+		Tags:        u.Tags,
+		Note:        u.Note,
+		Creator: entity.Creator{
+			IP:        u.CreatorIP,
+			UserAgent: u.CreatorUserAgent,
+		},
+		Owner: u.OwnerID,
 		URLStats: entity.URLStats{
 			AccessCount: u.AccessCount,
 		},
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
+		ExpiresAt: u.ExpiresAt.Time,
+	}
+}
+
+// dbtx is implemented by both *sqlx.DB and *sqlx.Tx, which allows
+// repository methods to run either directly or within a transaction.
+type dbtx interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest any, query string, args ...any) error
+}
+
+// txKey is the context key under which the current transaction is stored.
+type txKey struct{}
+
+// statCountDB is a representation of an aggregated click counter in the database.
+// It maps to the columns in the `url_click_stats` table.
+type statCountDB struct {
+	Value string `db:"value"`
+	Count int64  `db:"count"`
+}
+
+// accessEventDB is a representation of a recorded access in the database.
+// It maps to the columns in the `url_access_events` table.
+type accessEventDB struct {
+	ID         int64     `db:"id"`
+	IP         string    `db:"ip"`
+	Referrer   string    `db:"referrer"`
+	AccessedAt time.Time `db:"accessed_at"`
+}
+
+// urlAliasDB is a representation of an alias of a URL in the database. It maps to the columns
+// in the `url_aliases` table, along with the short code of the URL it refers to.
+type urlAliasDB struct {
+	ShortCode          string    `db:"short_code"`
+	CanonicalShortCode string    `db:"canonical_short_code"`
+	AccessCount        int64     `db:"access_count"`
+	CreatedAt          time.Time `db:"created_at"`
+}
+
+// toEntity converts a urlAliasDB struct to the entity URLAlias.
+func (a *urlAliasDB) toEntity() *entity.URLAlias {
+	return &entity.URLAlias{
+		ShortCode:          a.ShortCode,
+		CanonicalShortCode: a.CanonicalShortCode,
+		AccessCount:        a.AccessCount,
+		CreatedAt:          a.CreatedAt,
 	}
 }
 
+// upsertedURLDB is a representation of a URL saved by an upsert, which reports whether the row was inserted.
+type upsertedURLDB struct {
+	urlDB
+	Inserted bool `db:"inserted"`
+}
+
+// eventCountDB is a representation of the number of access events recorded for a URL in the database.
+type eventCountDB struct {
+	URLID int64 `db:"url_id"`
+	Count int64 `db:"count"`
+}
+
 // URLRepository provides methods to interact with the PostgreSQL database for URL management.
 // It is responsible for saving, retrieving, updating, and removing URLs from the database.
 type URLRepository struct {
@@ -59,19 +184,130 @@ func NewURLRepository(db *sqlx.DB) *URLRepository {
 	return &URLRepository{db: db}
 }
 
-// Save inserts a new URL into the database with the provided short code and original URL.
+// conn returns the transaction stored in the context, if any, or the underlying database otherwise.
+func (r *URLRepository) conn(ctx context.Context) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+
+	return r.db
+}
+
+// WithTx runs fn within a database transaction. Repository methods called with the context
+// passed to fn participate in the transaction. The transaction is committed if fn returns nil
+// and rolled back otherwise. If ctx already carries a transaction, fn joins it.
+func (r *URLRepository) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	const op = "adapter.repository.postgres.URLRepository.WithTx"
+
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%s: failed to rollback transaction: %w", op, errors.Join(err, rbErr))
+		}
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// Save inserts a new URL into the database with the provided short code, original URL, note, tags and owner.
 // If a short code already exists, it returns an entity.ErrShortCodeExists error.
-func (r *URLRepository) Save(ctx context.Context, shortCode, originalURL string) (*entity.URL, error) {
+func (r *URLRepository) Save(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, error) {
 	const op = "adapter.repository.postgres.URLRepository.Save"
-	const query = `INSERT INTO urls(short_code, original_url) VALUES ($1, $2) RETURNING *`
+	const query = `INSERT INTO urls(short_code, original_url, note, tags, owner_id) VALUES ($1, $2, $3, $4, $5) RETURNING *`
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var url urlDB
+
+	if err := r.conn(ctx).GetContext(ctx, &url, query, shortCode, originalURL, note, pq.Array(tags), owner); err != nil {
+		if isUniqueViolationError(err) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+		}
+
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to insert into urls table: %w", op, err)
+	}
+
+	return url.toEntity(), nil
+}
+
+// Upsert saves a new URL with the provided short code, or updates the original URL of the URL associated with it
+// like Update if the short code already exists, and reports whether the URL was created. The note, tags and owner
+// are only set for new URLs. Existing URLs are only updated if they have the same owner, and otherwise it returns
+// an entity.ErrShortCodeExists error. If the short code exists but has expired, it returns an entity.ErrShortCodeExists
+// error until the expired URL is deleted.
+func (r *URLRepository) Upsert(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, bool, error) {
+	const op = "adapter.repository.postgres.URLRepository.Upsert"
+	const query = `INSERT INTO urls(short_code, original_url, note, tags, owner_id) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (short_code) DO UPDATE SET original_url = EXCLUDED.original_url,
+		expires_at = CASE WHEN urls.original_url IS NULL THEN NULL ELSE urls.expires_at END
+		WHERE (urls.expires_at IS NULL OR urls.expires_at > CURRENT_TIMESTAMP) AND urls.owner_id = $5
+		RETURNING *, (xmax = 0) AS inserted`
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var url upsertedURLDB
+
+	if err := r.conn(ctx).GetContext(ctx, &url, query, shortCode, originalURL, note, pq.Array(tags), owner); err != nil {
+		// No row is returned if the short code is expired or owned by someone else. The short code may also
+		// be taken by an alias, which isn't a conflict the upsert can update.
+		if errors.Is(err, sql.ErrNoRows) || isUniqueViolationError(err) {
+			return nil, false, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+		}
+
+		if isConnectionError(err) {
+			return nil, false, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, false, fmt.Errorf("%s: failed to upsert into urls table: %w", op, err)
+	}
+
+	return url.toEntity(), url.Inserted, nil
+}
+
+// Reserve inserts a short code without an original URL into the database for the owner, which expires
+// at the provided time unless an original URL is set for it before. If a short code already exists,
+// it returns an entity.ErrShortCodeExists error.
+func (r *URLRepository) Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.Reserve"
+	const query = `INSERT INTO urls(short_code, expires_at, owner_id) VALUES ($1, $2, $3) RETURNING *`
 
 	var url urlDB
 
-	if err := r.db.GetContext(ctx, &url, query, shortCode, originalURL); err != nil {
+	if err := r.conn(ctx).GetContext(ctx, &url, query, shortCode, expiresAt, owner); err != nil {
 		if isUniqueViolationError(err) {
 			return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
 		}
 
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
 		return nil, fmt.Errorf("%s: failed to insert into urls table: %w", op, err)
 	}
 
@@ -79,70 +315,804 @@ func (r *URLRepository) Save(ctx context.Context, shortCode, originalURL string)
 }
 
 // RetrieveByShortCode retrieves a URL from the database based on the provided short code.
-// If the short code is not found, it returns an entity.ErrURLNotFound error.
+// Reserved short codes are not retrieved. If the short code is not found, it returns an entity.ErrURLNotFound error.
 func (r *URLRepository) RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
 	const op = "adapter.repository.postgres.URLRepository.RetrieveByShortCode"
-	const query = `SELECT * FROM urls WHERE short_code = $1`
+	const query = `SELECT * FROM urls WHERE short_code = $1 AND original_url IS NOT NULL`
 
 	var url urlDB
 
-	if err := r.db.GetContext(ctx, &url, query, shortCode); err != nil {
+	if err := r.conn(ctx).GetContext(ctx, &url, query, shortCode); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
 		}
 
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
 		return nil, fmt.Errorf("%s: failed to get row from urls table: %w", op, err)
 	}
 
 	return url.toEntity(), nil
 }
 
+// RetrieveManyByShortCodes retrieves the URLs associated with the provided short codes in a single query,
+// keyed by short code. Reserved and unknown short codes are missing from the result.
+func (r *URLRepository) RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.RetrieveManyByShortCodes"
+	const query = `SELECT * FROM urls WHERE short_code = ANY($1) AND original_url IS NOT NULL`
+
+	var rows []urlDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, pq.Array(shortCodes)); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from urls table: %w", op, err)
+	}
+
+	urls := make(map[string]*entity.URL, len(rows))
+	for i := range rows {
+		urls[rows[i].ShortCode] = rows[i].toEntity()
+	}
+
+	return urls, nil
+}
+
+// Exists reports whether the short code is stored in the database, either for a URL, a reservation or an alias.
+func (r *URLRepository) Exists(ctx context.Context, shortCode string) (bool, error) {
+	const op = "adapter.repository.postgres.URLRepository.Exists"
+	const query = `SELECT 1 FROM urls WHERE short_code = $1
+		UNION ALL SELECT 1 FROM url_aliases WHERE short_code = $1 LIMIT 1`
+
+	var one int
+
+	if err := r.conn(ctx).GetContext(ctx, &one, query, shortCode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		if isConnectionError(err) {
+			return false, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return false, fmt.Errorf("%s: failed to get row from urls table: %w", op, err)
+	}
+
+	return true, nil
+}
+
+// listOrders maps the orders URLs can be listed in to their ORDER BY clauses. Only these clauses are
+// interpolated into the query, since the order can't be passed as a parameter.
+var listOrders = map[entity.URLSort]string{
+	entity.URLSortOldest:    "id",
+	entity.URLSortNewest:    "id DESC",
+	entity.URLSortShortCode: "short_code, id",
+}
+
+// List retrieves up to limit URLs in the given order, skipping the first offset ones. Unknown orders list URLs
+// by ID, oldest first. If query is not empty, only the URLs whose original URL, short code or note contain it,
+// ignoring case, are retrieved. If tags are given, only the URLs having all of them are retrieved.
+// Pending reservations are not retrieved.
+func (r *URLRepository) List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.List"
+	const listQuery = `
+		SELECT * FROM urls
+		WHERE original_url IS NOT NULL AND (original_url ILIKE $1 OR short_code ILIKE $1 OR note ILIKE $1) AND tags @> $2
+		ORDER BY %s
+		LIMIT $3 OFFSET $4`
+
+	order, ok := listOrders[sort]
+	if !ok {
+		order = listOrders[entity.URLSortOldest]
+	}
+
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var rows []urlDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, fmt.Sprintf(listQuery, order), pattern, pq.Array(tags), limit, offset); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from urls table: %w", op, err)
+	}
+
+	urls := make([]entity.URL, 0, len(rows))
+	for _, row := range rows {
+		urls = append(urls, *row.toEntity())
+	}
+
+	return urls, nil
+}
+
+// ListBefore retrieves up to limit URLs ordered by ID, newest first. If before is positive, only the URLs
+// with a lower ID are retrieved, so that pages can be iterated with the ID of the last URL of the previous page
+// without the database skipping over rows like with an offset. Filtering by query and tags works like in List.
+func (r *URLRepository) ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.ListBefore"
+	const listQuery = `
+		SELECT * FROM urls
+		WHERE original_url IS NOT NULL AND (original_url ILIKE $1 OR short_code ILIKE $1 OR note ILIKE $1) AND tags @> $2
+		AND ($3 <= 0 OR id < $3)
+		ORDER BY id DESC
+		LIMIT $4`
+
+	pattern := "%" + likeEscaper.Replace(query) + "%"
+
+	if tags == nil {
+		tags = []string{}
+	}
+
+	var rows []urlDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, listQuery, pattern, pq.Array(tags), before, limit); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from urls table: %w", op, err)
+	}
+
+	urls := make([]entity.URL, 0, len(rows))
+	for _, row := range rows {
+		urls = append(urls, *row.toEntity())
+	}
+
+	return urls, nil
+}
+
 // RetrieveAndUpdateStats retrieves a URL from the database by its short code and increments its access count.
+// Reserved short codes and disabled URLs are not retrieved. The access count is incremented in place
+// by a single UPDATE, which locks the row, so concurrent accesses are all counted.
 // If the short code is not found, it returns an entity.ErrURLNotFound error.
 func (r *URLRepository) RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error) {
 	const op = "adapter.repository.postgres.URLRepository.RetrieveAndUpdateStats"
-	const query = `UPDATE urls SET access_count = access_count + 1 WHERE short_code = $1 RETURNING *`
+	// The access count saturates at the maximum BIGINT value instead of failing with an out of range error.
+	const query = `UPDATE urls SET access_count = LEAST(access_count, 9223372036854775806) + 1
+		WHERE short_code = $1 AND original_url IS NOT NULL AND is_active
+		AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP) RETURNING *`
 
 	var url urlDB
 
-	if err := r.db.GetContext(ctx, &url, query, shortCode); err != nil {
+	if err := r.conn(ctx).GetContext(ctx, &url, query, shortCode); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
 		}
 
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
 		return nil, fmt.Errorf("%s: failed to get and update urls table row: %w", op, err)
 	}
 
 	return url.toEntity(), nil
 }
 
-// Update modifies the original URL associated with the provided short code.
-// If the short code is not found, it returns an entity.ErrURLNotFound error.
+// IncrementClickStats increments the number of clicks with the given value of the click dimension
+// for the URL with the provided ID, creating the counter if it doesn't exist yet.
+func (r *URLRepository) IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error {
+	const op = "adapter.repository.postgres.URLRepository.IncrementClickStats"
+	const query = `INSERT INTO url_click_stats(url_id, dimension, value, count) VALUES ($1, $2, $3, 1)
+		ON CONFLICT (url_id, dimension, value) DO UPDATE SET count = LEAST(url_click_stats.count, 9223372036854775806) + 1`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, urlID, dimension, value); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to upsert into url_click_stats table: %w", op, err)
+	}
+
+	return nil
+}
+
+// RetrieveClickStats retrieves up to limit click counters of the given dimension for the URL
+// with the provided ID, ordered by the number of clicks in descending order.
+func (r *URLRepository) RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error) {
+	const op = "adapter.repository.postgres.URLRepository.RetrieveClickStats"
+	const query = `SELECT value, count FROM url_click_stats WHERE url_id = $1 AND dimension = $2
+		ORDER BY count DESC, value LIMIT $3`
+
+	var rows []statCountDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, urlID, dimension, limit); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from url_click_stats table: %w", op, err)
+	}
+
+	stats := make([]entity.StatCount, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, entity.StatCount{Value: row.Value, Count: row.Count})
+	}
+
+	return stats, nil
+}
+
+// SaveAccessEvent records an access to the URL with the provided ID from the given IP address and referrer.
+func (r *URLRepository) SaveAccessEvent(ctx context.Context, urlID int64, ip, referrer string) error {
+	const op = "adapter.repository.postgres.URLRepository.SaveAccessEvent"
+	const query = `INSERT INTO url_access_events(url_id, ip, referrer) VALUES ($1, $2, $3)`
+
+	if _, err := r.conn(ctx).ExecContext(ctx, query, urlID, ip, referrer); err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to insert into url_access_events table: %w", op, err)
+	}
+
+	return nil
+}
+
+// ListAccessEvents retrieves up to limit recorded accesses to the URL with the provided ID, most recent first.
+// If before is positive, only the events with a lower ID are retrieved, so that the ID of the last event
+// of a page can be used to retrieve the next one.
+func (r *URLRepository) ListAccessEvents(ctx context.Context, urlID, before int64, limit int) ([]entity.AccessEvent, error) {
+	const op = "adapter.repository.postgres.URLRepository.ListAccessEvents"
+	const query = `SELECT id, ip, referrer, accessed_at FROM url_access_events
+		WHERE url_id = $1 AND ($2 <= 0 OR id < $2) ORDER BY id DESC LIMIT $3`
+
+	var rows []accessEventDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, urlID, before, limit); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from url_access_events table: %w", op, err)
+	}
+
+	events := make([]entity.AccessEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, entity.AccessEvent{
+			ID:         row.ID,
+			IP:         row.IP,
+			Referrer:   row.Referrer,
+			AccessedAt: row.AccessedAt,
+		})
+	}
+
+	return events, nil
+}
+
+// CountAccessEvents counts the access events recorded for each of the URLs with the provided IDs, keyed by URL ID.
+// URLs without any events are missing from the result.
+func (r *URLRepository) CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error) {
+	const op = "adapter.repository.postgres.URLRepository.CountAccessEvents"
+	const query = `SELECT url_id, COUNT(*) AS count FROM url_access_events
+		WHERE url_id = ANY($1) GROUP BY url_id`
+
+	var rows []eventCountDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, pq.Array(urlIDs)); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to count rows of url_access_events table: %w", op, err)
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.URLID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// Update modifies the original URL associated with the provided short code. If the short code is reserved,
+// the reservation is committed and no longer expires. If the short code is not found or its reservation
+// has expired, it returns an entity.ErrURLNotFound error.
 func (r *URLRepository) Update(ctx context.Context, shortCode, originalURL string) (*entity.URL, error) {
 	const op = "adapter.repository.postgres.URLRepository.Update"
-	const query = `UPDATE urls SET original_url = $1 WHERE short_code = $2 RETURNING *`
+	const query = `UPDATE urls SET original_url = $1,
+		expires_at = CASE WHEN original_url IS NULL THEN NULL ELSE expires_at END
+		WHERE short_code = $2 AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP) RETURNING *`
 
 	var url urlDB
 
-	if err := r.db.GetContext(ctx, &url, query, originalURL, shortCode); err != nil {
+	if err := r.conn(ctx).GetContext(ctx, &url, query, originalURL, shortCode); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
 		}
 
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
 		return nil, fmt.Errorf("%s: failed to update urls table row: %w", op, err)
 	}
 
 	return url.toEntity(), nil
 }
 
+// Rename changes the short code of the URL associated with the provided short code.
+// If the new short code already exists, it returns an entity.ErrShortCodeExists error.
+// If the old short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) Rename(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.Rename"
+	const query = `UPDATE urls SET short_code = $1 WHERE short_code = $2 RETURNING *`
+
+	var url urlDB
+
+	if err := r.conn(ctx).GetContext(ctx, &url, query, newShortCode, oldShortCode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+		}
+
+		if isUniqueViolationError(err) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+		}
+
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to update urls table row: %w", op, err)
+	}
+
+	return url.toEntity(), nil
+}
+
+// summaryDB represents the aggregate counters of the urls table.
+type summaryDB struct {
+	TotalURLs      int64 `db:"total_urls"`
+	ActiveURLs     int64 `db:"active_urls"`
+	TotalClicks    int64 `db:"total_clicks"`
+	CreatedLastDay int64 `db:"created_last_day"`
+}
+
+// Summary retrieves aggregate statistics across all shortened URLs, including the most accessed ones.
+// Pending reservations are not taken into account.
+func (r *URLRepository) Summary(ctx context.Context) (*entity.Summary, error) {
+	const op = "adapter.repository.postgres.URLRepository.Summary"
+	const countersQuery = `
+		SELECT
+			COUNT(*) AS total_urls,
+			COUNT(*) FILTER (WHERE is_active AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)) AS active_urls,
+			COALESCE(SUM(access_count), 0) AS total_clicks,
+			COUNT(*) FILTER (WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '24 hours') AS created_last_day
+		FROM urls
+		WHERE original_url IS NOT NULL`
+	const topURLsQuery = `
+		SELECT * FROM urls
+		WHERE original_url IS NOT NULL
+		ORDER BY access_count DESC, id
+		LIMIT $1`
+
+	return r.summary(ctx, op, countersQuery, topURLsQuery)
+}
+
+// SummaryFromEvents retrieves the same statistics as Summary, but with the number of clicks
+// aggregated from the access events rather than read from the access_count column.
+func (r *URLRepository) SummaryFromEvents(ctx context.Context) (*entity.Summary, error) {
+	const op = "adapter.repository.postgres.URLRepository.SummaryFromEvents"
+	const countersQuery = `
+		SELECT
+			COUNT(*) AS total_urls,
+			COUNT(*) FILTER (WHERE is_active AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)) AS active_urls,
+			(SELECT COUNT(*) FROM url_access_events) AS total_clicks,
+			COUNT(*) FILTER (WHERE created_at > CURRENT_TIMESTAMP - INTERVAL '24 hours') AS created_last_day
+		FROM urls
+		WHERE original_url IS NOT NULL`
+	const topURLsQuery = `
+		SELECT
+			urls.id, urls.short_code, urls.original_url, COALESCE(events.access_count, 0) AS access_count,
+			urls.created_at, urls.updated_at, urls.expires_at, urls.is_active, urls.tags, urls.note
+		FROM urls
+		LEFT JOIN (
+			SELECT url_id, COUNT(*) AS access_count FROM url_access_events GROUP BY url_id
+		) AS events ON events.url_id = urls.id
+		WHERE urls.original_url IS NOT NULL
+		ORDER BY access_count DESC, urls.id
+		LIMIT $1`
+
+	return r.summary(ctx, op, countersQuery, topURLsQuery)
+}
+
+// summary retrieves the summary counters and the most accessed URLs with the given queries on behalf of op.
+func (r *URLRepository) summary(ctx context.Context, op, countersQuery, topURLsQuery string) (*entity.Summary, error) {
+	var counters summaryDB
+
+	if err := r.conn(ctx).GetContext(ctx, &counters, countersQuery); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select counters from urls table: %w", op, err)
+	}
+
+	var rows []urlDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, topURLsQuery, summaryTopURLsLimit); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select top urls from urls table: %w", op, err)
+	}
+
+	topURLs := make([]entity.URL, 0, len(rows))
+	for _, row := range rows {
+		topURLs = append(topURLs, *row.toEntity())
+	}
+
+	return &entity.Summary{
+		TotalURLs:      counters.TotalURLs,
+		ActiveURLs:     counters.ActiveURLs,
+		TotalClicks:    counters.TotalClicks,
+		CreatedLastDay: counters.CreatedLastDay,
+		TopURLs:        topURLs,
+	}, nil
+}
+
+// domainStatsDB is a representation of the aggregate statistics of a destination host in the database.
+type domainStatsDB struct {
+	Host   string `db:"host"`
+	URLs   int64  `db:"urls"`
+	Clicks int64  `db:"clicks"`
+}
+
+// DomainStats retrieves the number of URLs and clicks per destination host, most clicked first, limited to limit hosts.
+// If domain isn't empty, only the domain and its subdomains are included.
+func (r *URLRepository) DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	const op = "adapter.repository.postgres.URLRepository.DomainStats"
+	const query = `
+		SELECT host, COUNT(*) AS urls, COALESCE(SUM(access_count), 0) AS clicks
+		FROM urls
+		WHERE host IS NOT NULL AND ($1 = '' OR host = $1 OR host LIKE $2)
+		GROUP BY host
+		ORDER BY clicks DESC, urls DESC, host
+		LIMIT $3`
+
+	return r.domainStats(ctx, op, query, domain, limit)
+}
+
+// DomainStatsFromEvents retrieves the same statistics as DomainStats, but with the number of clicks
+// aggregated from the access events rather than read from the access_count column.
+func (r *URLRepository) DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	const op = "adapter.repository.postgres.URLRepository.DomainStatsFromEvents"
+	const query = `
+		SELECT urls.host, COUNT(*) AS urls, COALESCE(SUM(events.access_count), 0) AS clicks
+		FROM urls
+		LEFT JOIN (
+			SELECT url_id, COUNT(*) AS access_count FROM url_access_events GROUP BY url_id
+		) AS events ON events.url_id = urls.id
+		WHERE urls.host IS NOT NULL AND ($1 = '' OR urls.host = $1 OR urls.host LIKE $2)
+		GROUP BY urls.host
+		ORDER BY clicks DESC, urls DESC, urls.host
+		LIMIT $3`
+
+	return r.domainStats(ctx, op, query, domain, limit)
+}
+
+// domainStats retrieves the statistics of destination hosts with the given query on behalf of op.
+func (r *URLRepository) domainStats(ctx context.Context, op, query, domain string, limit int) ([]entity.DomainStats, error) {
+	var rows []domainStatsDB
+
+	pattern := "%." + likeEscaper.Replace(domain)
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, domain, pattern, limit); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select domain statistics from urls table: %w", op, err)
+	}
+
+	stats := make([]entity.DomainStats, 0, len(rows))
+	for _, row := range rows {
+		stats = append(stats, entity.DomainStats{Host: row.Host, URLs: row.URLs, Clicks: row.Clicks})
+	}
+
+	return stats, nil
+}
+
+// DeleteExpired deletes the URLs and reserved short codes that expired before the provided time
+// in batches of deleteExpiredBatchSize rows and returns the total number of deleted rows.
+func (r *URLRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	const op = "adapter.repository.postgres.URLRepository.DeleteExpired"
+	const query = `
+		DELETE FROM urls
+		WHERE id IN (
+			SELECT id FROM urls
+			WHERE expires_at < $1
+			LIMIT $2
+		)`
+
+	var total int64
+
+	for {
+		res, err := r.conn(ctx).ExecContext(ctx, query, now, deleteExpiredBatchSize)
+		if err != nil {
+			if isConnectionError(err) {
+				return total, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+			}
+
+			return total, fmt.Errorf("%s: failed to delete from urls table: %w", op, err)
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("%s: failed to get number of affected rows: %w", op, err)
+		}
+
+		total += rowsAffected
+
+		if rowsAffected < deleteExpiredBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// UpdateFields applies the fields set in the update to the URL associated with the provided short code
+// and returns the updated URL. Only the columns of the set fields are updated, so at least one field must be set.
+// A zero expiration timestamp removes the expiration. If the short code is not found or is reserved,
+// it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	const op = "adapter.repository.postgres.URLRepository.UpdateFields"
+	const query = `UPDATE urls SET %s WHERE short_code = $%d AND original_url IS NOT NULL RETURNING *`
+
+	var (
+		sets []string
+		args []any
+	)
+
+	add := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if update.OriginalURL != nil {
+		add("original_url", *update.OriginalURL)
+	}
+	if update.ExpiresAt != nil {
+		add("expires_at", sql.NullTime{Time: *update.ExpiresAt, Valid: !update.ExpiresAt.IsZero()})
+	}
+	if update.Active != nil {
+		add("is_active", *update.Active)
+	}
+	if update.Tags != nil {
+		add("tags", pq.Array(*update.Tags))
+	}
+	if update.Note != nil {
+		add("note", *update.Note)
+	}
+
+	if len(sets) == 0 {
+		return nil, fmt.Errorf("%s: no fields to update", op)
+	}
+
+	args = append(args, shortCode)
+
+	var url urlDB
+
+	if err := r.conn(ctx).GetContext(ctx, &url, fmt.Sprintf(query, strings.Join(sets, ", "), len(args)), args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+		}
+
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to update urls table row: %w", op, err)
+	}
+
+	return url.toEntity(), nil
+}
+
+// SetCreator records the client that created the URL associated with the provided short code.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error {
+	const op = "adapter.repository.postgres.URLRepository.SetCreator"
+	const query = `UPDATE urls SET creator_ip = $1, creator_user_agent = $2 WHERE short_code = $3`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, creator.IP, creator.UserAgent, shortCode)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to update urls table row: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get number of affected rows: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	return nil
+}
+
+// ResetStats resets the access counts of the URL associated with the provided short code and of its aliases
+// to zero and deletes its click statistics and access events. Reserved short codes are not reset.
+// If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) ResetStats(ctx context.Context, shortCode string) error {
+	const op = "adapter.repository.postgres.URLRepository.ResetStats"
+	const query = `WITH url AS (
+			UPDATE urls SET access_count = 0 WHERE short_code = $1 AND original_url IS NOT NULL RETURNING id
+		), aliases AS (
+			UPDATE url_aliases SET access_count = 0 WHERE url_id IN (SELECT id FROM url)
+		), stats AS (
+			DELETE FROM url_click_stats WHERE url_id IN (SELECT id FROM url)
+		), events AS (
+			DELETE FROM url_access_events WHERE url_id IN (SELECT id FROM url)
+		)
+		SELECT id FROM url`
+
+	var id int64
+
+	if err := r.conn(ctx).GetContext(ctx, &id, query, shortCode); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+		}
+
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to reset url statistics: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveAlias inserts an alias for the URL associated with the provided short code. Reserved short codes
+// can't have aliases. If the alias already exists as a short code of a URL or an alias, it returns
+// an entity.ErrShortCodeExists error. If the short code is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) SaveAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error) {
+	const op = "adapter.repository.postgres.URLRepository.SaveAlias"
+	const query = `INSERT INTO url_aliases(short_code, url_id)
+		SELECT $2, id FROM urls WHERE short_code = $1 AND original_url IS NOT NULL
+		RETURNING short_code, $1::VARCHAR AS canonical_short_code, access_count, created_at`
+
+	var a urlAliasDB
+
+	if err := r.conn(ctx).GetContext(ctx, &a, query, shortCode, alias); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+		}
+
+		if isUniqueViolationError(err) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeExists)
+		}
+
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to insert into url_aliases table: %w", op, err)
+	}
+
+	return a.toEntity(), nil
+}
+
+// RetrieveAlias retrieves the alias with the provided short code along with the short code of its URL.
+// If the alias is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error) {
+	const op = "adapter.repository.postgres.URLRepository.RetrieveAlias"
+	const query = `SELECT a.short_code, u.short_code AS canonical_short_code, a.access_count, a.created_at
+		FROM url_aliases a JOIN urls u ON u.id = a.url_id WHERE a.short_code = $1`
+
+	var a urlAliasDB
+
+	if err := r.conn(ctx).GetContext(ctx, &a, query, alias); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+		}
+
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to get row from url_aliases table: %w", op, err)
+	}
+
+	return a.toEntity(), nil
+}
+
+// IncrementAliasStats increments the access count of the alias with the provided short code.
+// If the alias is not found, it returns an entity.ErrURLNotFound error.
+func (r *URLRepository) IncrementAliasStats(ctx context.Context, alias string) error {
+	const op = "adapter.repository.postgres.URLRepository.IncrementAliasStats"
+	// The access count saturates at the maximum BIGINT value like the access count of URLs.
+	const query = `UPDATE url_aliases SET access_count = LEAST(access_count, 9223372036854775806) + 1
+		WHERE short_code = $1`
+
+	res, err := r.conn(ctx).ExecContext(ctx, query, alias)
+	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return fmt.Errorf("%s: failed to update url_aliases table row: %w", op, err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: failed to get number of affected rows: %w", op, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, entity.ErrURLNotFound)
+	}
+
+	return nil
+}
+
+// ListAliases retrieves the aliases of the URL with the provided ID, oldest first.
+func (r *URLRepository) ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error) {
+	const op = "adapter.repository.postgres.URLRepository.ListAliases"
+	const query = `SELECT a.short_code, u.short_code AS canonical_short_code, a.access_count, a.created_at
+		FROM url_aliases a JOIN urls u ON u.id = a.url_id WHERE a.url_id = $1
+		ORDER BY a.created_at, a.short_code`
+
+	var rows []urlAliasDB
+
+	if err := sqlx.SelectContext(ctx, r.conn(ctx), &rows, query, urlID); err != nil {
+		if isConnectionError(err) {
+			return nil, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return nil, fmt.Errorf("%s: failed to select from url_aliases table: %w", op, err)
+	}
+
+	aliases := make([]entity.URLAlias, 0, len(rows))
+	for i := range rows {
+		aliases = append(aliases, *rows[i].toEntity())
+	}
+
+	return aliases, nil
+}
+
+// NextIDBlock reserves the next block of IDs for sequential short codes from short_code_id_seq.
+// The sequence is never rolled back, so the IDs are unique across transactions and instances.
+func (r *URLRepository) NextIDBlock(ctx context.Context) (first, size uint64, err error) {
+	const op = "adapter.repository.postgres.URLRepository.NextIDBlock"
+	const query = `SELECT nextval('short_code_id_seq')`
+
+	var id int64
+
+	if err := r.conn(ctx).GetContext(ctx, &id, query); err != nil {
+		if isConnectionError(err) {
+			return 0, 0, fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
+		return 0, 0, fmt.Errorf("%s: failed to get next value of short_code_id_seq: %w", op, err)
+	}
+
+	return uint64(id), shortCodeIDBlockSize, nil
+}
+
 // Remove deletes a URL from the database based on the provided short code.
 // If the short code is not found, it returns an entity.ErrURLNotFound error.
 func (r *URLRepository) Remove(ctx context.Context, shortCode string) error {
 	const op = "adapter.repository.postgres.URLRepository.Remove"
 	const query = `DELETE FROM urls WHERE short_code = $1`
 
-	res, err := r.db.ExecContext(ctx, query, shortCode)
+	res, err := r.conn(ctx).ExecContext(ctx, query, shortCode)
 	if err != nil {
+		if isConnectionError(err) {
+			return fmt.Errorf("%s: %w: %w", op, entity.ErrDatabaseUnavailable, err)
+		}
+
 		return fmt.Errorf("%s: failed to delete from urls table: %w", op, err)
 	}
 
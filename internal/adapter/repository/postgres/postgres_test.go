@@ -4,12 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"math"
+	"net"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jackc/pgconn"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/suite"
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
 )
@@ -18,6 +22,7 @@ type URLRepositoryTestSuite struct {
 	suite.Suite
 	errUnknown      error
 	errAffectedRows error
+	errConn         error
 	columns         []string
 	mock            sqlmock.Sqlmock
 	repo            *URLRepository
@@ -26,6 +31,7 @@ type URLRepositoryTestSuite struct {
 func (suite *URLRepositoryTestSuite) SetupSuite() {
 	suite.errUnknown = errors.New("unknown error")
 	suite.errAffectedRows = errors.New("affected rows error")
+	suite.errConn = &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
 	suite.columns = []string{"id", "short_code", "original_url", "access_count", "created_at", "updated_at"}
 }
 
@@ -51,25 +57,105 @@ func (suite *URLRepositoryTestSuite) TearDownSubTest() {
 	suite.NoError(suite.mock.ExpectationsWereMet())
 }
 
+func (suite *URLRepositoryTestSuite) TestWithTx() {
+	suite.Run("begin error", func() {
+		suite.mock.ExpectBegin().WillReturnError(suite.errUnknown)
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("companion write error", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectBegin()
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
+			WillReturnRows(rows)
+		suite.mock.ExpectExec(`UPDATE urls SET creator_ip`).
+			WithArgs("203.0.113.7", "curl/8.0", "abc123").
+			WillReturnError(suite.errUnknown)
+		suite.mock.ExpectRollback()
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			if _, err := suite.repo.Save(ctx, "abc123", "https://example.com", "", nil, ""); err != nil {
+				return err
+			}
+
+			return suite.repo.SetCreator(ctx, "abc123", entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"})
+		})
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("commit error", func() {
+		suite.mock.ExpectBegin()
+		suite.mock.ExpectCommit().WillReturnError(suite.errUnknown)
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectBegin()
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
+			WillReturnRows(rows)
+		suite.mock.ExpectCommit()
+
+		err := suite.repo.WithTx(context.Background(), func(ctx context.Context) error {
+			_, err := suite.repo.Save(ctx, "abc123", "https://example.com", "", nil, "")
+			return err
+		})
+
+		suite.NoError(err)
+	})
+}
+
 func (suite *URLRepositoryTestSuite) TestSave() {
 	suite.Run("short code exists", func() {
 		suite.mock.ExpectQuery(`INSERT INTO urls`).
-			WithArgs("abc123", "https://example.com").
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
 			WillReturnError(&pgconn.PgError{Code: uniqueViolationErrCode})
 
-		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 
 		suite.Error(err)
 		suite.ErrorIs(err, entity.ErrShortCodeExists)
 		suite.Nil(url)
 	})
 
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
+			WillReturnError(suite.errConn)
+
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(url)
+	})
+
 	suite.Run("unknown error", func() {
 		suite.mock.ExpectQuery(`INSERT INTO urls`).
-			WithArgs("abc123", "https://example.com").
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
 			WillReturnError(suite.errUnknown)
 
-		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 
 		suite.Error(err)
 		suite.ErrorIs(err, suite.errUnknown)
@@ -81,10 +167,10 @@ func (suite *URLRepositoryTestSuite) TestSave() {
 			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
 
 		suite.mock.ExpectQuery(`INSERT INTO urls`).
-			WithArgs("abc123", "https://example.com").
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{}), "").
 			WillReturnRows(rows)
 
-		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 
 		suite.NoError(err)
 		suite.NotNil(url)
@@ -92,27 +178,128 @@ func (suite *URLRepositoryTestSuite) TestSave() {
 		suite.Equal("https://example.com", url.OriginalURL)
 		suite.Zero(url.AccessCount)
 	})
+
+	suite.Run("with tags", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "tags")).
+			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{}, "{spring,email}")
+
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", "https://example.com", "", pq.Array([]string{"spring", "email"}), "").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "", []string{"spring", "email"}, "")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal([]string{"spring", "email"}, url.Tags)
+	})
+
+	suite.Run("with note", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "note")).
+			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{}, "spring newsletter")
+
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", "https://example.com", "spring newsletter", pq.Array([]string{}), "").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.Save(context.Background(), "abc123", "https://example.com", "spring newsletter", nil, "")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal("spring newsletter", url.Note)
+	})
 }
 
-func (suite *URLRepositoryTestSuite) TestRetrieveByShortCode() {
-	suite.Run("url not found", func() {
-		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
-			WithArgs("abc123").
+func (suite *URLRepositoryTestSuite) TestUpsert() {
+	suite.Run("expired short code", func() {
+		suite.mock.ExpectQuery(`INSERT INTO urls(.+) ON CONFLICT`).
+			WithArgs("my-alias", "https://example.com", "", pq.Array([]string{}), "").
 			WillReturnError(sql.ErrNoRows)
 
-		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		url, created, err := suite.repo.Upsert(context.Background(), "my-alias", "https://example.com", "", nil, "")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.False(created)
+		suite.Nil(url)
+	})
+
+	suite.Run("another owner", func() {
+		suite.mock.ExpectQuery(`INSERT INTO urls(.+) ON CONFLICT(.+) AND urls.owner_id = \$5`).
+			WithArgs("my-alias", "https://example.com", "", pq.Array([]string{}), "user-2").
+			WillReturnError(sql.ErrNoRows)
+
+		url, created, err := suite.repo.Upsert(context.Background(), "my-alias", "https://example.com", "", nil, "user-2")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.False(created)
+		suite.Nil(url)
+	})
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`INSERT INTO urls(.+) ON CONFLICT`).
+			WithArgs("my-alias", "https://example.com", "", pq.Array([]string{}), "").
+			WillReturnError(suite.errUnknown)
+
+		url, created, err := suite.repo.Upsert(context.Background(), "my-alias", "https://example.com", "", nil, "")
+
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.False(created)
+		suite.Nil(url)
+	})
+
+	suite.Run("created", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "inserted")).
+			AddRow(1, "my-alias", "https://example.com", 0, time.Time{}, time.Time{}, true)
+
+		suite.mock.ExpectQuery(`INSERT INTO urls(.+) ON CONFLICT`).
+			WithArgs("my-alias", "https://example.com", "launch", pq.Array([]string{"spring"}), "").
+			WillReturnRows(rows)
+
+		url, created, err := suite.repo.Upsert(context.Background(), "my-alias", "https://example.com", "launch", []string{"spring"}, "")
+
+		suite.NoError(err)
+		suite.True(created)
+		suite.Equal("my-alias", url.ShortCode)
+	})
+
+	suite.Run("updated", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "inserted")).
+			AddRow(1, "my-alias", "https://new-example.com", 3, time.Time{}, time.Time{}, false)
+
+		suite.mock.ExpectQuery(`INSERT INTO urls(.+) ON CONFLICT`).
+			WithArgs("my-alias", "https://new-example.com", "", pq.Array([]string{}), "").
+			WillReturnRows(rows)
+
+		url, created, err := suite.repo.Upsert(context.Background(), "my-alias", "https://new-example.com", "", nil, "")
+
+		suite.NoError(err)
+		suite.False(created)
+		suite.Equal("https://new-example.com", url.OriginalURL)
+		suite.Equal(int64(3), url.AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestReserve() {
+	expiresAt := time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+
+	suite.Run("short code exists", func() {
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", expiresAt, "").
+			WillReturnError(&pgconn.PgError{Code: uniqueViolationErrCode})
+
+		url, err := suite.repo.Reserve(context.Background(), "abc123", expiresAt, "")
 
 		suite.Error(err)
-		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
 		suite.Nil(url)
 	})
 
 	suite.Run("unknown error", func() {
-		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
-			WithArgs("abc123").
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", expiresAt, "").
 			WillReturnError(suite.errUnknown)
 
-		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		url, err := suite.repo.Reserve(context.Background(), "abc123", expiresAt, "")
 
 		suite.Error(err)
 		suite.ErrorIs(err, suite.errUnknown)
@@ -120,42 +307,54 @@ func (suite *URLRepositoryTestSuite) TestRetrieveByShortCode() {
 	})
 
 	suite.Run("success", func() {
-		rows := sqlmock.NewRows(suite.columns).
-			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
+		rows := sqlmock.NewRows(append(suite.columns, "expires_at")).
+			AddRow(0, "abc123", nil, 0, time.Time{}, time.Time{}, expiresAt)
 
-		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
-			WithArgs("abc123").
+		suite.mock.ExpectQuery(`INSERT INTO urls`).
+			WithArgs("abc123", expiresAt, "").
 			WillReturnRows(rows)
 
-		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+		url, err := suite.repo.Reserve(context.Background(), "abc123", expiresAt, "")
 
 		suite.NoError(err)
 		suite.NotNil(url)
 		suite.Equal("abc123", url.ShortCode)
-		suite.Equal("https://example.com", url.OriginalURL)
-		suite.Zero(url.AccessCount)
+		suite.Empty(url.OriginalURL)
+		suite.Equal(expiresAt, url.ExpiresAt)
 	})
 }
 
-func (suite *URLRepositoryTestSuite) TestRetrieveAndUpdateStats() {
+func (suite *URLRepositoryTestSuite) TestRetrieveByShortCode() {
 	suite.Run("url not found", func() {
-		suite.mock.ExpectQuery(`UPDATE urls`).
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
 			WithArgs("abc123").
 			WillReturnError(sql.ErrNoRows)
 
-		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
 
 		suite.Error(err)
 		suite.ErrorIs(err, entity.ErrURLNotFound)
 		suite.Nil(url)
 	})
 
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs("abc123").
+			WillReturnError(&pgconn.PgError{Code: cannotConnectNowErrCode})
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(url)
+	})
+
 	suite.Run("unknown error", func() {
-		suite.mock.ExpectQuery(`UPDATE urls`).
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
 			WithArgs("abc123").
 			WillReturnError(suite.errUnknown)
 
-		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
 
 		suite.Error(err)
 		suite.ErrorIs(err, suite.errUnknown)
@@ -166,11 +365,11 @@ func (suite *URLRepositoryTestSuite) TestRetrieveAndUpdateStats() {
 		rows := sqlmock.NewRows(suite.columns).
 			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
 
-		suite.mock.ExpectQuery(`UPDATE urls`).
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
 			WithArgs("abc123").
 			WillReturnRows(rows)
 
-		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
 
 		suite.NoError(err)
 		suite.NotNil(url)
@@ -180,91 +379,1125 @@ func (suite *URLRepositoryTestSuite) TestRetrieveAndUpdateStats() {
 	})
 }
 
-func (suite *URLRepositoryTestSuite) TestUpdate() {
-	suite.Run("url nof found", func() {
-		suite.mock.ExpectQuery(`UPDATE urls`).
-			WithArgs("https://new-example.com", "abc123").
+func (suite *URLRepositoryTestSuite) TestExists() {
+	suite.Run("not found", func() {
+		suite.mock.ExpectQuery(`SELECT 1 FROM urls`).
+			WithArgs("abc123").
 			WillReturnError(sql.ErrNoRows)
 
-		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+		exists, err := suite.repo.Exists(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.False(exists)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT 1 FROM urls`).
+			WithArgs("abc123").
+			WillReturnError(&pgconn.PgError{Code: cannotConnectNowErrCode})
+
+		exists, err := suite.repo.Exists(context.Background(), "abc123")
 
 		suite.Error(err)
-		suite.ErrorIs(err, entity.ErrURLNotFound)
-		suite.Nil(url)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.False(exists)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT 1 FROM urls`).
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"?column?"}).AddRow(1))
+
+		exists, err := suite.repo.Exists(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.True(exists)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveManyByShortCodes() {
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs(pq.Array([]string{"abc123"})).
+			WillReturnError(suite.errConn)
+
+		urls, err := suite.repo.RetrieveManyByShortCodes(context.Background(), []string{"abc123"})
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(urls)
+	})
+
+	suite.Run("existing and missing short codes", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(1, "abc123", "https://example.com", 3, time.Time{}, time.Time{}).
+			AddRow(2, "def456", "https://example.org", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE short_code = ANY\(\$1\)`).
+			WithArgs(pq.Array([]string{"abc123", "missing", "def456"})).
+			WillReturnRows(rows)
+
+		urls, err := suite.repo.RetrieveManyByShortCodes(context.Background(), []string{"abc123", "missing", "def456"})
+
+		suite.NoError(err)
+		suite.Len(urls, 2)
+		suite.Equal("https://example.com", urls["abc123"].OriginalURL)
+		suite.Equal(int64(3), urls["abc123"].AccessCount)
+		suite.Equal("https://example.org", urls["def456"].OriginalURL)
+		suite.NotContains(urls, "missing")
 	})
+}
 
+func (suite *URLRepositoryTestSuite) TestListBefore() {
 	suite.Run("unknown error", func() {
-		suite.mock.ExpectQuery(`UPDATE urls`).
-			WithArgs("https://new-example.com", "abc123").
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs("%%", pq.Array([]string{}), int64(0), 20).
 			WillReturnError(suite.errUnknown)
 
-		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+		urls, err := suite.repo.ListBefore(context.Background(), "", nil, 0, 20)
 
 		suite.Error(err)
 		suite.ErrorIs(err, suite.errUnknown)
-		suite.Nil(url)
+		suite.Nil(urls)
 	})
 
 	suite.Run("success", func() {
 		rows := sqlmock.NewRows(suite.columns).
-			AddRow(0, "abc123", "https://new-example.com", 0, time.Time{}, time.Time{})
+			AddRow(9, "def456", "https://example.com/b", 0, time.Time{}, time.Time{}).
+			AddRow(7, "abc123", "https://example.com/a", 0, time.Time{}, time.Time{})
 
-		suite.mock.ExpectQuery(`UPDATE urls`).
-			WithArgs("https://new-example.com", "abc123").
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE (.+) AND \(\$3 <= 0 OR id < \$3\) ORDER BY id DESC LIMIT \$4`).
+			WithArgs("%example%", pq.Array([]string{"spring"}), int64(10), 2).
 			WillReturnRows(rows)
 
-		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+		urls, err := suite.repo.ListBefore(context.Background(), "example", []string{"spring"}, 10, 2)
 
 		suite.NoError(err)
-		suite.NotNil(url)
-		suite.Equal("abc123", url.ShortCode)
-		suite.Equal("https://new-example.com", url.OriginalURL)
-		suite.Zero(url.AccessCount)
+		suite.Len(urls, 2)
+		suite.Equal(int64(9), urls[0].ID)
+		suite.Equal(int64(7), urls[1].ID)
 	})
 }
 
-func (suite *URLRepositoryTestSuite) TestRemove() {
+func (suite *URLRepositoryTestSuite) TestList() {
 	suite.Run("unknown error", func() {
-		suite.mock.ExpectExec(`DELETE FROM urls`).
-			WithArgs("abc123").
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs("%%", pq.Array([]string{}), 20, 0).
 			WillReturnError(suite.errUnknown)
 
-		err := suite.repo.Remove(context.Background(), "abc123")
+		urls, err := suite.repo.List(context.Background(), "", nil, entity.URLSortOldest, 20, 0)
 
 		suite.Error(err)
 		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(urls)
 	})
 
-	suite.Run("rows affected error", func() {
-		suite.mock.ExpectExec(`DELETE FROM urls`).
-			WithArgs("abc123").
-			WillReturnResult(sqlmock.NewErrorResult(suite.errAffectedRows))
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(1, "abc123", "https://example.com/100%_off", 0, time.Time{}, time.Time{})
 
-		err := suite.repo.Remove(context.Background(), "abc123")
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE (.+) ILIKE \$1`).
+			WithArgs(`%100\%\_off%`, pq.Array([]string{}), 10, 5).
+			WillReturnRows(rows)
 
-		suite.Error(err)
-		suite.ErrorIs(err, suite.errAffectedRows)
+		urls, err := suite.repo.List(context.Background(), "100%_off", nil, entity.URLSortOldest, 10, 5)
+
+		suite.NoError(err)
+		suite.Len(urls, 1)
+		suite.Equal("abc123", urls[0].ShortCode)
+		suite.Equal("https://example.com/100%_off", urls[0].OriginalURL)
+	})
+
+	suite.Run("search by note", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "note")).
+			AddRow(1, "abc123", "https://example.com", 0, time.Time{}, time.Time{}, "Spring newsletter")
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE (.+) OR note ILIKE \$1`).
+			WithArgs("%newsletter%", pq.Array([]string{}), 20, 0).
+			WillReturnRows(rows)
+
+		urls, err := suite.repo.List(context.Background(), "newsletter", nil, entity.URLSortOldest, 20, 0)
+
+		suite.NoError(err)
+		suite.Len(urls, 1)
+		suite.Equal("Spring newsletter", urls[0].Note)
+	})
+
+	suite.Run("filter by tags", func() {
+		rows := sqlmock.NewRows(append(suite.columns, "tags")).
+			AddRow(1, "abc123", "https://example.com", 0, time.Time{}, time.Time{}, "{spring,email}")
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE (.+) AND tags @> \$2`).
+			WithArgs("%%", pq.Array([]string{"spring"}), 20, 0).
+			WillReturnRows(rows)
+
+		urls, err := suite.repo.List(context.Background(), "", []string{"spring"}, entity.URLSortOldest, 20, 0)
+
+		suite.NoError(err)
+		suite.Len(urls, 1)
+		suite.Equal([]string{"spring", "email"}, urls[0].Tags)
+	})
+
+	suite.Run("sorted", func() {
+		tests := []struct {
+			sort      entity.URLSort
+			wantOrder string
+		}{
+			{sort: entity.URLSortOldest, wantOrder: `ORDER BY id LIMIT`},
+			{sort: entity.URLSortNewest, wantOrder: `ORDER BY id DESC LIMIT`},
+			{sort: entity.URLSortShortCode, wantOrder: `ORDER BY short_code, id LIMIT`},
+			{sort: "id; DROP TABLE urls", wantOrder: `ORDER BY id LIMIT`},
+		}
+
+		for _, tt := range tests {
+			suite.mock.ExpectQuery(`SELECT (.+) FROM urls WHERE (.+) `+tt.wantOrder).
+				WithArgs("%%", pq.Array([]string{}), 20, 0).
+				WillReturnRows(sqlmock.NewRows(suite.columns))
+
+			_, err := suite.repo.List(context.Background(), "", nil, tt.sort, 20, 0)
+
+			suite.NoError(err, tt.sort)
+		}
 	})
+}
 
+func (suite *URLRepositoryTestSuite) TestRetrieveAndUpdateStats() {
 	suite.Run("url not found", func() {
-		suite.mock.ExpectExec(`DELETE FROM urls`).
+		suite.mock.ExpectQuery(`UPDATE urls`).
 			WithArgs("abc123").
-			WillReturnResult(sqlmock.NewResult(0, 0))
+			WillReturnError(sql.ErrNoRows)
 
-		err := suite.repo.Remove(context.Background(), "abc123")
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
 
 		suite.Error(err)
 		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
 	})
 
-	suite.Run("success", func() {
-		suite.mock.ExpectExec(`DELETE FROM urls`).
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
 			WithArgs("abc123").
-			WillReturnResult(sqlmock.NewResult(0, 1))
+			WillReturnError(suite.errConn)
 
-		err := suite.repo.Remove(context.Background(), "abc123")
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
 
-		suite.NoError(err)
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(url)
+	})
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("abc123").
+			WillReturnError(suite.errUnknown)
+
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(url)
+	})
+
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://example.com", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal("abc123", url.ShortCode)
+		suite.Equal("https://example.com", url.OriginalURL)
+		suite.Zero(url.AccessCount)
+	})
+
+	suite.Run("saturated access count", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://example.com", int64(math.MaxInt64), time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`UPDATE urls SET access_count = LEAST\(access_count, 9223372036854775806\) \+ 1`).
+			WithArgs("abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.RetrieveAndUpdateStats(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal(int64(math.MaxInt64), url.AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestIncrementClickStats() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectExec(`INSERT INTO url_click_stats`).
+			WithArgs(1, entity.ClickDimensionReferrer, "google.com").
+			WillReturnError(suite.errUnknown)
+
+		err := suite.repo.IncrementClickStats(context.Background(), 1, entity.ClickDimensionReferrer, "google.com")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`INSERT INTO url_click_stats`).
+			WithArgs(1, entity.ClickDimensionReferrer, "google.com").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := suite.repo.IncrementClickStats(context.Background(), 1, entity.ClickDimensionReferrer, "google.com")
+
+		suite.NoError(err)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveClickStats() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_click_stats`).
+			WithArgs(1, entity.ClickDimensionReferrer, 10).
+			WillReturnError(suite.errUnknown)
+
+		stats, err := suite.repo.RetrieveClickStats(context.Background(), 1, entity.ClickDimensionReferrer, 10)
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(stats)
+	})
+
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows([]string{"value", "count"}).
+			AddRow("google.com", 2).
+			AddRow("direct", 1)
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_click_stats`).
+			WithArgs(1, entity.ClickDimensionReferrer, 10).
+			WillReturnRows(rows)
+
+		stats, err := suite.repo.RetrieveClickStats(context.Background(), 1, entity.ClickDimensionReferrer, 10)
+
+		suite.NoError(err)
+		suite.Equal([]entity.StatCount{
+			{Value: "google.com", Count: 2},
+			{Value: "direct", Count: 1},
+		}, stats)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSaveAccessEvent() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectExec(`INSERT INTO url_access_events`).
+			WithArgs(1, "203.0.113.1", "https://google.com").
+			WillReturnError(suite.errUnknown)
+
+		err := suite.repo.SaveAccessEvent(context.Background(), 1, "203.0.113.1", "https://google.com")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`INSERT INTO url_access_events`).
+			WithArgs(1, "203.0.113.1", "https://google.com").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := suite.repo.SaveAccessEvent(context.Background(), 1, "203.0.113.1", "https://google.com")
+
+		suite.NoError(err)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestListAccessEvents() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events`).
+			WithArgs(1, 0, 20).
+			WillReturnError(suite.errUnknown)
+
+		events, err := suite.repo.ListAccessEvents(context.Background(), 1, 0, 20)
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(events)
+	})
+
+	suite.Run("success", func() {
+		accessedAt := time.Now()
+		rows := sqlmock.NewRows([]string{"id", "ip", "referrer", "accessed_at"}).
+			AddRow(9, "203.0.113.1", "https://google.com", accessedAt).
+			AddRow(8, "198.51.100.1", "", accessedAt)
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events`).
+			WithArgs(1, 10, 2).
+			WillReturnRows(rows)
+
+		events, err := suite.repo.ListAccessEvents(context.Background(), 1, 10, 2)
+
+		suite.NoError(err)
+		suite.Equal([]entity.AccessEvent{
+			{ID: 9, IP: "203.0.113.1", Referrer: "https://google.com", AccessedAt: accessedAt},
+			{ID: 8, IP: "198.51.100.1", AccessedAt: accessedAt},
+		}, events)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestCountAccessEvents() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events`).
+			WithArgs(pq.Array([]int64{1, 2})).
+			WillReturnError(suite.errUnknown)
+
+		counts, err := suite.repo.CountAccessEvents(context.Background(), []int64{1, 2})
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(counts)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events`).
+			WithArgs(pq.Array([]int64{1, 2})).
+			WillReturnRows(sqlmock.NewRows([]string{"url_id", "count"}).AddRow(1, 3))
+
+		counts, err := suite.repo.CountAccessEvents(context.Background(), []int64{1, 2})
+
+		suite.NoError(err)
+		suite.Equal(map[int64]int64{1: 3}, counts)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestUpdate() {
+	suite.Run("url nof found", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("https://new-example.com", "abc123").
+			WillReturnError(sql.ErrNoRows)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("https://new-example.com", "abc123").
+			WillReturnError(suite.errUnknown)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(url)
+	})
+
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://new-example.com", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("https://new-example.com", "abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.Update(context.Background(), "abc123", "https://new-example.com")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal("abc123", url.ShortCode)
+		suite.Equal("https://new-example.com", url.OriginalURL)
+		suite.Zero(url.AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRename() {
+	suite.Run("url not found", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("my-alias", "abc123").
+			WillReturnError(sql.ErrNoRows)
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "my-alias")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("short code exists", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("my-alias", "abc123").
+			WillReturnError(&pgconn.PgError{Code: uniqueViolationErrCode})
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "my-alias")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(url)
+	})
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`UPDATE urls`).
+			WithArgs("my-alias", "abc123").
+			WillReturnError(suite.errUnknown)
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "my-alias")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(url)
+	})
+
+	suite.Run("success", func() {
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "my-alias", "https://example.com", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`UPDATE urls SET short_code`).
+			WithArgs("my-alias", "abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.Rename(context.Background(), "abc123", "my-alias")
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal("my-alias", url.ShortCode)
+		suite.Equal("https://example.com", url.OriginalURL)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestUpdateFields() {
+	suite.Run("no fields", func() {
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{})
+
+		suite.Error(err)
+		suite.Nil(url)
+	})
+
+	suite.Run("url not found", func() {
+		active := false
+
+		suite.mock.ExpectQuery(`UPDATE urls SET is_active = \$1 WHERE short_code = \$2`).
+			WithArgs(false, "abc123").
+			WillReturnError(sql.ErrNoRows)
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Active: &active})
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(url)
+	})
+
+	suite.Run("unknown error", func() {
+		note := ""
+
+		suite.mock.ExpectQuery(`UPDATE urls SET note = \$1 WHERE short_code = \$2`).
+			WithArgs("", "abc123").
+			WillReturnError(suite.errUnknown)
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{Note: &note})
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(url)
+	})
+
+	suite.Run("partial update", func() {
+		originalURL := "https://example.org"
+		expiresAt := time.Time{}
+
+		rows := sqlmock.NewRows(suite.columns).
+			AddRow(0, "abc123", "https://example.org", 0, time.Time{}, time.Time{})
+
+		suite.mock.ExpectQuery(`UPDATE urls SET original_url = \$1, expires_at = \$2 WHERE short_code = \$3`).
+			WithArgs("https://example.org", nil, "abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{
+			OriginalURL: &originalURL,
+			ExpiresAt:   &expiresAt,
+		})
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.Equal("https://example.org", url.OriginalURL)
+	})
+
+	suite.Run("all fields", func() {
+		originalURL := "https://example.org"
+		expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+		active := true
+		tags := []string{"summer"}
+		note := "launch"
+
+		rows := sqlmock.NewRows(append(suite.columns, "is_active", "note")).
+			AddRow(0, "abc123", "https://example.org", 0, time.Time{}, time.Time{}, true, "launch")
+
+		suite.mock.ExpectQuery(`UPDATE urls SET original_url = \$1, expires_at = \$2, is_active = \$3, tags = \$4, note = \$5 `+
+			`WHERE short_code = \$6 AND original_url IS NOT NULL RETURNING \*`).
+			WithArgs("https://example.org", expiresAt, true, pq.Array(tags), "launch", "abc123").
+			WillReturnRows(rows)
+
+		url, err := suite.repo.UpdateFields(context.Background(), "abc123", entity.URLUpdate{
+			OriginalURL: &originalURL,
+			ExpiresAt:   &expiresAt,
+			Active:      &active,
+			Tags:        &tags,
+			Note:        &note,
+		})
+
+		suite.NoError(err)
+		suite.NotNil(url)
+		suite.True(url.Active)
+		suite.Equal("launch", url.Note)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSummary() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WillReturnError(suite.errUnknown)
+
+		summary, err := suite.repo.Summary(context.Background())
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(summary)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WillReturnRows(sqlmock.NewRows([]string{"total_urls", "active_urls", "total_clicks", "created_last_day"}).
+				AddRow(3, 2, 10, 1))
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs(summaryTopURLsLimit).
+			WillReturnError(suite.errConn)
+
+		summary, err := suite.repo.Summary(context.Background())
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(summary)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WillReturnRows(sqlmock.NewRows([]string{"total_urls", "active_urls", "total_clicks", "created_last_day"}).
+				AddRow(3, 2, 10, 1))
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs(summaryTopURLsLimit).
+			WillReturnRows(sqlmock.NewRows(append(suite.columns, "expires_at")).
+				AddRow(1, "abc123", "https://example.com", 7, time.Time{}, time.Time{}, nil).
+				AddRow(2, "def456", "https://example.org", 3, time.Time{}, time.Time{}, nil))
+
+		summary, err := suite.repo.Summary(context.Background())
+
+		suite.NoError(err)
+		suite.NotNil(summary)
+		suite.Equal(int64(3), summary.TotalURLs)
+		suite.Equal(int64(2), summary.ActiveURLs)
+		suite.Equal(int64(10), summary.TotalClicks)
+		suite.Equal(int64(1), summary.CreatedLastDay)
+		suite.Len(summary.TopURLs, 2)
+		suite.Equal("abc123", summary.TopURLs[0].ShortCode)
+		suite.Equal(int64(7), summary.TopURLs[0].AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestDomainStats() {
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT host(.+) FROM urls(.+) GROUP BY host`).
+			WithArgs("", "%.", 10).
+			WillReturnError(suite.errConn)
+
+		stats, err := suite.repo.DomainStats(context.Background(), "", 10)
+
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Nil(stats)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT host(.+) FROM urls(.+) GROUP BY host`).
+			WithArgs("my_site.com", `%.my\_site.com`, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"host", "urls", "clicks"}).
+				AddRow("my_site.com", 2, 7).
+				AddRow("docs.my_site.com", 1, 3))
+
+		stats, err := suite.repo.DomainStats(context.Background(), "my_site.com", 10)
+
+		suite.NoError(err)
+		suite.Equal([]entity.DomainStats{
+			{Host: "my_site.com", URLs: 2, Clicks: 7},
+			{Host: "docs.my_site.com", URLs: 1, Clicks: 3},
+		}, stats)
+	})
+
+	suite.Run("from events", func() {
+		suite.mock.ExpectQuery(`SELECT urls.host(.+) FROM urls(.+) FROM url_access_events`).
+			WithArgs("", "%.", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"host", "urls", "clicks"}).AddRow("example.com", 2, 1))
+
+		stats, err := suite.repo.DomainStatsFromEvents(context.Background(), "", 10)
+
+		suite.NoError(err)
+		suite.Equal([]entity.DomainStats{{Host: "example.com", URLs: 2, Clicks: 1}}, stats)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSummaryFromEvents() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events(.+) FROM urls`).
+			WillReturnError(suite.errUnknown)
+
+		summary, err := suite.repo.SummaryFromEvents(context.Background())
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(summary)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_access_events(.+) FROM urls`).
+			WillReturnRows(sqlmock.NewRows([]string{"total_urls", "active_urls", "total_clicks", "created_last_day"}).
+				AddRow(3, 2, 10, 1))
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls LEFT JOIN (.+) FROM url_access_events`).
+			WithArgs(summaryTopURLsLimit).
+			WillReturnRows(sqlmock.NewRows(append(suite.columns, "expires_at")).
+				AddRow(1, "abc123", "https://example.com", 7, time.Time{}, time.Time{}, nil))
+
+		summary, err := suite.repo.SummaryFromEvents(context.Background())
+
+		suite.NoError(err)
+		suite.Equal(int64(10), summary.TotalClicks)
+		suite.Len(summary.TopURLs, 1)
+		suite.Equal(int64(7), summary.TopURLs[0].AccessCount)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestDeleteExpired() {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs(now, deleteExpiredBatchSize).
+			WillReturnError(suite.errUnknown)
+
+		n, err := suite.repo.DeleteExpired(context.Background(), now)
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Zero(n)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs(now, deleteExpiredBatchSize).
+			WillReturnError(suite.errConn)
+
+		n, err := suite.repo.DeleteExpired(context.Background(), now)
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+		suite.Zero(n)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs(now, deleteExpiredBatchSize).
+			WillReturnResult(sqlmock.NewResult(0, deleteExpiredBatchSize))
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs(now, deleteExpiredBatchSize).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		n, err := suite.repo.DeleteExpired(context.Background(), now)
+
+		suite.NoError(err)
+		suite.Equal(int64(deleteExpiredBatchSize+2), n)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestNextIDBlock() {
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`SELECT nextval\('short_code_id_seq'\)`).
+			WillReturnError(suite.errConn)
+
+		_, _, err := suite.repo.NextIDBlock(context.Background())
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT nextval\('short_code_id_seq'\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"nextval"}).AddRow(201))
+
+		first, size, err := suite.repo.NextIDBlock(context.Background())
+
+		suite.NoError(err)
+		suite.Equal(uint64(201), first)
+		suite.Equal(uint64(shortCodeIDBlockSize), size)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRemove() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs("abc123").
+			WillReturnError(suite.errUnknown)
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs("abc123").
+			WillReturnError(suite.errConn)
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+	})
+
+	suite.Run("rows affected error", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewErrorResult(suite.errAffectedRows))
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, suite.errAffectedRows)
+	})
+
+	suite.Run("url not found", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.Error(err)
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`DELETE FROM urls`).
+			WithArgs("abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := suite.repo.Remove(context.Background(), "abc123")
+
+		suite.NoError(err)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSetCreator() {
+	creator := entity.Creator{IP: "203.0.113.7", UserAgent: "curl/8.0"}
+
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectExec(`UPDATE urls SET creator_ip`).
+			WithArgs("203.0.113.7", "curl/8.0", "abc123").
+			WillReturnError(suite.errUnknown)
+
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectExec(`UPDATE urls SET creator_ip`).
+			WithArgs("203.0.113.7", "curl/8.0", "abc123").
+			WillReturnError(suite.errConn)
+
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+	})
+
+	suite.Run("url not found", func() {
+		suite.mock.ExpectExec(`UPDATE urls SET creator_ip`).
+			WithArgs("203.0.113.7", "curl/8.0", "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`UPDATE urls SET creator_ip`).
+			WithArgs("203.0.113.7", "curl/8.0", "abc123").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := suite.repo.SetCreator(context.Background(), "abc123", creator)
+
+		suite.NoError(err)
+
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows(append(suite.columns, "creator_ip", "creator_user_agent")).
+				AddRow(1, "abc123", "https://example.com", 0, time.Time{}, time.Time{}, "203.0.113.7", "curl/8.0"))
+
+		url, err := suite.repo.RetrieveByShortCode(context.Background(), "abc123")
+
+		suite.NoError(err)
+		suite.Equal(creator, url.Creator)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestResetStats() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`WITH url AS \(\s*UPDATE urls SET access_count = 0`).
+			WithArgs("abc123").
+			WillReturnError(suite.errUnknown)
+
+		err := suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, suite.errUnknown)
+	})
+
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectQuery(`WITH url AS \(\s*UPDATE urls SET access_count = 0`).
+			WithArgs("abc123").
+			WillReturnError(suite.errConn)
+
+		err := suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+	})
+
+	suite.Run("url not found", func() {
+		suite.mock.ExpectQuery(`WITH url AS \(\s*UPDATE urls SET access_count = 0`).
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		err := suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`WITH url AS \(\s*UPDATE urls SET access_count = 0(.+)UPDATE url_aliases(.+)DELETE FROM url_click_stats(.+)DELETE FROM url_access_events`).
+			WithArgs("abc123").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		err := suite.repo.ResetStats(context.Background(), "abc123")
+
+		suite.NoError(err)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestSaveAlias() {
+	columns := []string{"short_code", "canonical_short_code", "access_count", "created_at"}
+
+	suite.Run("short code exists", func() {
+		suite.mock.ExpectQuery(`INSERT INTO url_aliases`).
+			WithArgs("abc123", "alias1").
+			WillReturnError(&pgconn.PgError{Code: uniqueViolationErrCode})
+
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+
+		suite.ErrorIs(err, entity.ErrShortCodeExists)
+		suite.Nil(alias)
+	})
+
+	suite.Run("url not found", func() {
+		suite.mock.ExpectQuery(`INSERT INTO url_aliases`).
+			WithArgs("abc123", "alias1").
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(alias)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`INSERT INTO url_aliases`).
+			WithArgs("abc123", "alias1").
+			WillReturnRows(sqlmock.NewRows(columns).AddRow("alias1", "abc123", 0, time.Time{}))
+
+		alias, err := suite.repo.SaveAlias(context.Background(), "abc123", "alias1")
+
+		suite.NoError(err)
+		suite.Equal(&entity.URLAlias{ShortCode: "alias1", CanonicalShortCode: "abc123"}, alias)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestRetrieveAlias() {
+	suite.Run("url not found", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_aliases`).
+			WithArgs("alias1").
+			WillReturnRows(sqlmock.NewRows([]string{"short_code"}))
+
+		alias, err := suite.repo.RetrieveAlias(context.Background(), "alias1")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+		suite.Nil(alias)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_aliases`).
+			WithArgs("alias1").
+			WillReturnRows(sqlmock.NewRows([]string{"short_code", "canonical_short_code", "access_count", "created_at"}).
+				AddRow("alias1", "abc123", 3, time.Time{}))
+
+		alias, err := suite.repo.RetrieveAlias(context.Background(), "alias1")
+
+		suite.NoError(err)
+		suite.Equal(&entity.URLAlias{ShortCode: "alias1", CanonicalShortCode: "abc123", AccessCount: 3}, alias)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestIncrementAliasStats() {
+	suite.Run("database unavailable", func() {
+		suite.mock.ExpectExec(`UPDATE url_aliases`).
+			WithArgs("alias1").
+			WillReturnError(suite.errConn)
+
+		err := suite.repo.IncrementAliasStats(context.Background(), "alias1")
+
+		suite.ErrorIs(err, entity.ErrDatabaseUnavailable)
+	})
+
+	suite.Run("url not found", func() {
+		suite.mock.ExpectExec(`UPDATE url_aliases`).
+			WithArgs("alias1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		err := suite.repo.IncrementAliasStats(context.Background(), "alias1")
+
+		suite.ErrorIs(err, entity.ErrURLNotFound)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectExec(`UPDATE url_aliases`).
+			WithArgs("alias1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := suite.repo.IncrementAliasStats(context.Background(), "alias1")
+
+		suite.NoError(err)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestListAliases() {
+	suite.Run("unknown error", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_aliases`).
+			WithArgs(1).
+			WillReturnError(suite.errUnknown)
+
+		aliases, err := suite.repo.ListAliases(context.Background(), 1)
+
+		suite.ErrorIs(err, suite.errUnknown)
+		suite.Nil(aliases)
+	})
+
+	suite.Run("success", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM url_aliases`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"short_code", "canonical_short_code", "access_count", "created_at"}).
+				AddRow("alias1", "abc123", 3, time.Time{}).
+				AddRow("alias2", "abc123", 0, time.Time{}))
+
+		aliases, err := suite.repo.ListAliases(context.Background(), 1)
+
+		suite.NoError(err)
+		suite.Equal([]entity.URLAlias{
+			{ShortCode: "alias1", CanonicalShortCode: "abc123", AccessCount: 3},
+			{ShortCode: "alias2", CanonicalShortCode: "abc123"},
+		}, aliases)
+	})
+}
+
+func (suite *URLRepositoryTestSuite) TestContextCancellation() {
+	operations := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{
+			name: "save",
+			call: func(ctx context.Context) error {
+				_, err := suite.repo.Save(ctx, "abc123", "https://example.com", "", nil, "")
+				return err
+			},
+		},
+		{
+			name: "retrieve by short code",
+			call: func(ctx context.Context) error {
+				_, err := suite.repo.RetrieveByShortCode(ctx, "abc123")
+				return err
+			},
+		},
+		{
+			name: "list",
+			call: func(ctx context.Context) error {
+				_, err := suite.repo.List(ctx, "", nil, entity.URLSortOldest, 10, 0)
+				return err
+			},
+		},
+		{
+			name: "update",
+			call: func(ctx context.Context) error {
+				_, err := suite.repo.Update(ctx, "abc123", "https://example.org")
+				return err
+			},
+		},
+		{
+			name: "remove",
+			call: func(ctx context.Context) error {
+				return suite.repo.Remove(ctx, "abc123")
+			},
+		},
+		{
+			name: "with tx",
+			call: func(ctx context.Context) error {
+				return suite.repo.WithTx(ctx, func(ctx context.Context) error {
+					return nil
+				})
+			},
+		},
+	}
+
+	for _, op := range operations {
+		suite.Run(op.name+": canceled", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := op.call(ctx)
+
+			suite.ErrorIs(err, context.Canceled)
+			suite.NotErrorIs(err, entity.ErrDatabaseUnavailable)
+		})
+
+		suite.Run(op.name+": deadline exceeded", func() {
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+
+			err := op.call(ctx)
+
+			suite.ErrorIs(err, context.DeadlineExceeded)
+			suite.NotErrorIs(err, entity.ErrDatabaseUnavailable)
+		})
+	}
+
+	suite.Run("canceled during query", func() {
+		suite.mock.ExpectQuery(`SELECT (.+) FROM urls`).
+			WithArgs("abc123").
+			WillDelayFor(time.Minute).
+			WillReturnRows(sqlmock.NewRows(suite.columns))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		url, err := suite.repo.RetrieveByShortCode(ctx, "abc123")
+
+		suite.ErrorIs(err, sqlmock.ErrCancelled)
+		suite.Nil(url)
+		suite.Less(time.Since(start), time.Second)
 	})
 }
 
@@ -2,97 +2,383 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"time"
 
 	"github.com/go-chi/httplog/v2"
+	"github.com/jmoiron/sqlx"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/vadimbarashkov/url-shortener/internal/config"
 	"github.com/vadimbarashkov/url-shortener/internal/usecase"
+	"github.com/vadimbarashkov/url-shortener/pkg/geoip"
+	"github.com/vadimbarashkov/url-shortener/pkg/httpclient"
+	"github.com/vadimbarashkov/url-shortener/pkg/jwt"
+	"github.com/vadimbarashkov/url-shortener/pkg/linkcheck"
 	"github.com/vadimbarashkov/url-shortener/pkg/postgres"
+	"github.com/vadimbarashkov/url-shortener/pkg/shortcode"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"golang.org/x/sync/errgroup"
 
 	delivery "github.com/vadimbarashkov/url-shortener/internal/adapter/delivery/http"
+	"github.com/vadimbarashkov/url-shortener/internal/adapter/repository/memory"
+	"github.com/vadimbarashkov/url-shortener/internal/adapter/repository/metrics"
 	repo "github.com/vadimbarashkov/url-shortener/internal/adapter/repository/postgres"
 )
 
+// migrationsPath is the source of the database migrations applied on startup.
+const migrationsPath = "file://migrations"
+
+// shortCodeAlphabets maps the short code encodings clients may request to their alphabets.
+var shortCodeAlphabets = map[string]string{
+	config.ShortCodeEncodingBase62: shortcode.AlphabetBase62,
+	config.ShortCodeEncodingBase58: shortcode.AlphabetBase58,
+	config.ShortCodeEncodingHex:    shortcode.AlphabetHex,
+}
+
 // Run initializes and starts the HTTP server with the given configuration.
-// It connects to the PostgreSQL database, applies migrations, sets up the URL use case, and starts the server.
+// It connects to the PostgreSQL database and applies migrations unless URLs are stored in memory,
+// sets up the URL use case, and starts the server.
 func Run(ctx context.Context, cfg *config.Config) error {
 	const op = "app.Run"
 
-	db, err := postgres.New(ctx, cfg.Postgres.DSN())
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%s: invalid config: %w", op, err)
+	}
+
+	var logOut io.Writer = os.Stdout
+	if cfg.LogFile != "" {
+		f, err := os.OpenFile(cfg.LogFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return fmt.Errorf("%s: failed to open log file: %w", op, err)
+		}
+		defer f.Close()
+
+		logOut = f
+	}
+
+	logger := setupLogger(cfg, logOut)
+
+	var (
+		urlRepo        *metrics.URLRepository
+		readinessCheck func(ctx context.Context) error
+	)
+
+	switch cfg.DBDriver {
+	case config.DBDriverMemory:
+		urlRepo = metrics.NewURLRepository(memory.NewURLRepository(), prometheus.DefaultRegisterer)
+	default:
+		retry := postgres.Retry{
+			Attempts: cfg.StartupDBRetries,
+			Interval: cfg.StartupDBRetryInterval,
+			OnRetry: func(attempt int, wait time.Duration, err error) {
+				logger.Warn("failed to connect to database, retrying",
+					slog.Int("attempt", attempt), slog.Duration("retry_in", wait), slog.Any("err", err))
+			},
+		}
+
+		db, err := postgres.Connect(ctx, cfg.Postgres.DSN(), retry)
+		if err != nil {
+			return fmt.Errorf("%s: failed to connect to database: %w", op, err)
+		}
+		defer db.Close()
+
+		if err := postgres.RunMigrations(migrationsPath, cfg.Postgres.DSN()); err != nil {
+			return fmt.Errorf("%s: failed to run migrations: %w", op, err)
+		}
+
+		readinessCheck, err = newReadinessCheck(cfg, db)
+		if err != nil {
+			return fmt.Errorf("%s: failed to set up readiness check: %w", op, err)
+		}
+
+		urlRepo = metrics.NewURLRepository(repo.NewURLRepository(db), prometheus.DefaultRegisterer)
+	}
+
+	// Features sending requests to other servers share a client, so that a slow server can't hold up a request.
+	outboundClient := httpclient.New(
+		httpclient.WithConnectTimeout(cfg.OutboundHTTP.ConnectTimeout),
+		httpclient.WithReadTimeout(cfg.OutboundHTTP.ReadTimeout),
+		httpclient.WithMaxRedirects(cfg.OutboundHTTP.MaxRedirects),
+		httpclient.WithMaxBodySize(cfg.OutboundHTTP.MaxBodySize),
+	)
+
+	urlOpts := []usecase.URLOption{
+		usecase.WithShortCodeLength(cfg.ShortCodeLength),
+		usecase.WithMaxShortCodeLength(cfg.MaxShortCodeLength),
+		usecase.WithMinCustomShortCodeLength(cfg.MinCustomShortCodeLength),
+		usecase.WithAliasConflictSuffix(cfg.AliasConflict == config.AliasConflictSuffix),
+		usecase.WithShortCodeTiers(cfg.ShortCodeTiers),
+		usecase.WithCodePrefix(cfg.CodePrefix),
+		usecase.WithReservationTTL(cfg.Reservation.TTL),
+		usecase.WithClickDebounce(cfg.ClickDebounce),
+		usecase.WithCreationCooldown(cfg.CreationCooldown.Limit, cfg.CreationCooldown.Window),
+		usecase.WithHideInactiveStats(cfg.HideInactiveStats),
+		usecase.WithEventTracking(cfg.TrackingMode == config.TrackingModeEvents),
+		usecase.WithCreatorTracking(cfg.TrackCreators),
+		usecase.WithDomainPolicy(cfg.AllowedDomains, cfg.BlockedDomains),
+		usecase.WithSlashCollapsing(cfg.CollapseURLSlashes),
+	}
+
+	if cfg.BaseURL != "" && !cfg.AllowSelfLinks {
+		baseURL, err := url.Parse(cfg.BaseURL)
+		if err != nil {
+			return fmt.Errorf("%s: failed to parse base url: %w", op, err)
+		}
+
+		urlOpts = append(urlOpts, usecase.WithSelfHost(baseURL.Hostname()))
+	}
+
+	if cfg.GeoIP.DBPath != "" {
+		geoDB, err := geoip.Open(cfg.GeoIP.DBPath)
+		if err != nil {
+			return fmt.Errorf("%s: failed to open geoip database: %w", op, err)
+		}
+		defer geoDB.Close()
+
+		urlOpts = append(urlOpts, usecase.WithCountryResolver(geoDB))
+	}
+
+	if cfg.LinkCheck.Enabled {
+		urlOpts = append(urlOpts, usecase.WithLinkChecker(linkcheck.New(outboundClient, cfg.LinkCheck.Timeout), cfg.LinkCheck.Concurrency))
+	}
+
+	if cfg.HashIPs {
+		urlOpts = append(urlOpts, usecase.WithIPHashing([]byte(cfg.IPHashSalt)))
+	}
+
+	encodings := make(map[string]usecase.ShortCodeGenerator, len(cfg.ShortCodeEncodings))
+	for _, encoding := range cfg.ShortCodeEncodings {
+		encodings[encoding] = shortcode.NewRandom(shortCodeAlphabets[encoding])
+	}
+	urlOpts = append(urlOpts, usecase.WithShortCodeEncodings(encodings))
+
+	if cfg.ShortCodeGenerator == config.ShortCodeGeneratorSequence {
+		urlOpts = append(urlOpts, usecase.WithShortCodeGenerator(shortcode.NewSequence(urlRepo)))
+	}
+
+	urlUseCase := usecase.NewURLUseCase(urlRepo, urlOpts...)
+
+	trustedProxies, err := cfg.HTTPServer.TrustedProxyPrefixes()
 	if err != nil {
-		return fmt.Errorf("%s: failed to connect to database: %w", op, err)
+		return fmt.Errorf("%s: failed to parse trusted proxies: %w", op, err)
 	}
-	defer db.Close()
 
-	if err := postgres.RunMigrations("file://migrations", cfg.Postgres.DSN()); err != nil {
-		return fmt.Errorf("%s: failed to run migrations: %w", op, err)
+	tlsConfig, err := cfg.HTTPServer.TLSConfig()
+	if err != nil {
+		return fmt.Errorf("%s: failed to build tls config: %w", op, err)
 	}
 
-	urlRepo := repo.NewURLRepository(db)
-	urlUseCase := usecase.NewURLUseCase(urlRepo)
+	tokenVerifier, err := newTokenVerifier(ctx, cfg.Auth, outboundClient)
+	if err != nil {
+		return fmt.Errorf("%s: failed to set up token verification: %w", op, err)
+	}
 
-	logger := setupLogger(cfg.Env)
-	r := delivery.NewRouter(logger, urlUseCase)
+	routerOpts := []delivery.RouterOption{
+		delivery.WithTrustedProxies(trustedProxies...),
+		delivery.WithSwagger(cfg.Swagger.Enabled, cfg.Swagger.Path),
+		delivery.WithRequestTimeout(cfg.HTTPServer.RequestTimeout),
+		delivery.WithMaxConcurrentRequests(cfg.HTTPServer.MaxConcurrentRequests),
+		delivery.WithLogSampleRate(cfg.LogSampleRate),
+		delivery.WithCompression(cfg.HTTPServer.CompressionEnabled),
+		delivery.WithServerTiming(cfg.HTTPServer.ServerTimingEnabled),
+		delivery.WithCORSMaxAge(cfg.HTTPServer.CORSMaxAge),
+		delivery.WithCORSExposedHeaders(cfg.HTTPServer.CORSExposedHeaders...),
+		delivery.WithAdminToken(cfg.Admin.Token),
+		delivery.WithJWTAuth(tokenVerifier, cfg.Auth.Operations...),
+		delivery.WithLinkCheck(cfg.LinkCheck.Enabled),
+		delivery.WithNotFoundRedirect(cfg.NotFoundRedirectURL),
+		delivery.WithExpiredRedirect(cfg.ExpiredRedirectURL),
+		delivery.WithExpiredPage(cfg.ExpiredPage),
+		delivery.WithInterstitial(cfg.Interstitial.Enabled, cfg.Interstitial.Delay),
+		delivery.WithRootRedirect(cfg.RootRedirectURL),
+		delivery.WithBaseURL(cfg.BaseURL),
+		delivery.WithShortCodeLength(cfg.ShortCodeLength),
+		delivery.WithRedirectCacheMaxAge(cfg.RedirectCacheMaxAge),
+		delivery.WithReadOnly(cfg.ReadOnly),
+		delivery.WithFeatures(cfg.Features),
+		delivery.WithMaxBatchSize(cfg.MaxBatchSize),
+		delivery.WithListDefaults(cfg.DefaultListLimit, cfg.DefaultListSort),
+		delivery.WithPrettyJSON(cfg.Env == config.EnvDev),
+		delivery.WithMetricsHandler(promhttp.Handler()),
+		delivery.WithMetricsRegisterer(prometheus.DefaultRegisterer),
+		delivery.WithReadinessCheck(readinessCheck),
+	}
 
-	server := &http.Server{
-		Addr:           cfg.HTTPServer.Addr(),
-		Handler:        r,
-		ReadTimeout:    cfg.HTTPServer.ReadTimeout,
-		WriteTimeout:   cfg.HTTPServer.WriteTimeout,
-		IdleTimeout:    cfg.HTTPServer.IdleTimeout,
-		MaxHeaderBytes: cfg.HTTPServer.MaxHeaderBytes,
-		BaseContext: func(_ net.Listener) context.Context {
-			return ctx
-		},
+	servers := make([]*http.Server, 0, 2)
+
+	if cfg.HTTPServer.AdminPort != 0 {
+		r, adminRouter := delivery.NewRouters(logger, urlUseCase, routerOpts...)
+		servers = append(servers,
+			newServer(ctx, cfg, cfg.HTTPServer.Addr(), r, tlsConfig.Clone()),
+			newServer(ctx, cfg, cfg.HTTPServer.AdminAddr(), adminRouter, tlsConfig.Clone()),
+		)
+	} else {
+		r := delivery.NewRouter(logger, urlUseCase, routerOpts...)
+		servers = append(servers, newServer(ctx, cfg, cfg.HTTPServer.Addr(), r, tlsConfig))
 	}
 
 	g, ctx := errgroup.WithContext(ctx)
 
+	for _, server := range servers {
+		g.Go(func() error {
+			var err error
+
+			switch cfg.Env {
+			case config.EnvProd:
+				err = server.ListenAndServeTLS(cfg.HTTPServer.CertFile, cfg.HTTPServer.KeyFile)
+			default:
+				err = server.ListenAndServe()
+			}
+
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("%s: server error occurred on %s: %w", op, server.Addr, err)
+			}
+
+			return nil
+		})
+	}
+
 	g.Go(func() error {
-		var err error
+		ticker := time.NewTicker(cfg.Sweeper.Interval)
+		defer ticker.Stop()
 
-		switch cfg.Env {
-		case config.EnvProd:
-			err = server.ListenAndServeTLS(cfg.HTTPServer.CertFile, cfg.HTTPServer.KeyFile)
-		default:
-			err = server.ListenAndServe()
-		}
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+				n, err := urlUseCase.PurgeExpired(ctx)
+				if err != nil {
+					logger.Error("failed to purge expired urls", slog.Any("err", err), slog.Int64("count", n))
+					continue
+				}
 
-		if err != nil && !errors.Is(err, http.ErrServerClosed) {
-			return fmt.Errorf("%s: server error occurred: %w", op, err)
+				if n > 0 {
+					logger.Info("purged expired urls", slog.Int64("count", n))
+				}
+			}
 		}
-
-		return nil
 	})
 
 	g.Go(func() error {
 		<-ctx.Done()
 
-		if err := server.Shutdown(context.Background()); err != nil {
-			return fmt.Errorf("%s: failed to shutdown server: %w", op, err)
+		var errs []error
+
+		for _, server := range servers {
+			if err := server.Shutdown(context.Background()); err != nil {
+				errs = append(errs, fmt.Errorf("%s: failed to shutdown server on %s: %w", op, server.Addr, err))
+			}
 		}
 
-		return nil
+		return errors.Join(errs...)
 	})
 
 	return g.Wait()
 }
 
-// setupLogger configures and returns an httplog.Logger based on the provided environment.
-func setupLogger(env string) *httplog.Logger {
+// newReadinessCheck returns the check run on /readyz, which pings the database and,
+// if configured, verifies that the migrations applied to it are up to date.
+func newReadinessCheck(cfg *config.Config, db *sqlx.DB) (func(ctx context.Context) error, error) {
+	if !cfg.Readiness.CheckMigrations {
+		return db.PingContext, nil
+	}
+
+	checker, err := postgres.NewMigrationChecker(db, migrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if err := db.PingContext(ctx); err != nil {
+			return err
+		}
+
+		return checker.Check(ctx)
+	}, nil
+}
+
+// newTokenVerifier returns the verifier of the JWTs required on the operations of the configuration,
+// accepting HS256 tokens if a secret is set and RS256 tokens if a public key file or JWKS URL is set.
+// The keys of the JWKS URL are fetched with the client until ctx is done.
+func newTokenVerifier(ctx context.Context, cfg config.Auth, client *http.Client) (*jwt.Verifier, error) {
+	var opts []jwt.Option
+
+	if cfg.JWTSecret != "" {
+		opts = append(opts, jwt.WithHMACSecret([]byte(cfg.JWTSecret)))
+	}
+
+	if cfg.JWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read public key: %w", err)
+		}
+
+		key, err := jwt.ParseRSAPublicKey(data)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, jwt.WithRSAPublicKey(key))
+	}
+
+	if cfg.JWKSURL != "" {
+		opts = append(opts, jwt.WithJWKS(cfg.JWKSURL, client))
+	}
+
+	return jwt.New(ctx, opts...)
+}
+
+// newServer creates an HTTP server listening on addr with the timeouts and protocol settings of the configuration.
+// The base context of the server's requests is ctx, and tlsConfig is used when the server serves TLS.
+func newServer(ctx context.Context, cfg *config.Config, addr string, h http.Handler, tlsConfig *tls.Config) *http.Server {
+	if cfg.HTTPServer.H2C {
+		h = h2c.NewHandler(h, &http2.Server{})
+	}
+
+	server := &http.Server{
+		Addr:           addr,
+		Handler:        h,
+		ReadTimeout:    cfg.HTTPServer.ReadTimeout,
+		WriteTimeout:   cfg.HTTPServer.WriteTimeout,
+		IdleTimeout:    cfg.HTTPServer.IdleTimeout,
+		MaxHeaderBytes: cfg.HTTPServer.MaxHeaderBytes,
+		TLSConfig:      tlsConfig,
+		BaseContext: func(_ net.Listener) context.Context {
+			return ctx
+		},
+	}
+
+	if !cfg.HTTPServer.HTTP2 {
+		// A non-nil empty map disables HTTP/2 negotiation over TLS.
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	return server
+}
+
+// setupLogger configures and returns an httplog.Logger writing to w. The level and format
+// are derived from the environment, unless they are explicitly set in the configuration.
+func setupLogger(cfg *config.Config, w io.Writer) *httplog.Logger {
 	opt := httplog.Options{
 		LogLevel:        slog.LevelDebug,
 		Concise:         true,
 		RequestHeaders:  true,
 		ResponseHeaders: true,
+		Writer:          w,
 	}
 
-	switch env {
+	switch cfg.Env {
 	case config.EnvStage:
 		opt.JSON = true
 	case config.EnvProd:
@@ -100,8 +386,17 @@ func setupLogger(env string) *httplog.Logger {
 		opt.JSON = true
 	}
 
+	if cfg.LogLevel != "" {
+		// The level is validated when the configuration is loaded.
+		opt.LogLevel, _ = config.ParseLogLevel(cfg.LogLevel)
+	}
+
+	if cfg.LogFormat != "" {
+		opt.JSON = cfg.LogFormat == config.LogFormatJSON
+	}
+
 	logger := httplog.NewLogger("url-shortener", opt)
-	logger.Logger = logger.With(slog.String("env", env))
+	logger.Logger = logger.With(slog.String("env", cfg.Env))
 
 	return logger
 }
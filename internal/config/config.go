@@ -3,8 +3,19 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -15,35 +26,192 @@ const (
 	EnvStage = "stage"
 	EnvProd  = "prod"
 
-	defaultShortCodeLength = 7
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+
+	ShortCodeGeneratorNanoID   = "nanoid"
+	ShortCodeGeneratorSequence = "sequence"
+
+	ShortCodeEncodingBase62 = "base62"
+	ShortCodeEncodingBase58 = "base58"
+	ShortCodeEncodingHex    = "hex"
+
+	TrackingModeColumn = "column"
+	TrackingModeEvents = "events"
+
+	AliasConflictError  = "error"
+	AliasConflictSuffix = "suffix"
+
+	DBDriverPostgres = "postgres"
+	DBDriverMemory   = "memory"
+
+	defaultShortCodeLength    = 7
+	defaultMaxShortCodeLength = 16
+	// defaultMinCustomShortCodeLength keeps custom short codes from being trivially guessable.
+	defaultMinCustomShortCodeLength = 4
+	// minIPHashSaltLength keeps hashed IP addresses from being reversed by hashing the whole address space.
+	minIPHashSaltLength = 16
+	// maxShortCodeLength is the maximum length of short codes that can be stored in the database.
+	maxShortCodeLength = 50
 )
 
+// codePrefixRegexp matches the characters allowed in short codes.
+var codePrefixRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+// authOperations are the names of the operations of the public API that can require a token.
+var authOperations = []string{
+	"add_alias", "clone", "deactivate", "exists", "get_access_counts", "get_config", "get_stats", "list", "lookup",
+	"redirect", "rename", "reserve", "resolve", "shorten", "update", "upsert",
+}
+
+// features are the names of the features of the API that can be disabled.
+var features = []string{"batch", "list", "redirect", "stats"}
+
+// listSorts are the orders URLs can be listed in.
+var listSorts = []string{"oldest", "newest", "short_code"}
+
+// maxListLimit is the maximum number of URLs listed by a single request.
+const maxListLimit = 100
+
+// domainPatternRegexp matches domain names, optionally prefixed with "*." to match their subdomains.
+var domainPatternRegexp = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.?$`)
+
 // Config represents the application's configuration.
+// DBDriver selects between storing URLs in PostgreSQL and in memory, which needs no database but loses
+// all URLs on restart, e.g. for evaluating the service. The postgres settings are ignored in memory.
+// StartupDBRetries is the number of times connecting to the database is retried on startup, e.g. while it is
+// still starting, waiting StartupDBRetryInterval before the first retry and twice as long before each next one.
+// LogLevel and LogFormat override the logging defaults derived from Env when set.
+// LogSampleRate logs only 1 in LogSampleRate successful read requests, e.g. redirects; failed requests
+// and requests modifying data are always logged.
+// NotFoundRedirectURL is the URL requests to follow unknown short codes are redirected to.
+// ExpiredRedirectURL is the URL requests to follow expired short codes are redirected to, and ExpiredPage
+// answers them with a "this link has expired" page instead; both take precedence over NotFoundRedirectURL.
+// BaseURL is the public URL of the service, e.g. https://sho.rt, which links in responses are built from.
+// AllowedDomains restricts original URLs to the given domains if set, and BlockedDomains rejects original URLs
+// pointing at the given domains. Domains prefixed with "*." match any of their subdomains.
+// AllowSelfLinks allows original URLs pointing at the host of BaseURL, which are rejected by default
+// since their short codes would redirect in a loop.
+// CollapseURLSlashes collapses runs of slashes in the path of original URLs before they are saved.
+// RedirectCacheMaxAge is how long redirects to original URLs may be cached, zero disables caching.
+// RootRedirectURL is the URL requests to the root path are redirected to instead of getting the service banner.
+// ReadOnly starts the service in read-only mode, in which write endpoints are rejected.
+// Features enables or disables endpoints by feature name, e.g. "redirect"; features are enabled unless disabled.
+// MaxBatchSize is the maximum number of short codes of the batch lookup and access count requests.
+// DefaultListLimit and DefaultListSort are the number of URLs listed and their order if clients don't specify them.
+// ClickDebounce is the window in which repeated clicks from the same IP address are counted once.
+// TrackingMode selects between counting accesses in the access_count column and aggregating access events.
+// TrackCreators stores the IP address and user agent of the client that shortened a URL with it for investigating abuse.
+// HashIPs replaces IP addresses with their HMAC-SHA256 keyed with IPHashSalt wherever they are remembered for analytics.
+// HideInactiveStats reports the statistics of deactivated URLs as not found.
+// MaxShortCodeLength caps the length short codes grow to when generated short codes conflict.
+// MinCustomShortCodeLength is the minimum length of custom short codes chosen by users, e.g. vanity aliases.
+// AliasConflict selects between rejecting custom short codes that exist and making them unique with a suffix.
+// ShortCodeTiers maps tiers, e.g. plans, to the length of the short codes generated for URLs shortened with them.
+// ShortCodeGenerator selects between random nanoid codes and sequential codes backed by a database sequence.
+// ShortCodeEncodings are the encodings clients may request random short codes to be generated in instead.
 type Config struct {
-	Env             string `yaml:"env"`
-	ShortCodeLength int    `yaml:"short_code_length"`
-	HTTPServer      `yaml:"http_server"`
-	Postgres        `yaml:"postgres"`
+	Env                      string          `yaml:"env"`
+	DBDriver                 string          `yaml:"db_driver"`
+	StartupDBRetries         int             `yaml:"startup_db_retries"`
+	StartupDBRetryInterval   time.Duration   `yaml:"startup_db_retry_interval"`
+	ShortCodeLength          int             `yaml:"short_code_length"`
+	MaxShortCodeLength       int             `yaml:"max_short_code_length"`
+	MinCustomShortCodeLength int             `yaml:"min_custom_short_code_length"`
+	AliasConflict            string          `yaml:"alias_conflict"`
+	ShortCodeTiers           map[string]int  `yaml:"short_code_tiers"`
+	ShortCodeGenerator       string          `yaml:"short_code_generator"`
+	ShortCodeEncodings       []string        `yaml:"short_code_encodings"`
+	CodePrefix               string          `yaml:"code_prefix"`
+	BaseURL                  string          `yaml:"base_url"`
+	NotFoundRedirectURL      string          `yaml:"not_found_redirect_url"`
+	ExpiredRedirectURL       string          `yaml:"expired_redirect_url"`
+	ExpiredPage              bool            `yaml:"expired_page"`
+	RootRedirectURL          string          `yaml:"root_redirect_url"`
+	RedirectCacheMaxAge      time.Duration   `yaml:"redirect_cache_max_age"`
+	AllowedDomains           []string        `yaml:"allowed_domains"`
+	BlockedDomains           []string        `yaml:"blocked_domains"`
+	AllowSelfLinks           bool            `yaml:"allow_self_links"`
+	CollapseURLSlashes       bool            `yaml:"collapse_url_slashes"`
+	ReadOnly                 bool            `yaml:"read_only"`
+	Features                 map[string]bool `yaml:"features"`
+	MaxBatchSize             int             `yaml:"max_batch_size"`
+	DefaultListLimit         int             `yaml:"default_list_limit"`
+	DefaultListSort          string          `yaml:"default_list_sort"`
+	ClickDebounce            time.Duration   `yaml:"click_debounce"`
+	TrackingMode             string          `yaml:"tracking_mode"`
+	TrackCreators            bool            `yaml:"track_creators"`
+	HashIPs                  bool            `yaml:"hash_ips"`
+	IPHashSalt               string          `yaml:"ip_hash_salt"`
+	HideInactiveStats        bool            `yaml:"hide_inactive_stats"`
+	LogLevel                 string          `yaml:"log_level"`
+	LogFormat                string          `yaml:"log_format"`
+	LogFile                  string          `yaml:"log_file"`
+	LogSampleRate            int             `yaml:"log_sample_rate"`
+	HTTPServer               `yaml:"http_server"`
+	Swagger                  `yaml:"swagger"`
+	GeoIP                    `yaml:"geoip"`
+	Reservation              `yaml:"reservation"`
+	Interstitial             `yaml:"interstitial"`
+	CreationCooldown         `yaml:"creation_cooldown"`
+	Sweeper                  `yaml:"sweeper"`
+	Admin                    `yaml:"admin"`
+	Auth                     `yaml:"auth"`
+	Readiness                `yaml:"readiness"`
+	LinkCheck                `yaml:"link_check"`
+	OutboundHTTP             `yaml:"outbound_http"`
+	Postgres                 `yaml:"postgres"`
 }
 
 // HTTPServer contains the configuration for the HTTP server.
+// MaxConcurrentRequests limits the number of requests served at a time, zero means no limit.
+// AdminPort is the port the metrics, health check and admin endpoints are served on instead of Port,
+// so that they can be firewalled off from the public API. They are served on Port if AdminPort is zero.
+// CompressionEnabled compresses JSON and text responses with gzip or deflate for clients that accept it.
+// ServerTimingEnabled adds a Server-Timing header with the database and total time to the responses.
+// CORSMaxAge is how long browsers may cache CORS preflight responses, and CORSExposedHeaders are the response
+// headers browser scripts of other origins may read.
+// MinTLSVersion and CipherSuites harden the TLS connections served in the prod env, see TLSConfig.
 type HTTPServer struct {
-	Port           int           `yaml:"port"`
-	ReadTimeout    time.Duration `yaml:"read_timeout"`
-	WriteTimeout   time.Duration `yaml:"write_timeout"`
-	IdleTimeout    time.Duration `yaml:"idle_timeout"`
-	MaxHeaderBytes int           `yaml:"max_header_bytes"`
-	CertFile       string        `yaml:"cert_file"`
-	KeyFile        string        `yaml:"key_file"`
+	Port                  int           `yaml:"port"`
+	AdminPort             int           `yaml:"admin_port"`
+	ReadTimeout           time.Duration `yaml:"read_timeout"`
+	WriteTimeout          time.Duration `yaml:"write_timeout"`
+	IdleTimeout           time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes        int           `yaml:"max_header_bytes"`
+	RequestTimeout        time.Duration `yaml:"request_timeout"`
+	MaxConcurrentRequests int           `yaml:"max_concurrent_requests"`
+	CompressionEnabled    bool          `yaml:"compression_enabled"`
+	ServerTimingEnabled   bool          `yaml:"server_timing_enabled"`
+	CORSMaxAge            time.Duration `yaml:"cors_max_age"`
+	CORSExposedHeaders    []string      `yaml:"cors_exposed_headers"`
+	HTTP2                 bool          `yaml:"http2"`
+	H2C                   bool          `yaml:"h2c"`
+	TrustedProxies        []string      `yaml:"trusted_proxies"`
+	CertFile              string        `yaml:"cert_file"`
+	KeyFile               string        `yaml:"key_file"`
+	MinTLSVersion         string        `yaml:"min_tls_version"`
+	CipherSuites          []string      `yaml:"cipher_suites"`
 }
 
 // defaultHTTPServer holds the default settings for the HTTP server.
 var defaultHTTPServer = HTTPServer{
-	Port:           8080,
-	ReadTimeout:    5 * time.Second,
-	WriteTimeout:   10 * time.Second,
-	IdleTimeout:    time.Minute,
-	MaxHeaderBytes: 1 << 20,
+	Port:               8080,
+	ReadTimeout:        5 * time.Second,
+	WriteTimeout:       10 * time.Second,
+	IdleTimeout:        time.Minute,
+	MaxHeaderBytes:     1 << 20,
+	RequestTimeout:     8 * time.Second,
+	CORSMaxAge:         24 * time.Hour,
+	CORSExposedHeaders: []string{"Location", "Link", "X-Request-ID"},
+	HTTP2:              true,
+	MinTLSVersion:      "1.2",
+}
+
+// tlsVersions maps the supported values of min_tls_version to TLS versions.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
 }
 
 // Addr returns the address the HTTP server will bind to, formatted as <:port>.
@@ -51,10 +219,188 @@ func (s *HTTPServer) Addr() string {
 	return fmt.Sprintf(":%d", s.Port)
 }
 
+// AdminAddr returns the address the admin HTTP server will bind to, formatted as <:port>.
+func (s *HTTPServer) AdminAddr() string {
+	return fmt.Sprintf(":%d", s.AdminPort)
+}
+
+// TrustedProxyPrefixes parses the CIDR ranges of the trusted proxies.
+func (s *HTTPServer) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(s.TrustedProxies))
+
+	for _, proxy := range s.TrustedProxies {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
+	}
+
+	return prefixes, nil
+}
+
+// TLSConfig builds the TLS configuration of the HTTP server from the minimum TLS version and cipher suites.
+// Cipher suites are given by their IANA names, e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, and only secure
+// suites are accepted. They apply to TLS 1.2 only, since the TLS 1.3 suites aren't configurable. If no cipher
+// suites are set, the Go defaults are used.
+func (s *HTTPServer) TLSConfig() (*tls.Config, error) {
+	version, ok := tlsVersions[s.MinTLSVersion]
+	if !ok {
+		return nil, fmt.Errorf("unsupported tls version %q, must be 1.2 or 1.3", s.MinTLSVersion)
+	}
+
+	cfg := &tls.Config{MinVersion: version}
+
+	if len(s.CipherSuites) == 0 {
+		return cfg, nil
+	}
+
+	ids := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		ids[suite.Name] = suite.ID
+	}
+
+	for _, name := range s.CipherSuites {
+		id, ok := ids[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+
+	return cfg, nil
+}
+
+// Swagger contains the configuration for the Swagger UI.
+type Swagger struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+}
+
+// defaultSwagger holds the default settings for the Swagger UI.
+var defaultSwagger = Swagger{
+	Enabled: true,
+	Path:    "/swagger",
+}
+
+// GeoIP contains the configuration for resolving click countries.
+// Country statistics are disabled if DBPath is empty.
+type GeoIP struct {
+	DBPath string `yaml:"db_path"`
+}
+
+// Reservation contains the configuration for short code reservations.
+type Reservation struct {
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// defaultReservation holds the default settings for short code reservations.
+var defaultReservation = Reservation{
+	TTL: 10 * time.Minute,
+}
+
+// Sweeper contains the configuration for the background job that removes
+// expired URLs and reservations.
+type Sweeper struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+// defaultSweeper holds the default settings for the sweeper.
+var defaultSweeper = Sweeper{
+	Interval: time.Minute,
+}
+
+// Admin contains the configuration for the admin endpoints.
+// The admin endpoints are disabled if Token is empty.
+type Admin struct {
+	Token string `yaml:"token"`
+}
+
+// Auth contains the configuration for requiring JWTs issued by an identity provider on the public API.
+// Tokens signed with HS256 are verified with JWTSecret, and tokens signed with RS256 with the PEM-encoded
+// public key in JWTPublicKeyFile or the keys published at JWKSURL. Operations are the names of the operations
+// requiring a token, as in the request logs, e.g. shorten; no operation requires one by default.
+type Auth struct {
+	JWTSecret        string   `yaml:"jwt_secret"`
+	JWTPublicKeyFile string   `yaml:"jwt_public_key_file"`
+	JWKSURL          string   `yaml:"jwks_url"`
+	Operations       []string `yaml:"operations"`
+}
+
+// Readiness contains the configuration for the readiness check on /readyz, which always pings the database.
+// CheckMigrations also reports the service as not ready while migrations are pending, i.e. the database schema
+// is behind the latest migration or a migration failed halfway.
+type Readiness struct {
+	CheckMigrations bool `yaml:"check_migrations"`
+}
+
+// Interstitial contains the configuration for answering requests to follow short codes with a page naming
+// the original URL and linking to it instead of an immediate redirect, e.g. for advertising or compliance.
+// The page redirects to the original URL after Delay if it is positive; otherwise visitors follow the link.
+type Interstitial struct {
+	Enabled bool          `yaml:"enabled"`
+	Delay   time.Duration `yaml:"delay"`
+}
+
+// defaultInterstitial holds the default settings for the interstitial page.
+var defaultInterstitial = Interstitial{
+	Delay: 5 * time.Second,
+}
+
+// CreationCooldown contains the configuration for limiting the number of URLs shortened from the same IP address,
+// which targets spam abuse of the shorten endpoint. At most Limit URLs may be shortened from an IP address within
+// Window. It is disabled if Limit is zero.
+type CreationCooldown struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+// defaultCreationCooldown holds the default settings for the creation cooldown.
+var defaultCreationCooldown = CreationCooldown{
+	Window: time.Hour,
+}
+
+// LinkCheck contains the configuration for checking whether the original URLs are reachable on the admin endpoint.
+// It is disabled by default, since every check sends a request to the destination of each checked URL.
+// Concurrency limits the number of requests sent at a time, and Timeout limits the time waited for each of them.
+type LinkCheck struct {
+	Enabled     bool          `yaml:"enabled"`
+	Concurrency int           `yaml:"concurrency"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// defaultLinkCheck holds the default settings for link checks.
+var defaultLinkCheck = LinkCheck{
+	Concurrency: 10,
+	Timeout:     5 * time.Second,
+}
+
+// OutboundHTTP contains the configuration for the HTTP client shared by the features sending requests
+// to other servers, e.g. link checks and fetching the keys of auth.jwks_url. ConnectTimeout limits the time
+// spent connecting, ReadTimeout the time waited for the response, MaxRedirects the number of redirects
+// followed and MaxBodySize the number of bytes read from a response body.
+type OutboundHTTP struct {
+	ConnectTimeout time.Duration `yaml:"connect_timeout"`
+	ReadTimeout    time.Duration `yaml:"read_timeout"`
+	MaxRedirects   int           `yaml:"max_redirects"`
+	MaxBodySize    int64         `yaml:"max_body_size"`
+}
+
+// defaultOutboundHTTP holds the default settings for outbound HTTP requests.
+var defaultOutboundHTTP = OutboundHTTP{
+	ConnectTimeout: 5 * time.Second,
+	ReadTimeout:    10 * time.Second,
+	MaxRedirects:   10,
+	MaxBodySize:    1 << 20,
+}
+
 // Postgres contains PostgreSQL database connection settings.
 type Postgres struct {
 	User            string        `yaml:"user"`
 	Password        string        `yaml:"password"`
+	PasswordFile    string        `yaml:"password_file"`
 	Host            string        `yaml:"host"`
 	Port            int           `yaml:"port"`
 	DB              string        `yaml:"db"`
@@ -76,15 +422,37 @@ var defaultPostgres = Postgres{
 	MaxOpenConns:    25,
 }
 
+// loadPasswordFile replaces the password with the contents of the password file,
+// e.g. a Docker or Kubernetes secret mount, without the trailing newline.
+func (p *Postgres) loadPasswordFile() error {
+	data, err := os.ReadFile(p.PasswordFile)
+	if err != nil {
+		return fmt.Errorf("failed to read postgres password file: %w", err)
+	}
+
+	p.Password = strings.TrimRight(string(data), "\r\n")
+
+	return nil
+}
+
 // DSN returns a PostgreSQL Data Source Name (DSN) string for connecting to the PostgreSQL database.
+// The user, password and database are escaped, so that they may contain any characters, e.g. ones
+// of a generated password read from PasswordFile.
 func (p *Postgres) DSN() string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
-		p.User, p.Password, p.Host, p.Port, p.DB, p.SSLMode)
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.User, p.Password),
+		Host:     net.JoinHostPort(p.Host, strconv.Itoa(p.Port)),
+		Path:     "/" + p.DB,
+		RawQuery: url.Values{"sslmode": {p.SSLMode}}.Encode(),
+	}
+
+	return u.String()
 }
 
 // Load reads a configuration YAML file from the specified path and loads it into a Config struct.
 // If any fields are missing from the file, default values are assigned using the setDefaults function.
-// It returns a pointer to the Config struct and an error if the loading process fails.
+// The loaded configuration is checked with Validate. It returns a pointer to the Config struct and an error if the loading process fails.
 func Load(path string) (*Config, error) {
 	const op = "config.Load"
 
@@ -101,13 +469,262 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("%s: failed to decode config file: %w", op, err)
 	}
 
+	if cfg.Postgres.PasswordFile != "" {
+		if err := cfg.Postgres.loadPasswordFile(); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: invalid config: %w", op, err)
+	}
+
 	return &cfg, nil
 }
 
+// ParseLogLevel parses a log level name such as "debug", "info", "warn" or "error".
+func ParseLogLevel(s string) (slog.Level, error) {
+	var level slog.Level
+
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return level, fmt.Errorf("invalid log level %q: %w", s, err)
+	}
+
+	return level, nil
+}
+
+// Validate checks that the configuration is complete and consistent. It reports all problems found
+// at once, naming each offending setting by its key in the config file. A trailing "/" of swagger.path is trimmed.
+func (c *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Env == EnvDev || c.Env == EnvStage || c.Env == EnvProd,
+		"env: must be %q, %q or %q, got %q", EnvDev, EnvStage, EnvProd, c.Env)
+	check(c.ShortCodeLength > 0, "short_code_length: must be positive, got %d", c.ShortCodeLength)
+	check(c.MaxShortCodeLength >= c.ShortCodeLength,
+		"max_short_code_length: must not be less than short_code_length, got %d", c.MaxShortCodeLength)
+	check(len(c.CodePrefix)+c.MaxShortCodeLength <= maxShortCodeLength,
+		"code_prefix, max_short_code_length: generated short codes must not be longer than %d characters", maxShortCodeLength)
+	check(c.MinCustomShortCodeLength > 0 && c.MinCustomShortCodeLength <= maxShortCodeLength,
+		"min_custom_short_code_length: must be between 1 and %d, got %d", maxShortCodeLength, c.MinCustomShortCodeLength)
+	check(c.AliasConflict == AliasConflictError || c.AliasConflict == AliasConflictSuffix,
+		"alias_conflict: must be %q or %q, got %q", AliasConflictError, AliasConflictSuffix, c.AliasConflict)
+	for _, tier := range slices.Sorted(maps.Keys(c.ShortCodeTiers)) {
+		l := c.ShortCodeTiers[tier]
+		check(tier != "", "short_code_tiers: tier names must not be empty")
+		check(l > 0 && l <= c.MaxShortCodeLength,
+			"short_code_tiers: length of tier %q must be between 1 and max_short_code_length, got %d", tier, l)
+	}
+	for _, feature := range slices.Sorted(maps.Keys(c.Features)) {
+		check(slices.Contains(features, feature),
+			"features: must be one of %s, got %q", strings.Join(features, ", "), feature)
+	}
+	check(c.MaxBatchSize > 0, "max_batch_size: must be positive, got %d", c.MaxBatchSize)
+	check(c.DefaultListLimit > 0 && c.DefaultListLimit <= maxListLimit,
+		"default_list_limit: must be between 1 and %d, got %d", maxListLimit, c.DefaultListLimit)
+	check(slices.Contains(listSorts, c.DefaultListSort),
+		"default_list_sort: must be one of %s, got %q", strings.Join(listSorts, ", "), c.DefaultListSort)
+	check(c.ShortCodeGenerator == ShortCodeGeneratorNanoID || c.ShortCodeGenerator == ShortCodeGeneratorSequence,
+		"short_code_generator: must be %q or %q, got %q",
+		ShortCodeGeneratorNanoID, ShortCodeGeneratorSequence, c.ShortCodeGenerator)
+	for _, encoding := range c.ShortCodeEncodings {
+		check(encoding == ShortCodeEncodingBase62 || encoding == ShortCodeEncodingBase58 || encoding == ShortCodeEncodingHex,
+			"short_code_encodings: must be %q, %q or %q, got %q",
+			ShortCodeEncodingBase62, ShortCodeEncodingBase58, ShortCodeEncodingHex, encoding)
+	}
+	check(codePrefixRegexp.MatchString(c.CodePrefix),
+		"code_prefix: only letters, digits, '_' and '-' are allowed, got %q", c.CodePrefix)
+
+	if c.BaseURL != "" {
+		u, err := url.Parse(c.BaseURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"base_url: must be an absolute http or https url, got %q", c.BaseURL)
+	}
+
+	if c.NotFoundRedirectURL != "" {
+		u, err := url.Parse(c.NotFoundRedirectURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"not_found_redirect_url: must be an absolute http or https url, got %q", c.NotFoundRedirectURL)
+	}
+
+	if c.ExpiredRedirectURL != "" {
+		u, err := url.Parse(c.ExpiredRedirectURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"expired_redirect_url: must be an absolute http or https url, got %q", c.ExpiredRedirectURL)
+	}
+	check(c.ExpiredRedirectURL == "" || !c.ExpiredPage,
+		"expired_page: can't be enabled together with expired_redirect_url")
+
+	if c.RootRedirectURL != "" {
+		u, err := url.Parse(c.RootRedirectURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"root_redirect_url: must be an absolute http or https url, got %q", c.RootRedirectURL)
+	}
+
+	for _, domain := range c.AllowedDomains {
+		check(domainPatternRegexp.MatchString(domain),
+			"allowed_domains: must be domain names, optionally prefixed with \"*.\", got %q", domain)
+	}
+
+	for _, domain := range c.BlockedDomains {
+		check(domainPatternRegexp.MatchString(domain),
+			"blocked_domains: must be domain names, optionally prefixed with \"*.\", got %q", domain)
+	}
+
+	check(c.RedirectCacheMaxAge >= 0,
+		"redirect_cache_max_age: must not be negative, got %s", c.RedirectCacheMaxAge)
+	check(c.ClickDebounce >= 0, "click_debounce: must not be negative, got %s", c.ClickDebounce)
+	check(c.TrackingMode == TrackingModeColumn || c.TrackingMode == TrackingModeEvents,
+		"tracking_mode: must be %q or %q, got %q", TrackingModeColumn, TrackingModeEvents, c.TrackingMode)
+
+	if c.HashIPs {
+		check(len(c.IPHashSalt) >= minIPHashSaltLength,
+			"ip_hash_salt: must be at least %d characters long if hash_ips is enabled", minIPHashSaltLength)
+	}
+
+	if c.LogLevel != "" {
+		_, err := ParseLogLevel(c.LogLevel)
+		check(err == nil, "log_level: %v", err)
+	}
+
+	check(c.LogFormat == "" || c.LogFormat == LogFormatText || c.LogFormat == LogFormatJSON,
+		"log_format: must be %q or %q, got %q", LogFormatText, LogFormatJSON, c.LogFormat)
+	check(c.LogSampleRate > 0, "log_sample_rate: must be positive, got %d", c.LogSampleRate)
+	check(c.StartupDBRetries >= 0, "startup_db_retries: must not be negative, got %d", c.StartupDBRetries)
+	check(c.StartupDBRetryInterval > 0,
+		"startup_db_retry_interval: must be positive, got %s", c.StartupDBRetryInterval)
+
+	check(c.HTTPServer.Port > 0 && c.HTTPServer.Port <= 65535,
+		"http_server.port: must be between 1 and 65535, got %d", c.HTTPServer.Port)
+	check(c.HTTPServer.AdminPort >= 0 && c.HTTPServer.AdminPort <= 65535,
+		"http_server.admin_port: must be between 0 and 65535, got %d", c.HTTPServer.AdminPort)
+	check(c.HTTPServer.AdminPort != c.HTTPServer.Port,
+		"http_server.admin_port: must differ from http_server.port, got %d", c.HTTPServer.AdminPort)
+	check(c.HTTPServer.ReadTimeout > 0, "http_server.read_timeout: must be positive, got %s", c.HTTPServer.ReadTimeout)
+	check(c.HTTPServer.WriteTimeout > 0, "http_server.write_timeout: must be positive, got %s", c.HTTPServer.WriteTimeout)
+	check(c.HTTPServer.IdleTimeout > 0, "http_server.idle_timeout: must be positive, got %s", c.HTTPServer.IdleTimeout)
+	check(c.HTTPServer.MaxHeaderBytes > 0,
+		"http_server.max_header_bytes: must be positive, got %d", c.HTTPServer.MaxHeaderBytes)
+	check(c.HTTPServer.RequestTimeout >= 0,
+		"http_server.request_timeout: must not be negative, got %s", c.HTTPServer.RequestTimeout)
+	check(c.HTTPServer.MaxConcurrentRequests >= 0,
+		"http_server.max_concurrent_requests: must not be negative, got %d", c.HTTPServer.MaxConcurrentRequests)
+	check(c.HTTPServer.CORSMaxAge >= 0, "http_server.cors_max_age: must not be negative, got %s", c.HTTPServer.CORSMaxAge)
+	check(!slices.Contains(c.HTTPServer.CORSExposedHeaders, ""), "http_server.cors_exposed_headers: must not be empty")
+
+	if _, err := c.HTTPServer.TrustedProxyPrefixes(); err != nil {
+		check(false, "http_server.trusted_proxies: %v", err)
+	}
+
+	if _, err := c.HTTPServer.TLSConfig(); err != nil {
+		check(false, "http_server.min_tls_version, http_server.cipher_suites: %v", err)
+	}
+
+	if c.Env == EnvProd {
+		check(fileExists(c.HTTPServer.CertFile),
+			"http_server.cert_file: must point to an existing file in %s env, got %q", EnvProd, c.HTTPServer.CertFile)
+		check(fileExists(c.HTTPServer.KeyFile),
+			"http_server.key_file: must point to an existing file in %s env, got %q", EnvProd, c.HTTPServer.KeyFile)
+	}
+
+	check(c.Reservation.TTL > 0, "reservation.ttl: must be positive, got %s", c.Reservation.TTL)
+	check(c.Sweeper.Interval > 0, "sweeper.interval: must be positive, got %s", c.Sweeper.Interval)
+
+	if c.Swagger.Enabled {
+		check(strings.HasPrefix(c.Swagger.Path, "/") && c.Swagger.Path != "/",
+			"swagger.path: must start with \"/\" and not be the root path, got %q", c.Swagger.Path)
+		c.Swagger.Path = strings.TrimSuffix(c.Swagger.Path, "/")
+	}
+
+	if len(c.Auth.Operations) > 0 {
+		check(c.Auth.JWTSecret != "" || c.Auth.JWTPublicKeyFile != "" || c.Auth.JWKSURL != "",
+			"auth.operations: requires auth.jwt_secret, auth.jwt_public_key_file or auth.jwks_url to be set")
+	}
+	for _, operation := range c.Auth.Operations {
+		check(slices.Contains(authOperations, operation),
+			"auth.operations: must be one of %s, got %q", strings.Join(authOperations, ", "), operation)
+	}
+	check(c.Auth.JWTPublicKeyFile == "" || c.Auth.JWKSURL == "",
+		"auth.jwt_public_key_file, auth.jwks_url: must not both be set")
+	if c.Auth.JWKSURL != "" {
+		u, err := url.Parse(c.Auth.JWKSURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"auth.jwks_url: must be an absolute http or https url, got %q", c.Auth.JWKSURL)
+	}
+
+	check(c.Interstitial.Delay >= 0, "interstitial.delay: must not be negative, got %s", c.Interstitial.Delay)
+	check(c.CreationCooldown.Limit >= 0, "creation_cooldown.limit: must not be negative, got %d", c.CreationCooldown.Limit)
+	check(c.CreationCooldown.Window > 0, "creation_cooldown.window: must be positive, got %s", c.CreationCooldown.Window)
+
+	if c.LinkCheck.Enabled {
+		check(c.Admin.Token != "", "link_check.enabled: requires admin.token to be set")
+		check(c.LinkCheck.Concurrency > 0, "link_check.concurrency: must be positive, got %d", c.LinkCheck.Concurrency)
+		check(c.LinkCheck.Timeout > 0, "link_check.timeout: must be positive, got %s", c.LinkCheck.Timeout)
+	}
+
+	check(c.OutboundHTTP.ConnectTimeout > 0,
+		"outbound_http.connect_timeout: must be positive, got %s", c.OutboundHTTP.ConnectTimeout)
+	check(c.OutboundHTTP.ReadTimeout > 0, "outbound_http.read_timeout: must be positive, got %s", c.OutboundHTTP.ReadTimeout)
+	check(c.OutboundHTTP.MaxRedirects >= 0,
+		"outbound_http.max_redirects: must not be negative, got %d", c.OutboundHTTP.MaxRedirects)
+	check(c.OutboundHTTP.MaxBodySize > 0, "outbound_http.max_body_size: must be positive, got %d", c.OutboundHTTP.MaxBodySize)
+
+	check(c.DBDriver == DBDriverPostgres || c.DBDriver == DBDriverMemory,
+		"db_driver: must be %q or %q, got %q", DBDriverPostgres, DBDriverMemory, c.DBDriver)
+
+	if c.DBDriver == DBDriverPostgres {
+		check(c.Postgres.User != "", "postgres.user: is required")
+		check(c.Postgres.DB != "", "postgres.db: is required")
+		check(c.Postgres.Host != "", "postgres.host: is required")
+		check(c.Postgres.Port > 0 && c.Postgres.Port <= 65535,
+			"postgres.port: must be between 1 and 65535, got %d", c.Postgres.Port)
+		check(c.Postgres.MaxOpenConns >= 0, "postgres.max_open_conns: must not be negative, got %d", c.Postgres.MaxOpenConns)
+		check(c.Postgres.MaxIdleConns >= 0, "postgres.max_idle_conns: must not be negative, got %d", c.Postgres.MaxIdleConns)
+	}
+
+	return errors.Join(errs...)
+}
+
+// fileExists reports whether the path points to an existing regular file.
+func fileExists(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
 // setDefaults applies default values to the Config struct.
 func setDefaults(cfg *Config) {
 	cfg.Env = EnvDev
+	cfg.DBDriver = DBDriverPostgres
 	cfg.ShortCodeLength = defaultShortCodeLength
+	cfg.MaxShortCodeLength = defaultMaxShortCodeLength
+	cfg.MinCustomShortCodeLength = defaultMinCustomShortCodeLength
+	cfg.AliasConflict = AliasConflictError
+	cfg.ShortCodeGenerator = ShortCodeGeneratorNanoID
+	cfg.ShortCodeEncodings = []string{ShortCodeEncodingBase62, ShortCodeEncodingBase58, ShortCodeEncodingHex}
+	cfg.TrackingMode = TrackingModeColumn
+	cfg.LogSampleRate = 1
+	cfg.MaxBatchSize = 100
+	cfg.DefaultListLimit = 20
+	cfg.DefaultListSort = "oldest"
+	cfg.StartupDBRetries = 5
+	cfg.StartupDBRetryInterval = time.Second
 	cfg.HTTPServer = defaultHTTPServer
+	cfg.Swagger = defaultSwagger
+	cfg.Reservation = defaultReservation
+	cfg.Sweeper = defaultSweeper
+	cfg.Interstitial = defaultInterstitial
+	cfg.CreationCooldown = defaultCreationCooldown
+	cfg.LinkCheck = defaultLinkCheck
+	cfg.OutboundHTTP = defaultOutboundHTTP
 	cfg.Postgres = defaultPostgres
 }
@@ -0,0 +1,19 @@
+package entity
+
+import "context"
+
+// ownerKey is the context key of the owner of a request.
+type ownerKey struct{}
+
+// ContextWithOwner returns a copy of ctx carrying the ID of the owner of the request,
+// e.g. the subject of the token the request was authenticated with.
+func ContextWithOwner(ctx context.Context, owner string) context.Context {
+	return context.WithValue(ctx, ownerKey{}, owner)
+}
+
+// OwnerFromContext returns the ID of the owner of the request carried by ctx,
+// and reports whether the request was authenticated.
+func OwnerFromContext(ctx context.Context) (string, bool) {
+	owner, ok := ctx.Value(ownerKey{}).(string)
+	return owner, ok
+}
@@ -13,6 +13,28 @@ var (
 	ErrShortCodeExists = errors.New("short code exists")
 	// ErrURLNotFound is returned when a URL with the specified short code cannot be found.
 	ErrURLNotFound = errors.New("url not found")
+	// ErrURLDeactivated is returned when resolving a short code whose URL exists but was deactivated.
+	ErrURLDeactivated = errors.New("url deactivated")
+	// ErrURLExpired is returned when resolving a short code whose URL exists but has expired.
+	ErrURLExpired = errors.New("url expired")
+	// ErrShortCodeTooShort is returned when a custom short code is shorter than the configured minimum length.
+	ErrShortCodeTooShort = errors.New("short code too short")
+	// ErrUnknownTier is returned when shortening a URL with a tier that has no configured short code length.
+	ErrUnknownTier = errors.New("unknown tier")
+	// ErrEncodingNotAllowed is returned when shortening a URL with a short code encoding that isn't allowed.
+	ErrEncodingNotAllowed = errors.New("encoding not allowed")
+	// ErrDomainNotAllowed is returned when the host of an original URL isn't allowed or is blocked.
+	ErrDomainNotAllowed = errors.New("domain not allowed")
+	// ErrSelfReferentialURL is returned when an original URL points at the service itself,
+	// which would make the short code redirect in a loop.
+	ErrSelfReferentialURL = errors.New("self-referential url")
+	// ErrCreationCooldown is returned when shortening a URL from an IP address that has shortened
+	// too many URLs recently.
+	ErrCreationCooldown = errors.New("creation cooldown")
+	// ErrLinkCheckInProgress is returned when checking links while another link check is running.
+	ErrLinkCheckInProgress = errors.New("link check in progress")
+	// ErrDatabaseUnavailable is returned when the database cannot be reached.
+	ErrDatabaseUnavailable = errors.New("database unavailable")
 )
 
 // URL represents a shortened URL.
@@ -20,12 +42,138 @@ type URL struct {
 	ID          int64     // ID is the unique identifier of the URL in the database.
 	ShortCode   string    // ShortCode is the generated code used to shorten the original URL.
 	OriginalURL string    // OriginalURL is the full URL that the short code resolves to.
+	Active      bool      // Active reports whether the short code resolves to the original URL.
+	Tags        []string  // Tags contains the labels attached to the URL, e.g. to group campaign links.
+	Note        string    // Note is a free-form description of what the URL is for, supplied when it is created.
+	Creator     Creator   // Creator identifies the client that created the URL, if it was recorded.
+	Owner       string    // Owner is the subject of the token of the client that created the URL, or empty.
 	URLStats              // URLStats contains statistics about the URL.
 	CreatedAt   time.Time // CreatedAt is the timestamp when the URL was created.
 	UpdatedAt   time.Time // UpdatedAt is the timestamp when the URL was last updated.
+	ExpiresAt   time.Time // ExpiresAt is the timestamp when the URL expires, or zero if it never expires.
+}
+
+// URLUpdate contains the fields of a URL to update at once. Nil fields are left unchanged,
+// so that fields can be set to their zero values, e.g. to remove the note of a URL.
+type URLUpdate struct {
+	OriginalURL *string    // OriginalURL is the new URL that the short code resolves to.
+	ExpiresAt   *time.Time // ExpiresAt is the new expiration timestamp, or zero to never expire.
+	Active      *bool      // Active reports whether the short code resolves to the original URL.
+	Tags        *[]string  // Tags replaces the labels attached to the URL.
+	Note        *string    // Note replaces the description of what the URL is for.
+}
+
+// IsEmpty reports whether the update leaves all fields unchanged.
+func (u URLUpdate) IsEmpty() bool {
+	return u.OriginalURL == nil && u.ExpiresAt == nil && u.Active == nil && u.Tags == nil && u.Note == nil
+}
+
+// ShortenInput contains the parameters for shortening a URL. Only the original URL is required.
+type ShortenInput struct {
+	OriginalURL string   // OriginalURL is the full URL to shorten.
+	Note        string   // Note is a free-form description of what the URL is for.
+	Tags        []string // Tags contains the labels to attach to the URL.
+	UTM         UTM      // UTM contains the UTM parameters to set on the original URL.
+	Tier        string   // Tier selects the length of the short code, or the default length if empty.
+	Encoding    string   // Encoding selects how the short code is generated, or the default encoding if empty.
+	Creator     Creator  // Creator identifies the client shortening the URL.
+}
+
+// Creator identifies the client that created a URL, kept for investigating abuse.
+type Creator struct {
+	IP        string // IP is the IP address of the client, hashed if IP hashing is enabled.
+	UserAgent string // UserAgent is the value of the User-Agent header of the request.
+}
+
+// UTM contains the UTM parameters that are set on the original URL when it is shortened.
+// Empty parameters leave the original URL unchanged.
+type UTM struct {
+	Source   string // Source is the value of the utm_source query parameter, e.g. newsletter.
+	Medium   string // Medium is the value of the utm_medium query parameter, e.g. email.
+	Campaign string // Campaign is the value of the utm_campaign query parameter, e.g. spring_sale.
+	Term     string // Term is the value of the utm_term query parameter.
+	Content  string // Content is the value of the utm_content query parameter.
 }
 
 // URLStats contains statistics related to a shortened URL.
 type URLStats struct {
-	AccessCount int64 // AccessCount is the number of times the shortened URL has been accessed.
+	AccessCount  int64       // AccessCount is the number of times the shortened URL has been accessed.
+	TopReferrers []StatCount // TopReferrers contains the referrer hosts the most clicks came from.
+	UserAgents   []StatCount // UserAgents contains the browser families the most clicks came from.
+	Countries    []StatCount // Countries contains the countries the most clicks came from.
+	Aliases      []URLAlias  // Aliases contains the aliases of the URL along with their own access counts.
+}
+
+// URLAlias is an additional short code of a URL, e.g. for a variant of a campaign. It resolves to the URL,
+// and its accesses count towards the statistics of the URL while being counted for the alias as well.
+type URLAlias struct {
+	ShortCode          string    // ShortCode is the additional short code.
+	CanonicalShortCode string    // CanonicalShortCode is the short code of the URL the alias resolves to.
+	AccessCount        int64     // AccessCount is the number of times the URL has been accessed through the alias.
+	CreatedAt          time.Time // CreatedAt is the timestamp when the alias was created.
+}
+
+// Summary contains aggregate statistics across all shortened URLs.
+type Summary struct {
+	TotalURLs      int64 // TotalURLs is the number of shortened URLs, excluding pending reservations.
+	ActiveURLs     int64 // ActiveURLs is the number of shortened URLs that haven't expired.
+	TotalClicks    int64 // TotalClicks is the sum of access counts of all shortened URLs.
+	CreatedLastDay int64 // CreatedLastDay is the number of shortened URLs created in the last 24 hours.
+	TopURLs        []URL // TopURLs contains the most accessed shortened URLs.
+}
+
+// DomainStats contains aggregate statistics of the shortened URLs pointing at the same destination host.
+type DomainStats struct {
+	Host   string // Host is the lowercase host of the original URLs, without the port.
+	URLs   int64  // URLs is the number of shortened URLs pointing at the host.
+	Clicks int64  // Clicks is the sum of access counts of the shortened URLs pointing at the host.
+}
+
+// ClickDimension identifies an aspect of clicks that is aggregated in URL statistics.
+type ClickDimension string
+
+const (
+	ClickDimensionReferrer  ClickDimension = "referrer"
+	ClickDimensionUserAgent ClickDimension = "user_agent"
+	ClickDimensionCountry   ClickDimension = "country"
+)
+
+// URLSort identifies the order URLs are listed in.
+type URLSort string
+
+const (
+	URLSortOldest    URLSort = "oldest"     // URLSortOldest lists URLs in the order they were created.
+	URLSortNewest    URLSort = "newest"     // URLSortNewest lists the most recently created URLs first.
+	URLSortShortCode URLSort = "short_code" // URLSortShortCode lists URLs alphabetically by short code.
+)
+
+// Click contains information about a single access to a shortened URL.
+type Click struct {
+	Referrer  string // Referrer is the value of the Referer header of the request.
+	UserAgent string // UserAgent is the value of the User-Agent header of the request.
+	IP        string // IP is the IP address of the client, optionally followed by a port.
+}
+
+// AccessEvent is a recorded access to a shortened URL, kept for auditing its traffic.
+type AccessEvent struct {
+	ID         int64     // ID is the unique identifier of the event, increasing with every access.
+	IP         string    // IP is the IP address of the client, hashed if IP hashing is enabled.
+	Referrer   string    // Referrer is the value of the Referer header of the request.
+	AccessedAt time.Time // AccessedAt is the timestamp when the URL was accessed.
+}
+
+// LinkCheck is the result of checking whether the original URL of a shortened URL is reachable.
+type LinkCheck struct {
+	ShortCode   string // ShortCode is the short code of the checked URL.
+	OriginalURL string // OriginalURL is the checked original URL.
+	StatusCode  int    // StatusCode is the status code of the response, or zero if no response was received.
+	Error       string // Error describes why no response was received, e.g. a timeout.
+	Dead        bool   // Dead reports whether the original URL responded with an error status or not at all.
+	Skipped     bool   // Skipped reports whether the original URL wasn't checked because the link check ran out of time.
+}
+
+// StatCount is the number of clicks sharing the same value of a click dimension.
+type StatCount struct {
+	Value string // Value is the value of the click dimension, e.g. a referrer host.
+	Count int64  // Count is the number of clicks with this value.
 }
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxCooldownClients bounds the number of IP addresses remembered by creationCooldown.
+const maxCooldownClients = 100_000
+
+// creationCooldown remembers when URLs were recently created from each IP address
+// to allow at most limit URLs to be created from the same IP address within the window.
+type creationCooldown struct {
+	limit  int
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	created map[string][]time.Time
+}
+
+// newCreationCooldown creates a new instance of creationCooldown with the provided limit and window.
+func newCreationCooldown(limit int, window time.Duration) *creationCooldown {
+	return &creationCooldown{
+		limit:   limit,
+		window:  window,
+		now:     time.Now,
+		created: make(map[string][]time.Time),
+	}
+}
+
+// reserve reports whether a URL may be created from the IP address, and if so, remembers the creation
+// right away, so that concurrent creations from the same IP address can't exceed the limit together.
+// A URL may be created if fewer than limit URLs were created from the IP address less than the window ago.
+// The returned function forgets the creation again and must be called if the URL isn't saved after all,
+// so that failed creations don't count towards the limit.
+func (c *creationCooldown) reserve(ip string) (release func(), ok bool) {
+	now := c.now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	times, found := c.created[ip]
+	if !found && len(c.created) >= maxCooldownClients {
+		c.evict(now)
+	}
+
+	times = c.recent(times, now)
+	if len(times) >= c.limit {
+		c.created[ip] = times
+		return nil, false
+	}
+
+	c.created[ip] = append(times, now)
+
+	return func() { c.release(ip, now) }, true
+}
+
+// release forgets a creation from the IP address at the given time remembered by reserve.
+func (c *creationCooldown) release(ip string, t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	times := c.created[ip]
+
+	// Search from the end, since the creation is most likely among the latest ones.
+	for i := len(times) - 1; i >= 0; i-- {
+		if times[i].Equal(t) {
+			c.created[ip] = slices.Delete(times, i, i+1)
+			return
+		}
+	}
+}
+
+// recent returns the creation times that are less than the window ago. The times are in the order
+// they were remembered, so the older ones are at the start.
+func (c *creationCooldown) recent(times []time.Time, now time.Time) []time.Time {
+	i := 0
+	for i < len(times) && now.Sub(times[i]) >= c.window {
+		i++
+	}
+
+	return times[i:]
+}
+
+// evict forgets the IP addresses without recent creations. If all remembered IP addresses created URLs
+// recently, it forgets all of them to keep the memory bounded, at the cost of letting some clients
+// create more URLs than the limit.
+func (c *creationCooldown) evict(now time.Time) {
+	for ip, times := range c.created {
+		if len(c.recent(times, now)) == 0 {
+			delete(c.created, ip)
+		}
+	}
+
+	if len(c.created) >= maxCooldownClients {
+		clear(c.created)
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"sync"
+	"time"
+)
+
+// maxDebouncedClicks bounds the number of clicks remembered by clickDebouncer.
+const maxDebouncedClicks = 100_000
+
+// clickDebouncer remembers recent clicks to count repeated clicks on the same short code
+// from the same IP address within the window only once.
+type clickDebouncer struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// newClickDebouncer creates a new instance of clickDebouncer with the provided window.
+func newClickDebouncer(window time.Duration) *clickDebouncer {
+	return &clickDebouncer{
+		window: window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// allow reports whether the click on the short code from the IP address should be counted
+// and remembers it if so. Clicks are not counted if a counted click with the same short code
+// and IP address happened less than the window ago.
+func (d *clickDebouncer) allow(shortCode, ip string) bool {
+	key := shortCode + "|" + ip
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.seen[key]; ok && now.Sub(last) < d.window {
+		return false
+	}
+
+	if len(d.seen) >= maxDebouncedClicks {
+		d.evict(now)
+	}
+
+	d.seen[key] = now
+
+	return true
+}
+
+// evict forgets the clicks that are older than the window. If all remembered clicks are recent,
+// it forgets all of them to keep the memory bounded, at the cost of counting some repeats.
+func (d *clickDebouncer) evict(now time.Time) {
+	for key, last := range d.seen {
+		if now.Sub(last) >= d.window {
+			delete(d.seen, key)
+		}
+	}
+
+	if len(d.seen) >= maxDebouncedClicks {
+		clear(d.seen)
+	}
+}
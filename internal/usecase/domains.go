@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"net/url"
+	"strings"
+)
+
+// domainPolicy decides which hosts original URLs may point at. Patterns are host names,
+// optionally prefixed with "*." to match any subdomain of the host: "*.example.com" matches
+// "a.example.com" and "a.b.example.com", but not "example.com" itself.
+type domainPolicy struct {
+	allowed []string
+	blocked []string
+}
+
+// newDomainPolicy creates a new instance of domainPolicy with the provided patterns, compared case-insensitively.
+func newDomainPolicy(allowed, blocked []string) domainPolicy {
+	return domainPolicy{
+		allowed: normalizeDomains(allowed),
+		blocked: normalizeDomains(blocked),
+	}
+}
+
+// allows reports whether the original URL may be shortened. URLs whose host matches a blocked pattern
+// are rejected. If any allowed patterns are set, URLs whose host matches none of them are rejected too.
+func (p domainPolicy) allows(originalURL string) bool {
+	if len(p.allowed) == 0 && len(p.blocked) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return false
+	}
+
+	host := normalizeDomain(u.Hostname())
+
+	if matchesAnyDomain(host, p.blocked) {
+		return false
+	}
+
+	return len(p.allowed) == 0 || matchesAnyDomain(host, p.allowed)
+}
+
+// pointsAtHost reports whether the host of the original URL is the given host, ignoring case and port.
+func pointsAtHost(originalURL, host string) bool {
+	if host == "" {
+		return false
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return false
+	}
+
+	return normalizeDomain(u.Hostname()) == normalizeDomain(host)
+}
+
+// matchesAnyDomain reports whether the host matches any of the patterns.
+func matchesAnyDomain(host string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+
+			continue
+		}
+
+		if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeDomains normalizes each of the domains with normalizeDomain.
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+
+	for _, domain := range domains {
+		normalized = append(normalized, normalizeDomain(domain))
+	}
+
+	return normalized
+}
+
+// normalizeDomain lowercases the domain and strips the trailing dot of fully qualified names.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
+	"golang.org/x/sync/errgroup"
+)
+
+// ErrLinkCheckDisabled is returned when checking links without a link checker.
+var ErrLinkCheckDisabled = errors.New("link check disabled")
+
+// linkCheckPageSize is the number of URLs retrieved at a time when all URLs are checked.
+const linkCheckPageSize = 100
+
+// CheckLinks checks whether the original URLs associated with the given short codes, or of all URLs if none
+// are given, are reachable, and reports the result for each of them. Unknown short codes are left out of the report.
+// URLs responding with a 4xx or 5xx status code or not at all, e.g. in time, are reported as dead.
+// If ctx is done before all URLs are checked, the URLs checked so far are still reported, and the rest
+// are reported as skipped rather than dead. If another link check is running, it returns entity.ErrLinkCheckInProgress.
+func (uc *URLUseCase) CheckLinks(ctx context.Context, shortCodes []string) ([]entity.LinkCheck, error) {
+	const op = "usecase.URLUseCase.CheckLinks"
+
+	if uc.linkChecker == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrLinkCheckDisabled)
+	}
+
+	select {
+	case uc.linkCheckSem <- struct{}{}:
+		defer func() { <-uc.linkCheckSem }()
+	default:
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrLinkCheckInProgress)
+	}
+
+	urls, err := uc.linkCheckURLs(ctx, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get urls: %w", op, err)
+	}
+
+	checks := make([]entity.LinkCheck, len(urls))
+
+	var g errgroup.Group
+	g.SetLimit(max(uc.linkCheckConcurrency, 1))
+
+	for i, url := range urls {
+		g.Go(func() error {
+			checks[i] = uc.checkLink(ctx, url)
+			return nil
+		})
+	}
+
+	_ = g.Wait()
+
+	return checks, nil
+}
+
+// linkCheckURLs retrieves the URLs associated with the short codes in the order they were given,
+// skipping unknown and repeated short codes, or all URLs newest first if no short codes are given.
+// All URLs are paged through by ID, so URLs created or deleted meanwhile don't shift the pages.
+func (uc *URLUseCase) linkCheckURLs(ctx context.Context, shortCodes []string) ([]entity.URL, error) {
+	if len(shortCodes) == 0 {
+		var urls []entity.URL
+		var before int64
+
+		for {
+			page, err := uc.urlRepo.ListBefore(ctx, "", nil, before, linkCheckPageSize)
+			if err != nil {
+				return nil, err
+			}
+
+			urls = append(urls, page...)
+
+			if len(page) < linkCheckPageSize {
+				return urls, nil
+			}
+
+			before = page[len(page)-1].ID
+		}
+	}
+
+	found, err := uc.urlRepo.RetrieveManyByShortCodes(ctx, shortCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]entity.URL, 0, len(found))
+	for _, shortCode := range shortCodes {
+		if url, ok := found[shortCode]; ok {
+			urls = append(urls, *url)
+			delete(found, shortCode)
+		}
+	}
+
+	return urls, nil
+}
+
+// checkLink checks whether the original URL of the URL is reachable. If ctx is done before
+// a response is received, the URL is reported as skipped, since the URL itself may not be at fault.
+func (uc *URLUseCase) checkLink(ctx context.Context, url entity.URL) entity.LinkCheck {
+	check := entity.LinkCheck{
+		ShortCode:   url.ShortCode,
+		OriginalURL: url.OriginalURL,
+	}
+
+	if err := ctx.Err(); err != nil {
+		check.Error = err.Error()
+		check.Skipped = true
+		return check
+	}
+
+	statusCode, err := uc.linkChecker.Check(ctx, url.OriginalURL)
+	if err != nil && ctx.Err() != nil {
+		check.Error = ctx.Err().Error()
+		check.Skipped = true
+		return check
+	}
+	if err != nil {
+		check.Error = err.Error()
+		check.Dead = true
+		return check
+	}
+
+	check.StatusCode = statusCode
+	check.Dead = statusCode >= 400
+
+	return check
+}
@@ -6,26 +6,80 @@ package usecase
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/vadimbarashkov/url-shortener/internal/entity"
-
-	gonanoid "github.com/matoous/go-nanoid/v2"
+	"github.com/vadimbarashkov/url-shortener/pkg/shortcode"
 )
 
 // ErrMaxRetriesExceeded is returned when the maximum number of retries for generating a unique short code is exceeded.
 var ErrMaxRetriesExceeded = errors.New("maximum retries exceeded for generating short code")
 
+// maxStoredShortCodeLength is the maximum length of short codes the repository can store.
+const maxStoredShortCodeLength = 50
+
 // urlRepository defines the interface for interacting with the URL storage layer.
 // Implementations of this interface must provide methods for saving, retrieving,
-// updating, and removing URLs, as well as updating URL statistics.
+// updating, and removing URLs, as well as updating URL statistics. WithTx runs
+// the given function within a transaction spanning all repository calls made
+// with the context it receives.
 type urlRepository interface {
-	Save(ctx context.Context, shortCode, originalURL string) (*entity.URL, error)
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+	Save(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, error)
+	Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error)
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
 	RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error)
+	RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error)
+	Exists(ctx context.Context, shortCode string) (bool, error)
 	RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error)
+	List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit, offset int) ([]entity.URL, error)
+	ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error)
+	IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error
+	RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error)
+	SaveAccessEvent(ctx context.Context, urlID int64, ip, referrer string) error
+	ListAccessEvents(ctx context.Context, urlID, before int64, limit int) ([]entity.AccessEvent, error)
+	CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error)
 	Update(ctx context.Context, shortCode, originalURL string) (*entity.URL, error)
+	Upsert(ctx context.Context, shortCode, originalURL, note string, tags []string, owner string) (*entity.URL, bool, error)
+	Rename(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error)
+	UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error)
+	SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error
+	ResetStats(ctx context.Context, shortCode string) error
+	SaveAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error)
+	RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error)
+	IncrementAliasStats(ctx context.Context, alias string) error
+	ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error)
 	Remove(ctx context.Context, shortCode string) error
+	Summary(ctx context.Context) (*entity.Summary, error)
+	SummaryFromEvents(ctx context.Context) (*entity.Summary, error)
+	DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error)
+	DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error)
+}
+
+// ShortCodeGenerator defines the interface for generating short codes of the requested length.
+// Generators don't have to guarantee uniqueness, since conflicting short codes are retried.
+type ShortCodeGenerator interface {
+	Generate(ctx context.Context, length int) (string, error)
+}
+
+// countryResolver defines the interface for resolving IP addresses to the countries they belong to.
+type countryResolver interface {
+	Country(ip string) (string, error)
+}
+
+// linkChecker defines the interface for checking whether URLs are reachable.
+// It returns the status code of the response, or an error if no response was received.
+type linkChecker interface {
+	Check(ctx context.Context, url string) (int, error)
 }
 
 // URLOption defines a functional option for configuring URLUseCase.
@@ -46,18 +100,224 @@ func WithShortCodeLength(l int) URLOption {
 	}
 }
 
+// WithMaxShortCodeLength sets the length up to which the short code length grows when generated
+// short codes conflict. Once it is reached, conflicting short codes are retried at that length.
+func WithMaxShortCodeLength(l int) URLOption {
+	return func(uc *URLUseCase) {
+		uc.maxShortCodeLength = l
+	}
+}
+
+// WithShortCodeTiers sets the lengths of the short codes generated for URLs shortened with a tier,
+// e.g. shorter short codes for a premium plan. URLs shortened without a tier get the default length.
+func WithShortCodeTiers(tiers map[string]int) URLOption {
+	return func(uc *URLUseCase) {
+		uc.shortCodeTiers = tiers
+	}
+}
+
+// WithShortCodeEncodings sets the generators of the short codes of URLs shortened with an encoding,
+// e.g. "base58" for codes without easily confused characters. Only the given encodings are allowed,
+// and URLs shortened without an encoding get short codes from the default generator.
+func WithShortCodeEncodings(encodings map[string]ShortCodeGenerator) URLOption {
+	return func(uc *URLUseCase) {
+		uc.shortCodeEncodings = encodings
+	}
+}
+
+// WithCreatorTracking sets whether the IP address and user agent of the client that shortened a URL are stored
+// with it for investigating abuse. The IP address is hashed like the IP addresses of clicks if IP hashing is enabled.
+func WithCreatorTracking(enabled bool) URLOption {
+	return func(uc *URLUseCase) {
+		uc.creatorTracking = enabled
+	}
+}
+
+// WithAliasConflictSuffix sets whether a custom short code that already exists is made unique by appending
+// a numeric suffix, e.g. "promo-2", instead of being rejected with entity.ErrShortCodeExists.
+func WithAliasConflictSuffix(enabled bool) URLOption {
+	return func(uc *URLUseCase) {
+		uc.aliasConflictSuffix = enabled
+	}
+}
+
+// WithShortCodeGenerator sets the generator of short codes. Random nanoid codes are generated by default.
+// A shortcode.Counter generates predictable codes, e.g. for tests asserting exact short codes.
+func WithShortCodeGenerator(g ShortCodeGenerator) URLOption {
+	return func(uc *URLUseCase) {
+		uc.shortCodeGenerator = g
+	}
+}
+
+// WithMinCustomShortCodeLength sets the minimum length of custom short codes, which keeps them
+// from being trivially guessable. It doesn't apply to generated short codes.
+func WithMinCustomShortCodeLength(l int) URLOption {
+	return func(uc *URLUseCase) {
+		uc.minCustomShortCodeLength = l
+	}
+}
+
+// WithCodePrefix sets the prefix prepended to every generated short code, e.g. to namespace
+// environments or campaigns. The prefix doesn't count towards the short code length.
+func WithCodePrefix(prefix string) URLOption {
+	return func(uc *URLUseCase) {
+		uc.codePrefix = prefix
+	}
+}
+
+// WithReservationTTL sets the duration after which reserved short codes expire if no original URL is set for them.
+func WithReservationTTL(d time.Duration) URLOption {
+	return func(uc *URLUseCase) {
+		uc.reservationTTL = d
+	}
+}
+
+// WithTopStatsLimit sets the maximum number of entries returned for each click dimension in URL statistics.
+func WithTopStatsLimit(n int) URLOption {
+	return func(uc *URLUseCase) {
+		uc.topStatsLimit = n
+	}
+}
+
+// WithHideInactiveStats sets whether the statistics of deactivated URLs are reported as not found.
+// By default, statistics are reported for deactivated URLs as well.
+func WithHideInactiveStats(enabled bool) URLOption {
+	return func(uc *URLUseCase) {
+		uc.hideInactiveStats = enabled
+	}
+}
+
+// WithDomainPolicy restricts the hosts original URLs may point at. URLs whose host matches a blocked
+// domain are rejected, and so are URLs whose host matches none of the allowed domains if any are set.
+// Domains prefixed with "*." match any of their subdomains. By default, all domains are allowed.
+func WithDomainPolicy(allowed, blocked []string) URLOption {
+	return func(uc *URLUseCase) {
+		uc.domainPolicy = newDomainPolicy(allowed, blocked)
+	}
+}
+
+// WithSelfHost rejects original URLs pointing at the host the service is served on, e.g. the host
+// of its base URL, since their short codes would redirect to themselves in a loop.
+func WithSelfHost(host string) URLOption {
+	return func(uc *URLUseCase) {
+		uc.selfHost = host
+	}
+}
+
+// WithCountryResolver sets the resolver used to aggregate clicks by country.
+// Without a resolver, clicks are not aggregated by country.
+func WithCountryResolver(r countryResolver) URLOption {
+	return func(uc *URLUseCase) {
+		uc.countryResolver = r
+	}
+}
+
+// WithClickDebounce counts repeated clicks on the same short code from the same IP address
+// within the window only once, so that bots and prefetchers don't inflate the statistics.
+// A non-positive window disables debouncing.
+func WithClickDebounce(window time.Duration) URLOption {
+	return func(uc *URLUseCase) {
+		uc.clickDebouncer = nil
+		if window > 0 {
+			uc.clickDebouncer = newClickDebouncer(window)
+		}
+	}
+}
+
+// WithCreationCooldown allows at most limit URLs to be shortened from the same IP address within the window,
+// so that spammers can't flood the service with links. This applies to every way of creating URLs: shortening,
+// cloning, reserving short codes and upserting new custom aliases. URLs created over the limit are rejected with
+// entity.ErrCreationCooldown, and only URLs that were actually saved count towards the limit. This is independent
+// of rate limiting of requests in front of the service, which applies to all endpoints. A non-positive limit
+// or window disables the cooldown.
+func WithCreationCooldown(limit int, window time.Duration) URLOption {
+	return func(uc *URLUseCase) {
+		uc.creationCooldown = nil
+		if limit > 0 && window > 0 {
+			uc.creationCooldown = newCreationCooldown(limit, window)
+		}
+	}
+}
+
+// WithIPHashing replaces the IP addresses of clicks with their HMAC-SHA256 keyed with the salt
+// wherever they are remembered for analytics, e.g. for click debouncing, so that raw IP addresses
+// are never stored. IP addresses are still resolved to countries before they are hashed.
+// An empty salt disables hashing.
+func WithIPHashing(salt []byte) URLOption {
+	return func(uc *URLUseCase) {
+		uc.ipHashSalt = salt
+	}
+}
+
+// WithLinkChecker enables checking whether the original URLs of shortened URLs are reachable with the checker,
+// sending at most concurrency requests at a time. Only one link check runs at a time, since checks send
+// a request to each checked destination. Link checks are disabled by default.
+func WithLinkChecker(c linkChecker, concurrency int) URLOption {
+	return func(uc *URLUseCase) {
+		uc.linkChecker = c
+		uc.linkCheckConcurrency = concurrency
+		uc.linkCheckSem = make(chan struct{}, 1)
+	}
+}
+
+// WithEventTracking sets whether accesses are counted by aggregating the recorded access events
+// instead of incrementing the access count of the URL, which avoids contention on the rows of
+// popular URLs at the cost of slower statistics. Access events are recorded either way.
+func WithEventTracking(enabled bool) URLOption {
+	return func(uc *URLUseCase) {
+		uc.eventTracking = enabled
+	}
+}
+
+// WithSlashCollapsing sets whether runs of slashes in the path of original URLs are collapsed into
+// a single slash before the URLs are saved, e.g. https://example.com//docs becomes https://example.com/docs.
+// Such paths usually come from joining a base URL ending in a slash with a path starting with one.
+// It is disabled by default, since some servers serve different content for the paths.
+func WithSlashCollapsing(enabled bool) URLOption {
+	return func(uc *URLUseCase) {
+		uc.slashCollapsing = enabled
+	}
+}
+
 // URLUseCase is the main structure responsible for handling URL-related operations.
 // It includes configuration for retries, short code length, and a reference to the repository for URL storage.
 type URLUseCase struct {
-	maxRetries      int
-	shortCodeLength int
-	urlRepo         urlRepository
+	maxRetries               int
+	shortCodeLength          int
+	maxShortCodeLength       int
+	minCustomShortCodeLength int
+	aliasConflictSuffix      bool
+	shortCodeTiers           map[string]int
+	shortCodeEncodings       map[string]ShortCodeGenerator
+	codePrefix               string
+	shortCodeGenerator       ShortCodeGenerator
+	reservationTTL           time.Duration
+	topStatsLimit            int
+	hideInactiveStats        bool
+	eventTracking            bool
+	creatorTracking          bool
+	slashCollapsing          bool
+	domainPolicy             domainPolicy
+	selfHost                 string
+	countryResolver          countryResolver
+	linkChecker              linkChecker
+	linkCheckConcurrency     int
+	linkCheckSem             chan struct{}
+	ipHashSalt               []byte
+	clickDebouncer           *clickDebouncer
+	creationCooldown         *creationCooldown
+	urlRepo                  urlRepository
 }
 
 // defaultURLUseCase provides default configuration values for URLUseCase.
 var defaultURLUseCase = URLUseCase{
-	maxRetries:      5,
-	shortCodeLength: 7,
+	maxRetries:               5,
+	shortCodeLength:          7,
+	maxShortCodeLength:       16,
+	minCustomShortCodeLength: 4,
+	reservationTTL:           10 * time.Minute,
+	topStatsLimit:            10,
+	shortCodeGenerator:       shortcode.NanoID{},
 }
 
 // NewURLUseCase creates a new instance of URLUseCase with the provided urlRepository and any functional options.
@@ -73,41 +333,248 @@ func NewURLUseCase(urlRepo urlRepository, opts ...URLOption) *URLUseCase {
 	return &uc
 }
 
-// ShortenURL generates a unique short code for the provided original URL and saves it in the repository.
+// ShortenURL generates a unique short code for the original URL of the input and saves it in the repository
+// with the given note and tags. The UTM parameters are set on the original URL before it is saved.
+// The length of the short code is selected by the tier, or is the default length if the tier is empty.
+// Unknown tiers are rejected with entity.ErrUnknownTier.
+// The short code is generated in the encoding if it is given, and encodings that aren't allowed
+// are rejected with entity.ErrEncodingNotAllowed.
+// Original URLs pointing at domains the domain policy doesn't allow are rejected with entity.ErrDomainNotAllowed,
+// and original URLs pointing at the service itself with entity.ErrSelfReferentialURL, see WithSelfHost.
+// The creator is stored with the URL if creator tracking is enabled, see WithCreatorTracking, and the URL
+// is owned by the owner carried by ctx, see entity.OwnerFromContext.
+// The original URL is normalized before it is saved, see normalizeURL.
+// URLs shortened from an IP address that is cooling down are rejected with entity.ErrCreationCooldown,
+// see WithCreationCooldown.
 // It attempts to generate a unique short code, retrying up to maxRetries times if a conflict occurs.
-func (uc *URLUseCase) ShortenURL(ctx context.Context, originalURL string) (*entity.URL, error) {
+// Each attempt runs within its own transaction, so all writes made while creating the URL are atomic.
+func (uc *URLUseCase) ShortenURL(ctx context.Context, in entity.ShortenInput) (*entity.URL, error) {
 	const op = "usecase.URLUseCase.ShortenURL"
 
 	shortCodeLength := uc.shortCodeLength
+	if in.Tier != "" {
+		l, ok := uc.shortCodeTiers[in.Tier]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrUnknownTier)
+		}
+		shortCodeLength = l
+	}
+
+	generator := uc.shortCodeGenerator
+	if in.Encoding != "" {
+		g, ok := uc.shortCodeEncodings[in.Encoding]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", op, entity.ErrEncodingNotAllowed)
+		}
+		generator = g
+	}
+
+	originalURL, err := withUTM(uc.normalizeURL(in.OriginalURL), in.UTM)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to set utm parameters: %w", op, err)
+	}
+
+	if err := uc.checkOriginalURL(originalURL); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	release, ok := uc.reserveCreation(in.Creator.IP)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrCreationCooldown)
+	}
+
+	owner, _ := entity.OwnerFromContext(ctx)
+
+	url, err := uc.saveWithShortCode(ctx, generator, shortCodeLength, func(ctx context.Context, shortCode string) (*entity.URL, error) {
+		url, err := uc.urlRepo.Save(ctx, shortCode, originalURL, in.Note, in.Tags, owner)
+		if err != nil || !uc.creatorTracking {
+			return url, err
+		}
+
+		creator := in.Creator
+		creator.IP = uc.clickIP(creator.IP)
+
+		if err := uc.urlRepo.SetCreator(ctx, shortCode, creator); err != nil {
+			return nil, err
+		}
+
+		url.Creator = creator
+		return url, nil
+	})
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: failed to shorten url: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// CloneURL creates a URL with a new short code pointing at the same original URL as the URL
+// associated with the given short code, with the same note and tags. The statistics of the new URL start from scratch,
+// and it is owned by the owner carried by ctx.
+// URLs cloned from an IP address that is cooling down are rejected with entity.ErrCreationCooldown.
+func (uc *URLUseCase) CloneURL(ctx context.Context, shortCode, ip string) (*entity.URL, error) {
+	const op = "usecase.URLUseCase.CloneURL"
+
+	source, err := uc.urlRepo.RetrieveByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get source url: %w", op, err)
+	}
+
+	release, ok := uc.reserveCreation(ip)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrCreationCooldown)
+	}
+
+	owner, _ := entity.OwnerFromContext(ctx)
+
+	url, err := uc.saveWithShortCode(ctx, uc.shortCodeGenerator, uc.shortCodeLength, func(ctx context.Context, shortCode string) (*entity.URL, error) {
+		return uc.urlRepo.Save(ctx, shortCode, source.OriginalURL, source.Note, source.Tags, owner)
+	})
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: failed to clone url: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// ReserveShortCode generates a unique short code and reserves it without an original URL,
+// so it can be shown to the user before the URL is submitted. The reservation expires after
+// the reservation TTL unless the original URL is set for it with ModifyURL or UpsertURL by the owner carried by ctx.
+// Short codes reserved from an IP address that is cooling down are rejected with entity.ErrCreationCooldown.
+func (uc *URLUseCase) ReserveShortCode(ctx context.Context, ip string) (*entity.URL, error) {
+	const op = "usecase.URLUseCase.ReserveShortCode"
+
+	release, ok := uc.reserveCreation(ip)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrCreationCooldown)
+	}
+
+	owner, _ := entity.OwnerFromContext(ctx)
+
+	url, err := uc.saveWithShortCode(ctx, uc.shortCodeGenerator, uc.shortCodeLength, func(ctx context.Context, shortCode string) (*entity.URL, error) {
+		return uc.urlRepo.Reserve(ctx, shortCode, time.Now().Add(uc.reservationTTL), owner)
+	})
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: failed to reserve short code: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// reserveCreation counts a URL created from the IP address towards the creation cooldown, and reports
+// whether the IP address is allowed to create it. The returned function must be called to give the creation back
+// if the URL isn't saved after all. Without a creation cooldown, every creation is allowed.
+func (uc *URLUseCase) reserveCreation(ip string) (release func(), ok bool) {
+	if uc.creationCooldown == nil {
+		return func() {}, true
+	}
+
+	return uc.creationCooldown.reserve(uc.clickIP(ip))
+}
+
+// saveWithShortCode generates a unique short code of the given length with the generator, prefixed with the code prefix,
+// and saves a URL with it using the provided function. It retries up to maxRetries times with a longer short code if a conflict occurs,
+// without exceeding maxShortCodeLength. Each attempt runs within its own transaction.
+func (uc *URLUseCase) saveWithShortCode(
+	ctx context.Context,
+	generator ShortCodeGenerator,
+	length int,
+	save func(ctx context.Context, shortCode string) (*entity.URL, error),
+) (*entity.URL, error) {
+	shortCodeLength := length
 
 	for i := 0; i < uc.maxRetries; i++ {
-		shortCode, err := gonanoid.New(shortCodeLength)
+		shortCode, err := generator.Generate(ctx, shortCodeLength)
 		if err != nil {
-			return nil, fmt.Errorf("%s: failed to generate short code: %w", op, err)
+			return nil, fmt.Errorf("failed to generate short code: %w", err)
 		}
 
-		url, err := uc.urlRepo.Save(ctx, shortCode, originalURL)
+		shortCode = uc.codePrefix + shortCode
+
+		var url *entity.URL
+
+		err = uc.urlRepo.WithTx(ctx, func(ctx context.Context) error {
+			var err error
+			url, err = save(ctx, shortCode)
+			return err
+		})
 		if err != nil {
 			if errors.Is(err, entity.ErrShortCodeExists) {
-				shortCodeLength++
+				shortCodeLength = min(shortCodeLength+1, max(uc.maxShortCodeLength, length))
 				continue
 			}
 
-			return nil, fmt.Errorf("%s: failed to shorten url: %w", op, err)
+			return nil, err
 		}
 
 		return url, nil
 	}
 
-	return nil, fmt.Errorf("%s: %w", op, ErrMaxRetriesExceeded)
+	return nil, ErrMaxRetriesExceeded
 }
 
 // ResolveShortCode retrieves the original URL corresponding to the provided short code,
-// updating the access statistics in the process.
-func (uc *URLUseCase) ResolveShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+// updating the access statistics in the process. The referrer and user agent of the click
+// are aggregated by referrer host and browser family to keep the number of counters bounded.
+// If a country resolver is configured, the click is also aggregated by country. Each click is also
+// recorded as an access event with its IP address and referrer, see ListAccessEvents.
+// If click debouncing is enabled, repeated clicks are resolved without updating the statistics.
+// If event tracking is enabled, the access count of the URL isn't incremented, see retrieveForAccess.
+// Aliases resolve to the URL they refer to, counting the access for both the URL and the alias.
+// Short codes whose URL was deactivated or has expired are reported with entity.ErrURLDeactivated
+// and entity.ErrURLExpired rather than entity.ErrURLNotFound.
+func (uc *URLUseCase) ResolveShortCode(ctx context.Context, shortCode string, click entity.Click) (*entity.URL, error) {
 	const op = "usecase.URLUseCase.ResolveShortCode"
 
-	url, err := uc.urlRepo.RetrieveAndUpdateStats(ctx, shortCode)
+	if uc.clickDebouncer != nil && !uc.clickDebouncer.allow(shortCode, uc.clickIP(click.IP)) {
+		url, err := uc.retrieveActive(ctx, shortCode)
+		if errors.Is(err, entity.ErrURLNotFound) {
+			url, err = uc.followAlias(ctx, shortCode, uc.retrieveActive)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to resolve short code: %w", op, err)
+		}
+
+		return url, nil
+	}
+
+	var url *entity.URL
+
+	err := uc.urlRepo.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		url, err = uc.retrieveForAccess(ctx, shortCode)
+		if errors.Is(err, entity.ErrURLNotFound) {
+			url, err = uc.followAlias(ctx, shortCode, uc.retrieveForAccess)
+			if err == nil {
+				err = uc.urlRepo.IncrementAliasStats(ctx, shortCode)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := uc.urlRepo.SaveAccessEvent(ctx, url.ID, uc.clickIP(click.IP), click.Referrer); err != nil {
+			return err
+		}
+
+		if err := uc.urlRepo.IncrementClickStats(ctx, url.ID, entity.ClickDimensionReferrer, referrerHost(click.Referrer)); err != nil {
+			return err
+		}
+
+		if err := uc.urlRepo.IncrementClickStats(ctx, url.ID, entity.ClickDimensionUserAgent, userAgentFamily(click.UserAgent)); err != nil {
+			return err
+		}
+
+		if uc.countryResolver == nil {
+			return nil
+		}
+
+		return uc.urlRepo.IncrementClickStats(ctx, url.ID, entity.ClickDimensionCountry, uc.clickCountry(click.IP))
+	})
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to resolve short code: %w", op, err)
 	}
@@ -115,10 +582,157 @@ func (uc *URLUseCase) ResolveShortCode(ctx context.Context, shortCode string) (*
 	return url, nil
 }
 
+// retrieveForAccess retrieves the URL associated with the short code to record an access to it.
+// Its access count is incremented in the process, unless event tracking is enabled, in which case
+// the access is counted by its access event alone and the access count of the URL is left as is.
+func (uc *URLUseCase) retrieveForAccess(ctx context.Context, shortCode string) (*entity.URL, error) {
+	if uc.eventTracking {
+		return uc.retrieveActive(ctx, shortCode)
+	}
+
+	url, err := uc.urlRepo.RetrieveAndUpdateStats(ctx, shortCode)
+	if err != nil {
+		return nil, uc.explainNotFound(ctx, shortCode, err)
+	}
+
+	return url, nil
+}
+
+// followAlias retrieves the URL the alias with the given short code refers to with retrieve.
+// It returns entity.ErrURLNotFound if the short code isn't an alias either.
+func (uc *URLUseCase) followAlias(
+	ctx context.Context,
+	shortCode string,
+	retrieve func(ctx context.Context, shortCode string) (*entity.URL, error),
+) (*entity.URL, error) {
+	alias, err := uc.urlRepo.RetrieveAlias(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return retrieve(ctx, alias.CanonicalShortCode)
+}
+
+// LookupShortCode retrieves the original URL corresponding to the provided short code like ResolveShortCode,
+// but without updating the access statistics, e.g. for link checkers that shouldn't count as clicks.
+func (uc *URLUseCase) LookupShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+	const op = "usecase.URLUseCase.LookupShortCode"
+
+	url, err := uc.retrieveActive(ctx, shortCode)
+	if errors.Is(err, entity.ErrURLNotFound) {
+		url, err = uc.followAlias(ctx, shortCode, uc.retrieveActive)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to look up short code: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// retrieveActive retrieves the URL associated with the short code without updating its statistics.
+// Deactivated and expired URLs are reported as they are when resolved, see unresolvableReason.
+func (uc *URLUseCase) retrieveActive(ctx context.Context, shortCode string) (*entity.URL, error) {
+	url, err := uc.urlRepo.RetrieveByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unresolvableReason(url, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return url, nil
+}
+
+// explainNotFound replaces entity.ErrURLNotFound returned for a short code that couldn't be resolved
+// with entity.ErrURLDeactivated or entity.ErrURLExpired if the URL exists but is deactivated or expired.
+// Other errors, and short codes that never existed or were purged, are returned as is.
+func (uc *URLUseCase) explainNotFound(ctx context.Context, shortCode string, err error) error {
+	if !errors.Is(err, entity.ErrURLNotFound) {
+		return err
+	}
+
+	if _, retrieveErr := uc.retrieveActive(ctx, shortCode); errors.Is(retrieveErr, entity.ErrURLDeactivated) ||
+		errors.Is(retrieveErr, entity.ErrURLExpired) {
+		return retrieveErr
+	}
+
+	return err
+}
+
+// unresolvableReason returns the error reporting why the URL doesn't resolve at the given time:
+// entity.ErrURLExpired if it has expired, entity.ErrURLDeactivated if it was deactivated, or nil if it resolves.
+func unresolvableReason(url *entity.URL, now time.Time) error {
+	switch {
+	case !url.ExpiresAt.IsZero() && !now.Before(url.ExpiresAt):
+		return entity.ErrURLExpired
+	case !url.Active:
+		return entity.ErrURLDeactivated
+	default:
+		return nil
+	}
+}
+
+// LookupURLs retrieves the URLs associated with the given short codes, keyed by short code,
+// without updating their access statistics. Unknown short codes are missing from the result.
+func (uc *URLUseCase) LookupURLs(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	const op = "usecase.URLUseCase.LookupURLs"
+
+	urls, err := uc.urlRepo.RetrieveManyByShortCodes(ctx, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to look up urls: %w", op, err)
+	}
+
+	return urls, nil
+}
+
+// ListURLs retrieves up to limit URLs in the given order, skipping the first offset ones. If query is not empty,
+// only the URLs whose original URL, short code or note contain it, ignoring case, are retrieved.
+// If tags are given, only the URLs having all of them are retrieved.
+func (uc *URLUseCase) ListURLs(
+	ctx context.Context,
+	query string,
+	tags []string,
+	sort entity.URLSort,
+	limit, offset int,
+) ([]entity.URL, error) {
+	const op = "usecase.URLUseCase.ListURLs"
+
+	urls, err := uc.urlRepo.List(ctx, query, tags, sort, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list urls: %w", op, err)
+	}
+
+	return urls, nil
+}
+
+// ListURLsBefore retrieves up to limit URLs, newest first. If before is positive, only the URLs created
+// before the URL with that ID are retrieved, so that URLs can be iterated page by page with the ID of
+// the last URL of the previous page, even while URLs are created. Filtering works like in ListURLs.
+func (uc *URLUseCase) ListURLsBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	const op = "usecase.URLUseCase.ListURLsBefore"
+
+	urls, err := uc.urlRepo.ListBefore(ctx, query, tags, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list urls: %w", op, err)
+	}
+
+	return urls, nil
+}
+
 // ModifyURL updates the original URL associated with the given short code in the repository.
+// For a reserved short code, it commits the reservation. Like ShortenURL, it rejects original URLs
+// pointing at domains the domain policy doesn't allow with entity.ErrDomainNotAllowed, and original URLs
+// pointing at the service itself with entity.ErrSelfReferentialURL.
 func (uc *URLUseCase) ModifyURL(ctx context.Context, shortCode, originalURL string) (*entity.URL, error) {
 	const op = "usecase.URLUseCase.ModifyURL"
 
+	originalURL = uc.normalizeURL(originalURL)
+
+	if err := uc.checkOriginalURL(originalURL); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	url, err := uc.urlRepo.Update(ctx, shortCode, originalURL)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to modify url: %w", op, err)
@@ -127,6 +741,187 @@ func (uc *URLUseCase) ModifyURL(ctx context.Context, shortCode, originalURL stri
 	return url, nil
 }
 
+// UpsertURL creates a URL with the provided short code as a custom alias if it is free, or modifies
+// the original URL of the URL associated with it like ModifyURL otherwise, and reports whether the URL
+// was created, so that provisioning the same alias repeatedly is idempotent. The note and tags are only
+// set for new URLs. New URLs are owned by the owner carried by ctx, see entity.OwnerFromContext, and existing
+// URLs of other owners are rejected with entity.ErrShortCodeExists, so that nobody can take over the alias
+// of someone else. Short codes shorter than the minimum custom short code length can't be created,
+// only modified, and are rejected with entity.ErrShortCodeTooShort if they don't exist. While the IP address
+// is cooling down, existing URLs can still be modified, but new ones are rejected with entity.ErrCreationCooldown.
+func (uc *URLUseCase) UpsertURL(ctx context.Context, shortCode, originalURL, note string, tags []string, ip string) (*entity.URL, bool, error) {
+	const op = "usecase.URLUseCase.UpsertURL"
+
+	originalURL = uc.normalizeURL(originalURL)
+
+	if err := uc.checkOriginalURL(originalURL); err != nil {
+		return nil, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	owner, _ := entity.OwnerFromContext(ctx)
+
+	if len(shortCode) < uc.minCustomShortCodeLength {
+		url, err := uc.updateOwned(ctx, shortCode, originalURL, owner)
+		if errors.Is(err, entity.ErrURLNotFound) {
+			return nil, false, fmt.Errorf("%s: %w", op, entity.ErrShortCodeTooShort)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: failed to modify url: %w", op, err)
+		}
+
+		return url, false, nil
+	}
+
+	release, ok := uc.reserveCreation(ip)
+	if !ok {
+		url, err := uc.updateOwned(ctx, shortCode, originalURL, owner)
+		if errors.Is(err, entity.ErrURLNotFound) {
+			return nil, false, fmt.Errorf("%s: %w", op, entity.ErrCreationCooldown)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: failed to modify url: %w", op, err)
+		}
+
+		return url, false, nil
+	}
+
+	url, created, err := uc.urlRepo.Upsert(ctx, shortCode, originalURL, note, tags, owner)
+	if err != nil {
+		release()
+		return nil, false, fmt.Errorf("%s: failed to upsert url: %w", op, err)
+	}
+
+	// Updating an existing URL isn't a creation.
+	if !created {
+		release()
+	}
+
+	return url, created, nil
+}
+
+// updateOwned modifies the original URL of the URL associated with the short code like ModifyURL if it has the owner,
+// and otherwise returns an entity.ErrShortCodeExists error. The owner of a URL never changes once it is created,
+// so it can't change between checking it and the update.
+func (uc *URLUseCase) updateOwned(ctx context.Context, shortCode, originalURL, owner string) (*entity.URL, error) {
+	url, err := uc.urlRepo.RetrieveByShortCode(ctx, shortCode)
+	if err != nil && !errors.Is(err, entity.ErrURLNotFound) {
+		return nil, err
+	}
+	if err == nil && url.Owner != owner {
+		return nil, entity.ErrShortCodeExists
+	}
+
+	return uc.urlRepo.Update(ctx, shortCode, originalURL)
+}
+
+// RenameShortCode replaces the short code of an existing URL with the provided one,
+// for example to upgrade a randomly generated code to a custom alias. Custom short codes
+// shorter than the minimum custom short code length are rejected with entity.ErrShortCodeTooShort.
+// If the custom short code exists, it is rejected with entity.ErrShortCodeExists, unless alias conflict
+// suffixes are enabled, in which case the first free code of "code-2", "code-3" and so on is taken
+// within maxRetries attempts. The returned URL carries the short code that was actually set.
+func (uc *URLUseCase) RenameShortCode(ctx context.Context, oldShortCode, newShortCode string) (*entity.URL, error) {
+	const op = "usecase.URLUseCase.RenameShortCode"
+
+	if len(newShortCode) < uc.minCustomShortCodeLength {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeTooShort)
+	}
+
+	url, err := uc.urlRepo.Rename(ctx, oldShortCode, newShortCode)
+
+	for n := 2; uc.aliasConflictSuffix && errors.Is(err, entity.ErrShortCodeExists) && n < uc.maxRetries+2; n++ {
+		url, err = uc.urlRepo.Rename(ctx, oldShortCode, withSuffix(newShortCode, n))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to rename short code: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// withSuffix appends the numeric suffix n to the short code, e.g. "promo-2", shortening the short code
+// if necessary so that the result doesn't exceed maxStoredShortCodeLength.
+func withSuffix(shortCode string, n int) string {
+	suffix := "-" + strconv.Itoa(n)
+	return shortCode[:min(len(shortCode), maxStoredShortCodeLength-len(suffix))] + suffix
+}
+
+// UpdateURL applies the fields set in the update to the URL associated with the given short code
+// and returns the updated URL, leaving the other fields unchanged. A new original URL is normalized
+// and checked like in ModifyURL. Reserved short codes can't be updated, see ModifyURL.
+func (uc *URLUseCase) UpdateURL(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	const op = "usecase.URLUseCase.UpdateURL"
+
+	if update.OriginalURL != nil {
+		originalURL := uc.normalizeURL(*update.OriginalURL)
+
+		if err := uc.checkOriginalURL(originalURL); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		update.OriginalURL = &originalURL
+	}
+
+	url, err := uc.urlRepo.UpdateFields(ctx, shortCode, update)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to update url: %w", op, err)
+	}
+
+	return url, nil
+}
+
+// PurgeExpired removes the URLs and reserved short codes that have expired
+// and returns the number of removed rows.
+func (uc *URLUseCase) PurgeExpired(ctx context.Context) (int64, error) {
+	const op = "usecase.URLUseCase.PurgeExpired"
+
+	n, err := uc.urlRepo.DeleteExpired(ctx, time.Now())
+	if err != nil {
+		return n, fmt.Errorf("%s: failed to delete expired urls: %w", op, err)
+	}
+
+	return n, nil
+}
+
+// ResetStats resets the statistics of the URL associated with the given short code, e.g. when
+// restarting a campaign: its access count, click statistics and access events are cleared.
+func (uc *URLUseCase) ResetStats(ctx context.Context, shortCode string) error {
+	const op = "usecase.URLUseCase.ResetStats"
+
+	if err := uc.urlRepo.ResetStats(ctx, shortCode); err != nil {
+		return fmt.Errorf("%s: failed to reset stats: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddAlias adds an alias to the URL associated with the given short code, e.g. for a variant of a campaign.
+// The alias resolves to the URL, and its accesses count towards the statistics of the URL, see GetURLStats.
+// Like custom short codes, aliases shorter than the minimum custom short code length are rejected
+// with entity.ErrShortCodeTooShort, and aliases that are taken with entity.ErrShortCodeExists, unless
+// alias conflict suffixes are enabled, in which case a suffix is appended like in RenameShortCode.
+// The returned alias carries the short code that was actually added.
+func (uc *URLUseCase) AddAlias(ctx context.Context, shortCode, alias string) (*entity.URLAlias, error) {
+	const op = "usecase.URLUseCase.AddAlias"
+
+	if len(alias) < uc.minCustomShortCodeLength {
+		return nil, fmt.Errorf("%s: %w", op, entity.ErrShortCodeTooShort)
+	}
+
+	a, err := uc.urlRepo.SaveAlias(ctx, shortCode, alias)
+
+	for n := 2; uc.aliasConflictSuffix && errors.Is(err, entity.ErrShortCodeExists) && n < uc.maxRetries+2; n++ {
+		a, err = uc.urlRepo.SaveAlias(ctx, shortCode, withSuffix(alias, n))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to add alias: %w", op, err)
+	}
+
+	return a, nil
+}
+
 // DeactivateURL removes the URL associated with the given short code from the repository, effectively deactivating it.
 func (uc *URLUseCase) DeactivateURL(ctx context.Context, shortCode string) error {
 	const op = "usecase.URLUseCase.DeactivateURL"
@@ -139,7 +934,24 @@ func (uc *URLUseCase) DeactivateURL(ctx context.Context, shortCode string) error
 	return nil
 }
 
+// ShortCodeExists reports whether the short code is taken, including by reservations and disabled URLs,
+// without counting an access to the URL.
+func (uc *URLUseCase) ShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
+	const op = "usecase.URLUseCase.ShortCodeExists"
+
+	exists, err := uc.urlRepo.Exists(ctx, shortCode)
+	if err != nil {
+		return false, fmt.Errorf("%s: failed to check short code: %w", op, err)
+	}
+
+	return exists, nil
+}
+
 // GetURLStats retrieves the URL associated with the given short code along with its usage statistics.
+// The access count of the URL includes the accesses through its aliases, which are reported with
+// their own access counts as well.
+// A URL that has never been accessed is reported with zero statistics rather than as not found,
+// while deactivated URLs are reported as not found if WithHideInactiveStats is enabled.
 func (uc *URLUseCase) GetURLStats(ctx context.Context, shortCode string) (*entity.URL, error) {
 	const op = "usecase.URLUseCase.GetURLStats"
 
@@ -148,5 +960,311 @@ func (uc *URLUseCase) GetURLStats(ctx context.Context, shortCode string) (*entit
 		return nil, fmt.Errorf("%s: failed to get url stats: %w", op, err)
 	}
 
+	if uc.hideInactiveStats && !url.Active {
+		return nil, fmt.Errorf("%s: failed to get url stats: %w", op, entity.ErrURLNotFound)
+	}
+
+	if uc.eventTracking {
+		counts, err := uc.urlRepo.CountAccessEvents(ctx, []int64{url.ID})
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to count access events: %w", op, err)
+		}
+
+		url.AccessCount = counts[url.ID]
+	}
+
+	url.TopReferrers, err = uc.urlRepo.RetrieveClickStats(ctx, url.ID, entity.ClickDimensionReferrer, uc.topStatsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get top referrers: %w", op, err)
+	}
+
+	url.UserAgents, err = uc.urlRepo.RetrieveClickStats(ctx, url.ID, entity.ClickDimensionUserAgent, uc.topStatsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get user agents: %w", op, err)
+	}
+
+	url.Countries, err = uc.urlRepo.RetrieveClickStats(ctx, url.ID, entity.ClickDimensionCountry, uc.topStatsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get countries: %w", op, err)
+	}
+
+	url.Aliases, err = uc.urlRepo.ListAliases(ctx, url.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get aliases: %w", op, err)
+	}
+
 	return url, nil
 }
+
+// ListAccessEvents retrieves up to limit recorded accesses to the URL associated with the given short code,
+// most recent first. If before is positive, only the events recorded before the event with that ID are retrieved.
+// Like statistics, the events of deactivated URLs are reported as not found if WithHideInactiveStats is enabled.
+func (uc *URLUseCase) ListAccessEvents(ctx context.Context, shortCode string, before int64, limit int) ([]entity.AccessEvent, error) {
+	const op = "usecase.URLUseCase.ListAccessEvents"
+
+	url, err := uc.urlRepo.RetrieveByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list access events: %w", op, err)
+	}
+
+	if uc.hideInactiveStats && !url.Active {
+		return nil, fmt.Errorf("%s: failed to list access events: %w", op, entity.ErrURLNotFound)
+	}
+
+	events, err := uc.urlRepo.ListAccessEvents(ctx, url.ID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to list access events: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// GetCreator retrieves the client that created the URL associated with the given short code.
+// The creator is empty if creator tracking was disabled when the URL was shortened.
+func (uc *URLUseCase) GetCreator(ctx context.Context, shortCode string) (*entity.Creator, error) {
+	const op = "usecase.URLUseCase.GetCreator"
+
+	url, err := uc.urlRepo.RetrieveByShortCode(ctx, shortCode)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get url: %w", op, err)
+	}
+
+	return &url.Creator, nil
+}
+
+// GetAccessCounts retrieves the access counts of the URLs associated with the given short codes,
+// keyed by short code, with a single repository call. Unknown short codes are missing from the result,
+// and so are deactivated URLs if WithHideInactiveStats is enabled.
+func (uc *URLUseCase) GetAccessCounts(ctx context.Context, shortCodes []string) (map[string]int64, error) {
+	const op = "usecase.URLUseCase.GetAccessCounts"
+
+	urls, err := uc.urlRepo.RetrieveManyByShortCodes(ctx, shortCodes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get access counts: %w", op, err)
+	}
+
+	if uc.eventTracking {
+		if err := uc.countAccessEvents(ctx, urls); err != nil {
+			return nil, fmt.Errorf("%s: failed to count access events: %w", op, err)
+		}
+	}
+
+	counts := make(map[string]int64, len(urls))
+	for code, url := range urls {
+		if uc.hideInactiveStats && !url.Active {
+			continue
+		}
+
+		counts[code] = url.AccessCount
+	}
+
+	return counts, nil
+}
+
+// countAccessEvents sets the access counts of the URLs to the number of their recorded access events
+// with a single repository call.
+func (uc *URLUseCase) countAccessEvents(ctx context.Context, urls map[string]*entity.URL) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(urls))
+	for _, url := range urls {
+		ids = append(ids, url.ID)
+	}
+
+	counts, err := uc.urlRepo.CountAccessEvents(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		url.AccessCount = counts[url.ID]
+	}
+
+	return nil
+}
+
+// GetSummary retrieves aggregate statistics across all shortened URLs.
+func (uc *URLUseCase) GetSummary(ctx context.Context) (*entity.Summary, error) {
+	const op = "usecase.URLUseCase.GetSummary"
+
+	summaryFn := uc.urlRepo.Summary
+	if uc.eventTracking {
+		summaryFn = uc.urlRepo.SummaryFromEvents
+	}
+
+	summary, err := summaryFn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get summary: %w", op, err)
+	}
+
+	return summary, nil
+}
+
+// GetDomainStats retrieves the number of URLs and clicks per destination host, most clicked first,
+// limited to limit hosts. If domain isn't empty, only the domain and its subdomains are included.
+func (uc *URLUseCase) GetDomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	const op = "usecase.URLUseCase.GetDomainStats"
+
+	domainStatsFn := uc.urlRepo.DomainStats
+	if uc.eventTracking {
+		domainStatsFn = uc.urlRepo.DomainStatsFromEvents
+	}
+
+	stats, err := domainStatsFn(ctx, normalizeDomain(domain), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to get domain statistics: %w", op, err)
+	}
+
+	return stats, nil
+}
+
+// withUTM sets the non-empty UTM parameters on the query of the original URL, overriding any
+// values already present, and keeps the rest of the URL intact. The original URL is returned
+// as is if all UTM parameters are empty.
+func withUTM(originalURL string, utm entity.UTM) (string, error) {
+	if utm == (entity.UTM{}) {
+		return originalURL, nil
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+
+	for key, value := range map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// referrerHost reduces the referrer to its host name without the "www." prefix.
+// Clicks without a referrer are reported as "direct".
+func referrerHost(referrer string) string {
+	if referrer == "" {
+		return "direct"
+	}
+
+	u, err := url.Parse(referrer)
+	if err != nil || u.Hostname() == "" {
+		return "unknown"
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// normalizeURL trims the whitespace surrounding the original URL, e.g. a trailing newline it was pasted with,
+// and collapses runs of slashes in its path if slash collapsing is enabled, see WithSlashCollapsing.
+func (uc *URLUseCase) normalizeURL(originalURL string) string {
+	originalURL = strings.TrimSpace(originalURL)
+	if !uc.slashCollapsing {
+		return originalURL
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil || !strings.Contains(u.Path, "//") {
+		return originalURL
+	}
+
+	u.Path = collapseSlashes(u.Path)
+	u.RawPath = collapseSlashes(u.RawPath)
+
+	return u.String()
+}
+
+// collapseSlashes replaces each run of slashes in the path with a single slash.
+func collapseSlashes(path string) string {
+	for strings.Contains(path, "//") {
+		path = strings.ReplaceAll(path, "//", "/")
+	}
+
+	return path
+}
+
+// checkOriginalURL checks that the original URL may be shortened. It returns entity.ErrDomainNotAllowed
+// if the domain policy doesn't allow its host, and entity.ErrSelfReferentialURL if it points at the service.
+func (uc *URLUseCase) checkOriginalURL(originalURL string) error {
+	if !uc.domainPolicy.allows(originalURL) {
+		return entity.ErrDomainNotAllowed
+	}
+
+	if pointsAtHost(originalURL, uc.selfHost) {
+		return entity.ErrSelfReferentialURL
+	}
+
+	return nil
+}
+
+// clickCountry resolves the IP address of the click to a country code.
+// Clicks whose country cannot be resolved are reported as "unknown".
+func (uc *URLUseCase) clickCountry(ip string) string {
+	country, err := uc.countryResolver.Country(clickHost(ip))
+	if err != nil || country == "" {
+		return "unknown"
+	}
+
+	return country
+}
+
+// clickIP returns the IP address of the click as it is remembered for analytics: without the port,
+// and hashed with HMAC-SHA256 if IP hashing is enabled.
+func (uc *URLUseCase) clickIP(ip string) string {
+	host := clickHost(ip)
+	if len(uc.ipHashSalt) == 0 {
+		return host
+	}
+
+	mac := hmac.New(sha256.New, uc.ipHashSalt)
+	mac.Write([]byte(host))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// clickHost strips the port from the IP address of the click, if present.
+func clickHost(ip string) string {
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		return host
+	}
+
+	return ip
+}
+
+// userAgentFamily reduces the user agent to the family of the browser or client that sent it.
+func userAgentFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case ua == "":
+		return "unknown"
+	case strings.Contains(ua, "bot"), strings.Contains(ua, "crawler"), strings.Contains(ua, "spider"):
+		return "bot"
+	case strings.Contains(ua, "edg/"):
+		return "Edge"
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		return "Opera"
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		return "Firefox"
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"):
+		return "Chrome"
+	case strings.Contains(ua, "safari/"):
+		return "Safari"
+	case strings.Contains(ua, "curl/"):
+		return "curl"
+	default:
+		return "other"
+	}
+}
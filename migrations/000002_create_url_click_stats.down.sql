@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TABLE IF EXISTS url_click_stats;
+
+END;
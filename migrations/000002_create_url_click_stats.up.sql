@@ -0,0 +1,11 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS url_click_stats(
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    dimension VARCHAR(20) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    count BIGINT DEFAULT 0 CHECK (count >= 0),
+    PRIMARY KEY(url_id, dimension, value)
+);
+
+END;
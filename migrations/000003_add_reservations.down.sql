@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urls_expires_at_idx;
+
+DELETE FROM urls WHERE original_url IS NULL;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS expires_at;
+
+ALTER TABLE urls ALTER COLUMN original_url SET NOT NULL;
+
+END;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE urls ALTER COLUMN original_url DROP NOT NULL;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS urls_expires_at_idx ON urls(expires_at) WHERE expires_at IS NOT NULL;
+
+END;
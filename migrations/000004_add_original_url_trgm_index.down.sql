@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urls_original_url_trgm_idx;
+
+END;
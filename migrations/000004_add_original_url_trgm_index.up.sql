@@ -0,0 +1,7 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS urls_original_url_trgm_idx ON urls USING GIN (original_url gin_trgm_ops);
+
+END;
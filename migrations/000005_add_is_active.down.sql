@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS is_active;
+
+END;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT TRUE;
+
+END;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Each value reserves a block of 100 ids for the sequence short code generator,
+-- the increment must match shortCodeIDBlockSize in the postgres repository.
+CREATE SEQUENCE IF NOT EXISTS short_code_id_seq AS BIGINT INCREMENT BY 100 START WITH 1;
+
+END;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urls_tags_idx;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS tags;
+
+END;
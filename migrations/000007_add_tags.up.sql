@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- The GIN index serves the tags @> filter of the URL list.
+CREATE INDEX IF NOT EXISTS urls_tags_idx ON urls USING GIN (tags);
+
+END;
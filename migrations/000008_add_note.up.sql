@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+END;
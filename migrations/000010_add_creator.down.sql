@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE urls DROP COLUMN IF EXISTS creator_user_agent;
+ALTER TABLE urls DROP COLUMN IF EXISTS creator_ip;
+
+END;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS creator_ip VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS creator_user_agent TEXT NOT NULL DEFAULT '';
+
+END;
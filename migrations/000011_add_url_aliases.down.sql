@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS urls_check_short_code_free ON urls;
+DROP TABLE IF EXISTS url_aliases;
+DROP FUNCTION IF EXISTS check_short_code_free();
+
+END;
@@ -0,0 +1,36 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS url_aliases(
+    short_code VARCHAR(50) NOT NULL,
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    access_count BIGINT NOT NULL DEFAULT 0 CHECK (access_count >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY(short_code)
+);
+
+CREATE INDEX IF NOT EXISTS url_aliases_url_id_idx ON url_aliases(url_id);
+
+-- Short codes of URLs and aliases share a namespace, which the unique constraints of the tables
+-- can't enforce, so inserting a short code taken by the other table fails like a unique violation.
+CREATE OR REPLACE FUNCTION check_short_code_free()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_TABLE_NAME = 'urls' AND EXISTS (SELECT 1 FROM url_aliases WHERE short_code = NEW.short_code))
+        OR (TG_TABLE_NAME = 'url_aliases' AND EXISTS (SELECT 1 FROM urls WHERE short_code = NEW.short_code)) THEN
+        RAISE EXCEPTION 'short code "%" exists', NEW.short_code USING ERRCODE = 'unique_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER urls_check_short_code_free
+BEFORE INSERT OR UPDATE OF short_code ON urls
+FOR EACH ROW
+EXECUTE FUNCTION check_short_code_free();
+
+CREATE TRIGGER url_aliases_check_short_code_free
+BEFORE INSERT OR UPDATE OF short_code ON url_aliases
+FOR EACH ROW
+EXECUTE FUNCTION check_short_code_free();
+
+END;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS urls_host_idx;
+ALTER TABLE urls DROP COLUMN IF EXISTS host;
+
+END;
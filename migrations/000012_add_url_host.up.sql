@@ -0,0 +1,10 @@
+BEGIN;
+
+-- host is the lowercase host of the original URL without the port, NULL for reserved short codes.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS host VARCHAR(255) GENERATED ALWAYS AS (
+    LOWER(TRIM(BOTH '[]' FROM SUBSTRING(original_url FROM '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/?#]*@)?(\[[^]/?#]*\]|[^:/?#]*)')))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS urls_host_idx ON urls(host);
+
+END;
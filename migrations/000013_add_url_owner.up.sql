@@ -0,0 +1,6 @@
+BEGIN;
+
+-- owner_id is the subject of the token of the client that created the URL, empty if it was created without one.
+ALTER TABLE urls ADD COLUMN IF NOT EXISTS owner_id VARCHAR(255) NOT NULL DEFAULT '';
+
+END;
@@ -0,0 +1,14 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION check_short_code_free()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_TABLE_NAME = 'urls' AND EXISTS (SELECT 1 FROM url_aliases WHERE short_code = NEW.short_code))
+        OR (TG_TABLE_NAME = 'url_aliases' AND EXISTS (SELECT 1 FROM urls WHERE short_code = NEW.short_code)) THEN
+        RAISE EXCEPTION 'short code "%" exists', NEW.short_code USING ERRCODE = 'unique_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+END;
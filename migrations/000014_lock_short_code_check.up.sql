@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Concurrent transactions inserting the same short code into urls and url_aliases wouldn't see each other's
+-- uncommitted rows in the EXISTS checks, so both could succeed. Taking a transaction-level advisory lock
+-- on the short code first makes the second transaction wait until the first one ends and then see its row.
+CREATE OR REPLACE FUNCTION check_short_code_free()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext(NEW.short_code));
+
+    IF (TG_TABLE_NAME = 'urls' AND EXISTS (SELECT 1 FROM url_aliases WHERE short_code = NEW.short_code))
+        OR (TG_TABLE_NAME = 'url_aliases' AND EXISTS (SELECT 1 FROM urls WHERE short_code = NEW.short_code)) THEN
+        RAISE EXCEPTION 'short code "%" exists', NEW.short_code USING ERRCODE = 'unique_violation';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+END;
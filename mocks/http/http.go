@@ -23,6 +23,185 @@ func (_m *MockUrlUseCase) EXPECT() *MockUrlUseCase_Expecter {
 	return &MockUrlUseCase_Expecter{mock: &_m.Mock}
 }
 
+// AddAlias provides a mock function with given fields: ctx, shortCode, alias
+func (_m *MockUrlUseCase) AddAlias(ctx context.Context, shortCode string, alias string) (*entity.URLAlias, error) {
+	ret := _m.Called(ctx, shortCode, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAlias")
+	}
+
+	var r0 *entity.URLAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URLAlias, error)); ok {
+		return rf(ctx, shortCode, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URLAlias); ok {
+		r0 = rf(ctx, shortCode, alias)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URLAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, shortCode, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_AddAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddAlias'
+type MockUrlUseCase_AddAlias_Call struct {
+	*mock.Call
+}
+
+// AddAlias is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - alias string
+func (_e *MockUrlUseCase_Expecter) AddAlias(ctx interface{}, shortCode interface{}, alias interface{}) *MockUrlUseCase_AddAlias_Call {
+	return &MockUrlUseCase_AddAlias_Call{Call: _e.mock.On("AddAlias", ctx, shortCode, alias)}
+}
+
+func (_c *MockUrlUseCase_AddAlias_Call) Run(run func(ctx context.Context, shortCode string, alias string)) *MockUrlUseCase_AddAlias_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_AddAlias_Call) Return(_a0 *entity.URLAlias, _a1 error) *MockUrlUseCase_AddAlias_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_AddAlias_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URLAlias, error)) *MockUrlUseCase_AddAlias_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CheckLinks provides a mock function with given fields: ctx, shortCodes
+func (_m *MockUrlUseCase) CheckLinks(ctx context.Context, shortCodes []string) ([]entity.LinkCheck, error) {
+	ret := _m.Called(ctx, shortCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckLinks")
+	}
+
+	var r0 []entity.LinkCheck
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]entity.LinkCheck, error)); ok {
+		return rf(ctx, shortCodes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []entity.LinkCheck); ok {
+		r0 = rf(ctx, shortCodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.LinkCheck)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortCodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_CheckLinks_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckLinks'
+type MockUrlUseCase_CheckLinks_Call struct {
+	*mock.Call
+}
+
+// CheckLinks is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCodes []string
+func (_e *MockUrlUseCase_Expecter) CheckLinks(ctx interface{}, shortCodes interface{}) *MockUrlUseCase_CheckLinks_Call {
+	return &MockUrlUseCase_CheckLinks_Call{Call: _e.mock.On("CheckLinks", ctx, shortCodes)}
+}
+
+func (_c *MockUrlUseCase_CheckLinks_Call) Run(run func(ctx context.Context, shortCodes []string)) *MockUrlUseCase_CheckLinks_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_CheckLinks_Call) Return(_a0 []entity.LinkCheck, _a1 error) *MockUrlUseCase_CheckLinks_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_CheckLinks_Call) RunAndReturn(run func(context.Context, []string) ([]entity.LinkCheck, error)) *MockUrlUseCase_CheckLinks_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CloneURL provides a mock function with given fields: ctx, shortCode, ip
+func (_m *MockUrlUseCase) CloneURL(ctx context.Context, shortCode string, ip string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CloneURL")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, shortCode, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_CloneURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CloneURL'
+type MockUrlUseCase_CloneURL_Call struct {
+	*mock.Call
+}
+
+// CloneURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - ip string
+func (_e *MockUrlUseCase_Expecter) CloneURL(ctx interface{}, shortCode interface{}, ip interface{}) *MockUrlUseCase_CloneURL_Call {
+	return &MockUrlUseCase_CloneURL_Call{Call: _e.mock.On("CloneURL", ctx, shortCode, ip)}
+}
+
+func (_c *MockUrlUseCase_CloneURL_Call) Run(run func(ctx context.Context, shortCode string, ip string)) *MockUrlUseCase_CloneURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_CloneURL_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_CloneURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_CloneURL_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URL, error)) *MockUrlUseCase_CloneURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // DeactivateURL provides a mock function with given fields: ctx, shortCode
 func (_m *MockUrlUseCase) DeactivateURL(ctx context.Context, shortCode string) error {
 	ret := _m.Called(ctx, shortCode)
@@ -70,24 +249,83 @@ func (_c *MockUrlUseCase_DeactivateURL_Call) RunAndReturn(run func(context.Conte
 	return _c
 }
 
-// GetURLStats provides a mock function with given fields: ctx, shortCode
-func (_m *MockUrlUseCase) GetURLStats(ctx context.Context, shortCode string) (*entity.URL, error) {
+// GetAccessCounts provides a mock function with given fields: ctx, shortCodes
+func (_m *MockUrlUseCase) GetAccessCounts(ctx context.Context, shortCodes []string) (map[string]int64, error) {
+	ret := _m.Called(ctx, shortCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccessCounts")
+	}
+
+	var r0 map[string]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]int64, error)); ok {
+		return rf(ctx, shortCodes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]int64); ok {
+		r0 = rf(ctx, shortCodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortCodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_GetAccessCounts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAccessCounts'
+type MockUrlUseCase_GetAccessCounts_Call struct {
+	*mock.Call
+}
+
+// GetAccessCounts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCodes []string
+func (_e *MockUrlUseCase_Expecter) GetAccessCounts(ctx interface{}, shortCodes interface{}) *MockUrlUseCase_GetAccessCounts_Call {
+	return &MockUrlUseCase_GetAccessCounts_Call{Call: _e.mock.On("GetAccessCounts", ctx, shortCodes)}
+}
+
+func (_c *MockUrlUseCase_GetAccessCounts_Call) Run(run func(ctx context.Context, shortCodes []string)) *MockUrlUseCase_GetAccessCounts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_GetAccessCounts_Call) Return(_a0 map[string]int64, _a1 error) *MockUrlUseCase_GetAccessCounts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_GetAccessCounts_Call) RunAndReturn(run func(context.Context, []string) (map[string]int64, error)) *MockUrlUseCase_GetAccessCounts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetCreator provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlUseCase) GetCreator(ctx context.Context, shortCode string) (*entity.Creator, error) {
 	ret := _m.Called(ctx, shortCode)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetURLStats")
+		panic("no return value specified for GetCreator")
 	}
 
-	var r0 *entity.URL
+	var r0 *entity.Creator
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.Creator, error)); ok {
 		return rf(ctx, shortCode)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.Creator); ok {
 		r0 = rf(ctx, shortCode)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).(*entity.Creator)
 		}
 	}
 
@@ -100,58 +338,58 @@ func (_m *MockUrlUseCase) GetURLStats(ctx context.Context, shortCode string) (*e
 	return r0, r1
 }
 
-// MockUrlUseCase_GetURLStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURLStats'
-type MockUrlUseCase_GetURLStats_Call struct {
+// MockUrlUseCase_GetCreator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetCreator'
+type MockUrlUseCase_GetCreator_Call struct {
 	*mock.Call
 }
 
-// GetURLStats is a helper method to define mock.On call
+// GetCreator is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortCode string
-func (_e *MockUrlUseCase_Expecter) GetURLStats(ctx interface{}, shortCode interface{}) *MockUrlUseCase_GetURLStats_Call {
-	return &MockUrlUseCase_GetURLStats_Call{Call: _e.mock.On("GetURLStats", ctx, shortCode)}
+func (_e *MockUrlUseCase_Expecter) GetCreator(ctx interface{}, shortCode interface{}) *MockUrlUseCase_GetCreator_Call {
+	return &MockUrlUseCase_GetCreator_Call{Call: _e.mock.On("GetCreator", ctx, shortCode)}
 }
 
-func (_c *MockUrlUseCase_GetURLStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_GetURLStats_Call {
+func (_c *MockUrlUseCase_GetCreator_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_GetCreator_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockUrlUseCase_GetURLStats_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_GetURLStats_Call {
+func (_c *MockUrlUseCase_GetCreator_Call) Return(_a0 *entity.Creator, _a1 error) *MockUrlUseCase_GetCreator_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlUseCase_GetURLStats_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_GetURLStats_Call {
+func (_c *MockUrlUseCase_GetCreator_Call) RunAndReturn(run func(context.Context, string) (*entity.Creator, error)) *MockUrlUseCase_GetCreator_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ModifyURL provides a mock function with given fields: ctx, shortCode, originalURL
-func (_m *MockUrlUseCase) ModifyURL(ctx context.Context, shortCode string, originalURL string) (*entity.URL, error) {
-	ret := _m.Called(ctx, shortCode, originalURL)
+// GetDomainStats provides a mock function with given fields: ctx, domain, limit
+func (_m *MockUrlUseCase) GetDomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	ret := _m.Called(ctx, domain, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ModifyURL")
+		panic("no return value specified for GetDomainStats")
 	}
 
-	var r0 *entity.URL
+	var r0 []entity.DomainStats
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URL, error)); ok {
-		return rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]entity.DomainStats, error)); ok {
+		return rf(ctx, domain, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URL); ok {
-		r0 = rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []entity.DomainStats); ok {
+		r0 = rf(ctx, domain, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).([]entity.DomainStats)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, domain, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -159,42 +397,100 @@ func (_m *MockUrlUseCase) ModifyURL(ctx context.Context, shortCode string, origi
 	return r0, r1
 }
 
-// MockUrlUseCase_ModifyURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ModifyURL'
-type MockUrlUseCase_ModifyURL_Call struct {
+// MockUrlUseCase_GetDomainStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetDomainStats'
+type MockUrlUseCase_GetDomainStats_Call struct {
 	*mock.Call
 }
 
-// ModifyURL is a helper method to define mock.On call
+// GetDomainStats is a helper method to define mock.On call
 //   - ctx context.Context
-//   - shortCode string
-//   - originalURL string
-func (_e *MockUrlUseCase_Expecter) ModifyURL(ctx interface{}, shortCode interface{}, originalURL interface{}) *MockUrlUseCase_ModifyURL_Call {
-	return &MockUrlUseCase_ModifyURL_Call{Call: _e.mock.On("ModifyURL", ctx, shortCode, originalURL)}
+//   - domain string
+//   - limit int
+func (_e *MockUrlUseCase_Expecter) GetDomainStats(ctx interface{}, domain interface{}, limit interface{}) *MockUrlUseCase_GetDomainStats_Call {
+	return &MockUrlUseCase_GetDomainStats_Call{Call: _e.mock.On("GetDomainStats", ctx, domain, limit)}
 }
 
-func (_c *MockUrlUseCase_ModifyURL_Call) Run(run func(ctx context.Context, shortCode string, originalURL string)) *MockUrlUseCase_ModifyURL_Call {
+func (_c *MockUrlUseCase_GetDomainStats_Call) Run(run func(ctx context.Context, domain string, limit int)) *MockUrlUseCase_GetDomainStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_GetDomainStats_Call) Return(_a0 []entity.DomainStats, _a1 error) *MockUrlUseCase_GetDomainStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_GetDomainStats_Call) RunAndReturn(run func(context.Context, string, int) ([]entity.DomainStats, error)) *MockUrlUseCase_GetDomainStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSummary provides a mock function with given fields: ctx
+func (_m *MockUrlUseCase) GetSummary(ctx context.Context) (*entity.Summary, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSummary")
+	}
+
+	var r0 *entity.Summary
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*entity.Summary, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *entity.Summary); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Summary)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_GetSummary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSummary'
+type MockUrlUseCase_GetSummary_Call struct {
+	*mock.Call
+}
+
+// GetSummary is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MockUrlUseCase_Expecter) GetSummary(ctx interface{}) *MockUrlUseCase_GetSummary_Call {
+	return &MockUrlUseCase_GetSummary_Call{Call: _e.mock.On("GetSummary", ctx)}
+}
+
+func (_c *MockUrlUseCase_GetSummary_Call) Run(run func(ctx context.Context)) *MockUrlUseCase_GetSummary_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockUrlUseCase_ModifyURL_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ModifyURL_Call {
+func (_c *MockUrlUseCase_GetSummary_Call) Return(_a0 *entity.Summary, _a1 error) *MockUrlUseCase_GetSummary_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlUseCase_ModifyURL_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URL, error)) *MockUrlUseCase_ModifyURL_Call {
+func (_c *MockUrlUseCase_GetSummary_Call) RunAndReturn(run func(context.Context) (*entity.Summary, error)) *MockUrlUseCase_GetSummary_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ResolveShortCode provides a mock function with given fields: ctx, shortCode
-func (_m *MockUrlUseCase) ResolveShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+// GetURLStats provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlUseCase) GetURLStats(ctx context.Context, shortCode string) (*entity.URL, error) {
 	ret := _m.Called(ctx, shortCode)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ResolveShortCode")
+		panic("no return value specified for GetURLStats")
 	}
 
 	var r0 *entity.URL
@@ -219,58 +515,58 @@ func (_m *MockUrlUseCase) ResolveShortCode(ctx context.Context, shortCode string
 	return r0, r1
 }
 
-// MockUrlUseCase_ResolveShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveShortCode'
-type MockUrlUseCase_ResolveShortCode_Call struct {
+// MockUrlUseCase_GetURLStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetURLStats'
+type MockUrlUseCase_GetURLStats_Call struct {
 	*mock.Call
 }
 
-// ResolveShortCode is a helper method to define mock.On call
+// GetURLStats is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortCode string
-func (_e *MockUrlUseCase_Expecter) ResolveShortCode(ctx interface{}, shortCode interface{}) *MockUrlUseCase_ResolveShortCode_Call {
-	return &MockUrlUseCase_ResolveShortCode_Call{Call: _e.mock.On("ResolveShortCode", ctx, shortCode)}
+func (_e *MockUrlUseCase_Expecter) GetURLStats(ctx interface{}, shortCode interface{}) *MockUrlUseCase_GetURLStats_Call {
+	return &MockUrlUseCase_GetURLStats_Call{Call: _e.mock.On("GetURLStats", ctx, shortCode)}
 }
 
-func (_c *MockUrlUseCase_ResolveShortCode_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_ResolveShortCode_Call {
+func (_c *MockUrlUseCase_GetURLStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_GetURLStats_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(context.Context), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *MockUrlUseCase_ResolveShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ResolveShortCode_Call {
+func (_c *MockUrlUseCase_GetURLStats_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_GetURLStats_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlUseCase_ResolveShortCode_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_ResolveShortCode_Call {
+func (_c *MockUrlUseCase_GetURLStats_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_GetURLStats_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// ShortenURL provides a mock function with given fields: ctx, originalURL
-func (_m *MockUrlUseCase) ShortenURL(ctx context.Context, originalURL string) (*entity.URL, error) {
-	ret := _m.Called(ctx, originalURL)
+// ListAccessEvents provides a mock function with given fields: ctx, shortCode, before, limit
+func (_m *MockUrlUseCase) ListAccessEvents(ctx context.Context, shortCode string, before int64, limit int) ([]entity.AccessEvent, error) {
+	ret := _m.Called(ctx, shortCode, before, limit)
 
 	if len(ret) == 0 {
-		panic("no return value specified for ShortenURL")
+		panic("no return value specified for ListAccessEvents")
 	}
 
-	var r0 *entity.URL
+	var r0 []entity.AccessEvent
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
-		return rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) ([]entity.AccessEvent, error)); ok {
+		return rf(ctx, shortCode, before, limit)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
-		r0 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []entity.AccessEvent); ok {
+		r0 = rf(ctx, shortCode, before, limit)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).([]entity.AccessEvent)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, originalURL)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, shortCode, before, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -278,31 +574,748 @@ func (_m *MockUrlUseCase) ShortenURL(ctx context.Context, originalURL string) (*
 	return r0, r1
 }
 
-// MockUrlUseCase_ShortenURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShortenURL'
-type MockUrlUseCase_ShortenURL_Call struct {
+// MockUrlUseCase_ListAccessEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccessEvents'
+type MockUrlUseCase_ListAccessEvents_Call struct {
 	*mock.Call
 }
 
-// ShortenURL is a helper method to define mock.On call
+// ListAccessEvents is a helper method to define mock.On call
 //   - ctx context.Context
-//   - originalURL string
-func (_e *MockUrlUseCase_Expecter) ShortenURL(ctx interface{}, originalURL interface{}) *MockUrlUseCase_ShortenURL_Call {
-	return &MockUrlUseCase_ShortenURL_Call{Call: _e.mock.On("ShortenURL", ctx, originalURL)}
+//   - shortCode string
+//   - before int64
+//   - limit int
+func (_e *MockUrlUseCase_Expecter) ListAccessEvents(ctx interface{}, shortCode interface{}, before interface{}, limit interface{}) *MockUrlUseCase_ListAccessEvents_Call {
+	return &MockUrlUseCase_ListAccessEvents_Call{Call: _e.mock.On("ListAccessEvents", ctx, shortCode, before, limit)}
 }
 
-func (_c *MockUrlUseCase_ShortenURL_Call) Run(run func(ctx context.Context, originalURL string)) *MockUrlUseCase_ShortenURL_Call {
+func (_c *MockUrlUseCase_ListAccessEvents_Call) Run(run func(ctx context.Context, shortCode string, before int64, limit int)) *MockUrlUseCase_ListAccessEvents_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int))
 	})
 	return _c
 }
 
-func (_c *MockUrlUseCase_ShortenURL_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ShortenURL_Call {
+func (_c *MockUrlUseCase_ListAccessEvents_Call) Return(_a0 []entity.AccessEvent, _a1 error) *MockUrlUseCase_ListAccessEvents_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlUseCase_ShortenURL_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_ShortenURL_Call {
+func (_c *MockUrlUseCase_ListAccessEvents_Call) RunAndReturn(run func(context.Context, string, int64, int) ([]entity.AccessEvent, error)) *MockUrlUseCase_ListAccessEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListURLs provides a mock function with given fields: ctx, query, tags, sort, limit, offset
+func (_m *MockUrlUseCase) ListURLs(ctx context.Context, query string, tags []string, sort entity.URLSort, limit int, offset int) ([]entity.URL, error) {
+	ret := _m.Called(ctx, query, tags, sort, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListURLs")
+	}
+
+	var r0 []entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, entity.URLSort, int, int) ([]entity.URL, error)); ok {
+		return rf(ctx, query, tags, sort, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, entity.URLSort, int, int) []entity.URL); ok {
+		r0 = rf(ctx, query, tags, sort, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, entity.URLSort, int, int) error); ok {
+		r1 = rf(ctx, query, tags, sort, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ListURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListURLs'
+type MockUrlUseCase_ListURLs_Call struct {
+	*mock.Call
+}
+
+// ListURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - tags []string
+//   - sort entity.URLSort
+//   - limit int
+//   - offset int
+func (_e *MockUrlUseCase_Expecter) ListURLs(ctx interface{}, query interface{}, tags interface{}, sort interface{}, limit interface{}, offset interface{}) *MockUrlUseCase_ListURLs_Call {
+	return &MockUrlUseCase_ListURLs_Call{Call: _e.mock.On("ListURLs", ctx, query, tags, sort, limit, offset)}
+}
+
+func (_c *MockUrlUseCase_ListURLs_Call) Run(run func(ctx context.Context, query string, tags []string, sort entity.URLSort, limit int, offset int)) *MockUrlUseCase_ListURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(entity.URLSort), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ListURLs_Call) Return(_a0 []entity.URL, _a1 error) *MockUrlUseCase_ListURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ListURLs_Call) RunAndReturn(run func(context.Context, string, []string, entity.URLSort, int, int) ([]entity.URL, error)) *MockUrlUseCase_ListURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListURLsBefore provides a mock function with given fields: ctx, query, tags, before, limit
+func (_m *MockUrlUseCase) ListURLsBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	ret := _m.Called(ctx, query, tags, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListURLsBefore")
+	}
+
+	var r0 []entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int64, int) ([]entity.URL, error)); ok {
+		return rf(ctx, query, tags, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int64, int) []entity.URL); ok {
+		r0 = rf(ctx, query, tags, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, int64, int) error); ok {
+		r1 = rf(ctx, query, tags, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ListURLsBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListURLsBefore'
+type MockUrlUseCase_ListURLsBefore_Call struct {
+	*mock.Call
+}
+
+// ListURLsBefore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - tags []string
+//   - before int64
+//   - limit int
+func (_e *MockUrlUseCase_Expecter) ListURLsBefore(ctx interface{}, query interface{}, tags interface{}, before interface{}, limit interface{}) *MockUrlUseCase_ListURLsBefore_Call {
+	return &MockUrlUseCase_ListURLsBefore_Call{Call: _e.mock.On("ListURLsBefore", ctx, query, tags, before, limit)}
+}
+
+func (_c *MockUrlUseCase_ListURLsBefore_Call) Run(run func(ctx context.Context, query string, tags []string, before int64, limit int)) *MockUrlUseCase_ListURLsBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(int64), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ListURLsBefore_Call) Return(_a0 []entity.URL, _a1 error) *MockUrlUseCase_ListURLsBefore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ListURLsBefore_Call) RunAndReturn(run func(context.Context, string, []string, int64, int) ([]entity.URL, error)) *MockUrlUseCase_ListURLsBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupShortCode provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlUseCase) LookupShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LookupShortCode")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_LookupShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupShortCode'
+type MockUrlUseCase_LookupShortCode_Call struct {
+	*mock.Call
+}
+
+// LookupShortCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlUseCase_Expecter) LookupShortCode(ctx interface{}, shortCode interface{}) *MockUrlUseCase_LookupShortCode_Call {
+	return &MockUrlUseCase_LookupShortCode_Call{Call: _e.mock.On("LookupShortCode", ctx, shortCode)}
+}
+
+func (_c *MockUrlUseCase_LookupShortCode_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_LookupShortCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_LookupShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_LookupShortCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_LookupShortCode_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_LookupShortCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LookupURLs provides a mock function with given fields: ctx, shortCodes
+func (_m *MockUrlUseCase) LookupURLs(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	ret := _m.Called(ctx, shortCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LookupURLs")
+	}
+
+	var r0 map[string]*entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]*entity.URL, error)); ok {
+		return rf(ctx, shortCodes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]*entity.URL); ok {
+		r0 = rf(ctx, shortCodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortCodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_LookupURLs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LookupURLs'
+type MockUrlUseCase_LookupURLs_Call struct {
+	*mock.Call
+}
+
+// LookupURLs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCodes []string
+func (_e *MockUrlUseCase_Expecter) LookupURLs(ctx interface{}, shortCodes interface{}) *MockUrlUseCase_LookupURLs_Call {
+	return &MockUrlUseCase_LookupURLs_Call{Call: _e.mock.On("LookupURLs", ctx, shortCodes)}
+}
+
+func (_c *MockUrlUseCase_LookupURLs_Call) Run(run func(ctx context.Context, shortCodes []string)) *MockUrlUseCase_LookupURLs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_LookupURLs_Call) Return(_a0 map[string]*entity.URL, _a1 error) *MockUrlUseCase_LookupURLs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_LookupURLs_Call) RunAndReturn(run func(context.Context, []string) (map[string]*entity.URL, error)) *MockUrlUseCase_LookupURLs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RenameShortCode provides a mock function with given fields: ctx, oldShortCode, newShortCode
+func (_m *MockUrlUseCase) RenameShortCode(ctx context.Context, oldShortCode string, newShortCode string) (*entity.URL, error) {
+	ret := _m.Called(ctx, oldShortCode, newShortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RenameShortCode")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URL, error)); ok {
+		return rf(ctx, oldShortCode, newShortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URL); ok {
+		r0 = rf(ctx, oldShortCode, newShortCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, oldShortCode, newShortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_RenameShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RenameShortCode'
+type MockUrlUseCase_RenameShortCode_Call struct {
+	*mock.Call
+}
+
+// RenameShortCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - oldShortCode string
+//   - newShortCode string
+func (_e *MockUrlUseCase_Expecter) RenameShortCode(ctx interface{}, oldShortCode interface{}, newShortCode interface{}) *MockUrlUseCase_RenameShortCode_Call {
+	return &MockUrlUseCase_RenameShortCode_Call{Call: _e.mock.On("RenameShortCode", ctx, oldShortCode, newShortCode)}
+}
+
+func (_c *MockUrlUseCase_RenameShortCode_Call) Run(run func(ctx context.Context, oldShortCode string, newShortCode string)) *MockUrlUseCase_RenameShortCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_RenameShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_RenameShortCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_RenameShortCode_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URL, error)) *MockUrlUseCase_RenameShortCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReserveShortCode provides a mock function with given fields: ctx, ip
+func (_m *MockUrlUseCase) ReserveShortCode(ctx context.Context, ip string) (*entity.URL, error) {
+	ret := _m.Called(ctx, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveShortCode")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
+		return rf(ctx, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
+		r0 = rf(ctx, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ReserveShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReserveShortCode'
+type MockUrlUseCase_ReserveShortCode_Call struct {
+	*mock.Call
+}
+
+// ReserveShortCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - ip string
+func (_e *MockUrlUseCase_Expecter) ReserveShortCode(ctx interface{}, ip interface{}) *MockUrlUseCase_ReserveShortCode_Call {
+	return &MockUrlUseCase_ReserveShortCode_Call{Call: _e.mock.On("ReserveShortCode", ctx, ip)}
+}
+
+func (_c *MockUrlUseCase_ReserveShortCode_Call) Run(run func(ctx context.Context, ip string)) *MockUrlUseCase_ReserveShortCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ReserveShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ReserveShortCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ReserveShortCode_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlUseCase_ReserveShortCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetStats provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlUseCase) ResetStats(ctx context.Context, shortCode string) error {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlUseCase_ResetStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetStats'
+type MockUrlUseCase_ResetStats_Call struct {
+	*mock.Call
+}
+
+// ResetStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlUseCase_Expecter) ResetStats(ctx interface{}, shortCode interface{}) *MockUrlUseCase_ResetStats_Call {
+	return &MockUrlUseCase_ResetStats_Call{Call: _e.mock.On("ResetStats", ctx, shortCode)}
+}
+
+func (_c *MockUrlUseCase_ResetStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_ResetStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ResetStats_Call) Return(_a0 error) *MockUrlUseCase_ResetStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ResetStats_Call) RunAndReturn(run func(context.Context, string) error) *MockUrlUseCase_ResetStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResolveShortCode provides a mock function with given fields: ctx, shortCode, click
+func (_m *MockUrlUseCase) ResolveShortCode(ctx context.Context, shortCode string, click entity.Click) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, click)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResolveShortCode")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.Click) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, click)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.Click) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, click)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.Click) error); ok {
+		r1 = rf(ctx, shortCode, click)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ResolveShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResolveShortCode'
+type MockUrlUseCase_ResolveShortCode_Call struct {
+	*mock.Call
+}
+
+// ResolveShortCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - click entity.Click
+func (_e *MockUrlUseCase_Expecter) ResolveShortCode(ctx interface{}, shortCode interface{}, click interface{}) *MockUrlUseCase_ResolveShortCode_Call {
+	return &MockUrlUseCase_ResolveShortCode_Call{Call: _e.mock.On("ResolveShortCode", ctx, shortCode, click)}
+}
+
+func (_c *MockUrlUseCase_ResolveShortCode_Call) Run(run func(ctx context.Context, shortCode string, click entity.Click)) *MockUrlUseCase_ResolveShortCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.Click))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ResolveShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ResolveShortCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ResolveShortCode_Call) RunAndReturn(run func(context.Context, string, entity.Click) (*entity.URL, error)) *MockUrlUseCase_ResolveShortCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShortCodeExists provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlUseCase) ShortCodeExists(ctx context.Context, shortCode string) (bool, error) {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ShortCodeExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, shortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ShortCodeExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShortCodeExists'
+type MockUrlUseCase_ShortCodeExists_Call struct {
+	*mock.Call
+}
+
+// ShortCodeExists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlUseCase_Expecter) ShortCodeExists(ctx interface{}, shortCode interface{}) *MockUrlUseCase_ShortCodeExists_Call {
+	return &MockUrlUseCase_ShortCodeExists_Call{Call: _e.mock.On("ShortCodeExists", ctx, shortCode)}
+}
+
+func (_c *MockUrlUseCase_ShortCodeExists_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlUseCase_ShortCodeExists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ShortCodeExists_Call) Return(_a0 bool, _a1 error) *MockUrlUseCase_ShortCodeExists_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ShortCodeExists_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockUrlUseCase_ShortCodeExists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ShortenURL provides a mock function with given fields: ctx, in
+func (_m *MockUrlUseCase) ShortenURL(ctx context.Context, in entity.ShortenInput) (*entity.URL, error) {
+	ret := _m.Called(ctx, in)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ShortenURL")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ShortenInput) (*entity.URL, error)); ok {
+		return rf(ctx, in)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, entity.ShortenInput) *entity.URL); ok {
+		r0 = rf(ctx, in)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, entity.ShortenInput) error); ok {
+		r1 = rf(ctx, in)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_ShortenURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ShortenURL'
+type MockUrlUseCase_ShortenURL_Call struct {
+	*mock.Call
+}
+
+// ShortenURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - in entity.ShortenInput
+func (_e *MockUrlUseCase_Expecter) ShortenURL(ctx interface{}, in interface{}) *MockUrlUseCase_ShortenURL_Call {
+	return &MockUrlUseCase_ShortenURL_Call{Call: _e.mock.On("ShortenURL", ctx, in)}
+}
+
+func (_c *MockUrlUseCase_ShortenURL_Call) Run(run func(ctx context.Context, in entity.ShortenInput)) *MockUrlUseCase_ShortenURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(entity.ShortenInput))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_ShortenURL_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_ShortenURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_ShortenURL_Call) RunAndReturn(run func(context.Context, entity.ShortenInput) (*entity.URL, error)) *MockUrlUseCase_ShortenURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateURL provides a mock function with given fields: ctx, shortCode, update
+func (_m *MockUrlUseCase) UpdateURL(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateURL")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.URLUpdate) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.URLUpdate) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.URLUpdate) error); ok {
+		r1 = rf(ctx, shortCode, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlUseCase_UpdateURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateURL'
+type MockUrlUseCase_UpdateURL_Call struct {
+	*mock.Call
+}
+
+// UpdateURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - update entity.URLUpdate
+func (_e *MockUrlUseCase_Expecter) UpdateURL(ctx interface{}, shortCode interface{}, update interface{}) *MockUrlUseCase_UpdateURL_Call {
+	return &MockUrlUseCase_UpdateURL_Call{Call: _e.mock.On("UpdateURL", ctx, shortCode, update)}
+}
+
+func (_c *MockUrlUseCase_UpdateURL_Call) Run(run func(ctx context.Context, shortCode string, update entity.URLUpdate)) *MockUrlUseCase_UpdateURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.URLUpdate))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_UpdateURL_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlUseCase_UpdateURL_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlUseCase_UpdateURL_Call) RunAndReturn(run func(context.Context, string, entity.URLUpdate) (*entity.URL, error)) *MockUrlUseCase_UpdateURL_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpsertURL provides a mock function with given fields: ctx, shortCode, originalURL, note, tags, ip
+func (_m *MockUrlUseCase) UpsertURL(ctx context.Context, shortCode string, originalURL string, note string, tags []string, ip string) (*entity.URL, bool, error) {
+	ret := _m.Called(ctx, shortCode, originalURL, note, tags, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertURL")
+	}
+
+	var r0 *entity.URL
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) (*entity.URL, bool, error)); ok {
+		return rf(ctx, shortCode, originalURL, note, tags, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, originalURL, note, tags, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []string, string) bool); ok {
+		r1 = rf(ctx, shortCode, originalURL, note, tags, ip)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, []string, string) error); ok {
+		r2 = rf(ctx, shortCode, originalURL, note, tags, ip)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockUrlUseCase_UpsertURL_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpsertURL'
+type MockUrlUseCase_UpsertURL_Call struct {
+	*mock.Call
+}
+
+// UpsertURL is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - originalURL string
+//   - note string
+//   - tags []string
+//   - ip string
+func (_e *MockUrlUseCase_Expecter) UpsertURL(ctx interface{}, shortCode interface{}, originalURL interface{}, note interface{}, tags interface{}, ip interface{}) *MockUrlUseCase_UpsertURL_Call {
+	return &MockUrlUseCase_UpsertURL_Call{Call: _e.mock.On("UpsertURL", ctx, shortCode, originalURL, note, tags, ip)}
+}
+
+func (_c *MockUrlUseCase_UpsertURL_Call) Run(run func(ctx context.Context, shortCode string, originalURL string, note string, tags []string, ip string)) *MockUrlUseCase_UpsertURL_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]string), args[5].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlUseCase_UpsertURL_Call) Return(_a0 *entity.URL, _a1 bool, _a2 error) *MockUrlUseCase_UpsertURL_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockUrlUseCase_UpsertURL_Call) RunAndReturn(run func(context.Context, string, string, string, []string, string) (*entity.URL, bool, error)) *MockUrlUseCase_UpsertURL_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -7,32 +7,1243 @@ import (
 
 	mock "github.com/stretchr/testify/mock"
 	entity "github.com/vadimbarashkov/url-shortener/internal/entity"
+
+	time "time"
 )
 
-// MockUrlRepository is an autogenerated mock type for the urlRepository type
-type MockUrlRepository struct {
-	mock.Mock
+// MockUrlRepository is an autogenerated mock type for the urlRepository type
+type MockUrlRepository struct {
+	mock.Mock
+}
+
+type MockUrlRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockUrlRepository) EXPECT() *MockUrlRepository_Expecter {
+	return &MockUrlRepository_Expecter{mock: &_m.Mock}
+}
+
+// CountAccessEvents provides a mock function with given fields: ctx, urlIDs
+func (_m *MockUrlRepository) CountAccessEvents(ctx context.Context, urlIDs []int64) (map[int64]int64, error) {
+	ret := _m.Called(ctx, urlIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountAccessEvents")
+	}
+
+	var r0 map[int64]int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) (map[int64]int64, error)); ok {
+		return rf(ctx, urlIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) map[int64]int64); ok {
+		r0 = rf(ctx, urlIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, urlIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_CountAccessEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CountAccessEvents'
+type MockUrlRepository_CountAccessEvents_Call struct {
+	*mock.Call
+}
+
+// CountAccessEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlIDs []int64
+func (_e *MockUrlRepository_Expecter) CountAccessEvents(ctx interface{}, urlIDs interface{}) *MockUrlRepository_CountAccessEvents_Call {
+	return &MockUrlRepository_CountAccessEvents_Call{Call: _e.mock.On("CountAccessEvents", ctx, urlIDs)}
+}
+
+func (_c *MockUrlRepository_CountAccessEvents_Call) Run(run func(ctx context.Context, urlIDs []int64)) *MockUrlRepository_CountAccessEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_CountAccessEvents_Call) Return(_a0 map[int64]int64, _a1 error) *MockUrlRepository_CountAccessEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_CountAccessEvents_Call) RunAndReturn(run func(context.Context, []int64) (map[int64]int64, error)) *MockUrlRepository_CountAccessEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteExpired provides a mock function with given fields: ctx, now
+func (_m *MockUrlRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpired")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_DeleteExpired_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteExpired'
+type MockUrlRepository_DeleteExpired_Call struct {
+	*mock.Call
+}
+
+// DeleteExpired is a helper method to define mock.On call
+//   - ctx context.Context
+//   - now time.Time
+func (_e *MockUrlRepository_Expecter) DeleteExpired(ctx interface{}, now interface{}) *MockUrlRepository_DeleteExpired_Call {
+	return &MockUrlRepository_DeleteExpired_Call{Call: _e.mock.On("DeleteExpired", ctx, now)}
+}
+
+func (_c *MockUrlRepository_DeleteExpired_Call) Run(run func(ctx context.Context, now time.Time)) *MockUrlRepository_DeleteExpired_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_DeleteExpired_Call) Return(_a0 int64, _a1 error) *MockUrlRepository_DeleteExpired_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_DeleteExpired_Call) RunAndReturn(run func(context.Context, time.Time) (int64, error)) *MockUrlRepository_DeleteExpired_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DomainStats provides a mock function with given fields: ctx, domain, limit
+func (_m *MockUrlRepository) DomainStats(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	ret := _m.Called(ctx, domain, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DomainStats")
+	}
+
+	var r0 []entity.DomainStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]entity.DomainStats, error)); ok {
+		return rf(ctx, domain, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []entity.DomainStats); ok {
+		r0 = rf(ctx, domain, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DomainStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, domain, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_DomainStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DomainStats'
+type MockUrlRepository_DomainStats_Call struct {
+	*mock.Call
+}
+
+// DomainStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - domain string
+//   - limit int
+func (_e *MockUrlRepository_Expecter) DomainStats(ctx interface{}, domain interface{}, limit interface{}) *MockUrlRepository_DomainStats_Call {
+	return &MockUrlRepository_DomainStats_Call{Call: _e.mock.On("DomainStats", ctx, domain, limit)}
+}
+
+func (_c *MockUrlRepository_DomainStats_Call) Run(run func(ctx context.Context, domain string, limit int)) *MockUrlRepository_DomainStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_DomainStats_Call) Return(_a0 []entity.DomainStats, _a1 error) *MockUrlRepository_DomainStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_DomainStats_Call) RunAndReturn(run func(context.Context, string, int) ([]entity.DomainStats, error)) *MockUrlRepository_DomainStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DomainStatsFromEvents provides a mock function with given fields: ctx, domain, limit
+func (_m *MockUrlRepository) DomainStatsFromEvents(ctx context.Context, domain string, limit int) ([]entity.DomainStats, error) {
+	ret := _m.Called(ctx, domain, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DomainStatsFromEvents")
+	}
+
+	var r0 []entity.DomainStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) ([]entity.DomainStats, error)); ok {
+		return rf(ctx, domain, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) []entity.DomainStats); ok {
+		r0 = rf(ctx, domain, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.DomainStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, domain, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_DomainStatsFromEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DomainStatsFromEvents'
+type MockUrlRepository_DomainStatsFromEvents_Call struct {
+	*mock.Call
+}
+
+// DomainStatsFromEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - domain string
+//   - limit int
+func (_e *MockUrlRepository_Expecter) DomainStatsFromEvents(ctx interface{}, domain interface{}, limit interface{}) *MockUrlRepository_DomainStatsFromEvents_Call {
+	return &MockUrlRepository_DomainStatsFromEvents_Call{Call: _e.mock.On("DomainStatsFromEvents", ctx, domain, limit)}
+}
+
+func (_c *MockUrlRepository_DomainStatsFromEvents_Call) Run(run func(ctx context.Context, domain string, limit int)) *MockUrlRepository_DomainStatsFromEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_DomainStatsFromEvents_Call) Return(_a0 []entity.DomainStats, _a1 error) *MockUrlRepository_DomainStatsFromEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_DomainStatsFromEvents_Call) RunAndReturn(run func(context.Context, string, int) ([]entity.DomainStats, error)) *MockUrlRepository_DomainStatsFromEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Exists provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlRepository) Exists(ctx context.Context, shortCode string) (bool, error) {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Exists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, shortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_Exists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Exists'
+type MockUrlRepository_Exists_Call struct {
+	*mock.Call
+}
+
+// Exists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlRepository_Expecter) Exists(ctx interface{}, shortCode interface{}) *MockUrlRepository_Exists_Call {
+	return &MockUrlRepository_Exists_Call{Call: _e.mock.On("Exists", ctx, shortCode)}
+}
+
+func (_c *MockUrlRepository_Exists_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_Exists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Exists_Call) Return(_a0 bool, _a1 error) *MockUrlRepository_Exists_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_Exists_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *MockUrlRepository_Exists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementAliasStats provides a mock function with given fields: ctx, alias
+func (_m *MockUrlRepository) IncrementAliasStats(ctx context.Context, alias string) error {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementAliasStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_IncrementAliasStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementAliasStats'
+type MockUrlRepository_IncrementAliasStats_Call struct {
+	*mock.Call
+}
+
+// IncrementAliasStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockUrlRepository_Expecter) IncrementAliasStats(ctx interface{}, alias interface{}) *MockUrlRepository_IncrementAliasStats_Call {
+	return &MockUrlRepository_IncrementAliasStats_Call{Call: _e.mock.On("IncrementAliasStats", ctx, alias)}
+}
+
+func (_c *MockUrlRepository_IncrementAliasStats_Call) Run(run func(ctx context.Context, alias string)) *MockUrlRepository_IncrementAliasStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_IncrementAliasStats_Call) Return(_a0 error) *MockUrlRepository_IncrementAliasStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_IncrementAliasStats_Call) RunAndReturn(run func(context.Context, string) error) *MockUrlRepository_IncrementAliasStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IncrementClickStats provides a mock function with given fields: ctx, urlID, dimension, value
+func (_m *MockUrlRepository) IncrementClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string) error {
+	ret := _m.Called(ctx, urlID, dimension, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrementClickStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, entity.ClickDimension, string) error); ok {
+		r0 = rf(ctx, urlID, dimension, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_IncrementClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IncrementClickStats'
+type MockUrlRepository_IncrementClickStats_Call struct {
+	*mock.Call
+}
+
+// IncrementClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlID int64
+//   - dimension entity.ClickDimension
+//   - value string
+func (_e *MockUrlRepository_Expecter) IncrementClickStats(ctx interface{}, urlID interface{}, dimension interface{}, value interface{}) *MockUrlRepository_IncrementClickStats_Call {
+	return &MockUrlRepository_IncrementClickStats_Call{Call: _e.mock.On("IncrementClickStats", ctx, urlID, dimension, value)}
+}
+
+func (_c *MockUrlRepository_IncrementClickStats_Call) Run(run func(ctx context.Context, urlID int64, dimension entity.ClickDimension, value string)) *MockUrlRepository_IncrementClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(entity.ClickDimension), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_IncrementClickStats_Call) Return(_a0 error) *MockUrlRepository_IncrementClickStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_IncrementClickStats_Call) RunAndReturn(run func(context.Context, int64, entity.ClickDimension, string) error) *MockUrlRepository_IncrementClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// List provides a mock function with given fields: ctx, query, tags, sort, limit, offset
+func (_m *MockUrlRepository) List(ctx context.Context, query string, tags []string, sort entity.URLSort, limit int, offset int) ([]entity.URL, error) {
+	ret := _m.Called(ctx, query, tags, sort, limit, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 []entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, entity.URLSort, int, int) ([]entity.URL, error)); ok {
+		return rf(ctx, query, tags, sort, limit, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, entity.URLSort, int, int) []entity.URL); ok {
+		r0 = rf(ctx, query, tags, sort, limit, offset)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, entity.URLSort, int, int) error); ok {
+		r1 = rf(ctx, query, tags, sort, limit, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_List_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'List'
+type MockUrlRepository_List_Call struct {
+	*mock.Call
+}
+
+// List is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - tags []string
+//   - sort entity.URLSort
+//   - limit int
+//   - offset int
+func (_e *MockUrlRepository_Expecter) List(ctx interface{}, query interface{}, tags interface{}, sort interface{}, limit interface{}, offset interface{}) *MockUrlRepository_List_Call {
+	return &MockUrlRepository_List_Call{Call: _e.mock.On("List", ctx, query, tags, sort, limit, offset)}
+}
+
+func (_c *MockUrlRepository_List_Call) Run(run func(ctx context.Context, query string, tags []string, sort entity.URLSort, limit int, offset int)) *MockUrlRepository_List_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(entity.URLSort), args[4].(int), args[5].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_List_Call) Return(_a0 []entity.URL, _a1 error) *MockUrlRepository_List_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_List_Call) RunAndReturn(run func(context.Context, string, []string, entity.URLSort, int, int) ([]entity.URL, error)) *MockUrlRepository_List_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAccessEvents provides a mock function with given fields: ctx, urlID, before, limit
+func (_m *MockUrlRepository) ListAccessEvents(ctx context.Context, urlID int64, before int64, limit int) ([]entity.AccessEvent, error) {
+	ret := _m.Called(ctx, urlID, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAccessEvents")
+	}
+
+	var r0 []entity.AccessEvent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int) ([]entity.AccessEvent, error)); ok {
+		return rf(ctx, urlID, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int) []entity.AccessEvent); ok {
+		r0 = rf(ctx, urlID, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.AccessEvent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int) error); ok {
+		r1 = rf(ctx, urlID, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_ListAccessEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAccessEvents'
+type MockUrlRepository_ListAccessEvents_Call struct {
+	*mock.Call
+}
+
+// ListAccessEvents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlID int64
+//   - before int64
+//   - limit int
+func (_e *MockUrlRepository_Expecter) ListAccessEvents(ctx interface{}, urlID interface{}, before interface{}, limit interface{}) *MockUrlRepository_ListAccessEvents_Call {
+	return &MockUrlRepository_ListAccessEvents_Call{Call: _e.mock.On("ListAccessEvents", ctx, urlID, before, limit)}
+}
+
+func (_c *MockUrlRepository_ListAccessEvents_Call) Run(run func(ctx context.Context, urlID int64, before int64, limit int)) *MockUrlRepository_ListAccessEvents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_ListAccessEvents_Call) Return(_a0 []entity.AccessEvent, _a1 error) *MockUrlRepository_ListAccessEvents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_ListAccessEvents_Call) RunAndReturn(run func(context.Context, int64, int64, int) ([]entity.AccessEvent, error)) *MockUrlRepository_ListAccessEvents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAliases provides a mock function with given fields: ctx, urlID
+func (_m *MockUrlRepository) ListAliases(ctx context.Context, urlID int64) ([]entity.URLAlias, error) {
+	ret := _m.Called(ctx, urlID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAliases")
+	}
+
+	var r0 []entity.URLAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]entity.URLAlias, error)); ok {
+		return rf(ctx, urlID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []entity.URLAlias); ok {
+		r0 = rf(ctx, urlID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.URLAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, urlID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_ListAliases_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAliases'
+type MockUrlRepository_ListAliases_Call struct {
+	*mock.Call
+}
+
+// ListAliases is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlID int64
+func (_e *MockUrlRepository_Expecter) ListAliases(ctx interface{}, urlID interface{}) *MockUrlRepository_ListAliases_Call {
+	return &MockUrlRepository_ListAliases_Call{Call: _e.mock.On("ListAliases", ctx, urlID)}
+}
+
+func (_c *MockUrlRepository_ListAliases_Call) Run(run func(ctx context.Context, urlID int64)) *MockUrlRepository_ListAliases_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_ListAliases_Call) Return(_a0 []entity.URLAlias, _a1 error) *MockUrlRepository_ListAliases_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_ListAliases_Call) RunAndReturn(run func(context.Context, int64) ([]entity.URLAlias, error)) *MockUrlRepository_ListAliases_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListBefore provides a mock function with given fields: ctx, query, tags, before, limit
+func (_m *MockUrlRepository) ListBefore(ctx context.Context, query string, tags []string, before int64, limit int) ([]entity.URL, error) {
+	ret := _m.Called(ctx, query, tags, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListBefore")
+	}
+
+	var r0 []entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int64, int) ([]entity.URL, error)); ok {
+		return rf(ctx, query, tags, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []string, int64, int) []entity.URL); ok {
+		r0 = rf(ctx, query, tags, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []string, int64, int) error); ok {
+		r1 = rf(ctx, query, tags, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_ListBefore_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListBefore'
+type MockUrlRepository_ListBefore_Call struct {
+	*mock.Call
+}
+
+// ListBefore is a helper method to define mock.On call
+//   - ctx context.Context
+//   - query string
+//   - tags []string
+//   - before int64
+//   - limit int
+func (_e *MockUrlRepository_Expecter) ListBefore(ctx interface{}, query interface{}, tags interface{}, before interface{}, limit interface{}) *MockUrlRepository_ListBefore_Call {
+	return &MockUrlRepository_ListBefore_Call{Call: _e.mock.On("ListBefore", ctx, query, tags, before, limit)}
+}
+
+func (_c *MockUrlRepository_ListBefore_Call) Run(run func(ctx context.Context, query string, tags []string, before int64, limit int)) *MockUrlRepository_ListBefore_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]string), args[3].(int64), args[4].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_ListBefore_Call) Return(_a0 []entity.URL, _a1 error) *MockUrlRepository_ListBefore_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_ListBefore_Call) RunAndReturn(run func(context.Context, string, []string, int64, int) ([]entity.URL, error)) *MockUrlRepository_ListBefore_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Remove provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlRepository) Remove(ctx context.Context, shortCode string) error {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Remove")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_Remove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Remove'
+type MockUrlRepository_Remove_Call struct {
+	*mock.Call
+}
+
+// Remove is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlRepository_Expecter) Remove(ctx interface{}, shortCode interface{}) *MockUrlRepository_Remove_Call {
+	return &MockUrlRepository_Remove_Call{Call: _e.mock.On("Remove", ctx, shortCode)}
+}
+
+func (_c *MockUrlRepository_Remove_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_Remove_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Remove_Call) Return(_a0 error) *MockUrlRepository_Remove_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_Remove_Call) RunAndReturn(run func(context.Context, string) error) *MockUrlRepository_Remove_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Rename provides a mock function with given fields: ctx, oldShortCode, newShortCode
+func (_m *MockUrlRepository) Rename(ctx context.Context, oldShortCode string, newShortCode string) (*entity.URL, error) {
+	ret := _m.Called(ctx, oldShortCode, newShortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rename")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URL, error)); ok {
+		return rf(ctx, oldShortCode, newShortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URL); ok {
+		r0 = rf(ctx, oldShortCode, newShortCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, oldShortCode, newShortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_Rename_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rename'
+type MockUrlRepository_Rename_Call struct {
+	*mock.Call
+}
+
+// Rename is a helper method to define mock.On call
+//   - ctx context.Context
+//   - oldShortCode string
+//   - newShortCode string
+func (_e *MockUrlRepository_Expecter) Rename(ctx interface{}, oldShortCode interface{}, newShortCode interface{}) *MockUrlRepository_Rename_Call {
+	return &MockUrlRepository_Rename_Call{Call: _e.mock.On("Rename", ctx, oldShortCode, newShortCode)}
+}
+
+func (_c *MockUrlRepository_Rename_Call) Run(run func(ctx context.Context, oldShortCode string, newShortCode string)) *MockUrlRepository_Rename_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Rename_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_Rename_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_Rename_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URL, error)) *MockUrlRepository_Rename_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reserve provides a mock function with given fields: ctx, shortCode, expiresAt, owner
+func (_m *MockUrlRepository) Reserve(ctx context.Context, shortCode string, expiresAt time.Time, owner string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, expiresAt, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reserve")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, expiresAt, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, expiresAt, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time, string) error); ok {
+		r1 = rf(ctx, shortCode, expiresAt, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_Reserve_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reserve'
+type MockUrlRepository_Reserve_Call struct {
+	*mock.Call
+}
+
+// Reserve is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - expiresAt time.Time
+//   - owner string
+func (_e *MockUrlRepository_Expecter) Reserve(ctx interface{}, shortCode interface{}, expiresAt interface{}, owner interface{}) *MockUrlRepository_Reserve_Call {
+	return &MockUrlRepository_Reserve_Call{Call: _e.mock.On("Reserve", ctx, shortCode, expiresAt, owner)}
+}
+
+func (_c *MockUrlRepository_Reserve_Call) Run(run func(ctx context.Context, shortCode string, expiresAt time.Time, owner string)) *MockUrlRepository_Reserve_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Reserve_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_Reserve_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_Reserve_Call) RunAndReturn(run func(context.Context, string, time.Time, string) (*entity.URL, error)) *MockUrlRepository_Reserve_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetStats provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlRepository) ResetStats(ctx context.Context, shortCode string) error {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetStats")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_ResetStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetStats'
+type MockUrlRepository_ResetStats_Call struct {
+	*mock.Call
+}
+
+// ResetStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlRepository_Expecter) ResetStats(ctx interface{}, shortCode interface{}) *MockUrlRepository_ResetStats_Call {
+	return &MockUrlRepository_ResetStats_Call{Call: _e.mock.On("ResetStats", ctx, shortCode)}
+}
+
+func (_c *MockUrlRepository_ResetStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_ResetStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_ResetStats_Call) Return(_a0 error) *MockUrlRepository_ResetStats_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_ResetStats_Call) RunAndReturn(run func(context.Context, string) error) *MockUrlRepository_ResetStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetrieveAlias provides a mock function with given fields: ctx, alias
+func (_m *MockUrlRepository) RetrieveAlias(ctx context.Context, alias string) (*entity.URLAlias, error) {
+	ret := _m.Called(ctx, alias)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveAlias")
+	}
+
+	var r0 *entity.URLAlias
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URLAlias, error)); ok {
+		return rf(ctx, alias)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URLAlias); ok {
+		r0 = rf(ctx, alias)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URLAlias)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, alias)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_RetrieveAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveAlias'
+type MockUrlRepository_RetrieveAlias_Call struct {
+	*mock.Call
+}
+
+// RetrieveAlias is a helper method to define mock.On call
+//   - ctx context.Context
+//   - alias string
+func (_e *MockUrlRepository_Expecter) RetrieveAlias(ctx interface{}, alias interface{}) *MockUrlRepository_RetrieveAlias_Call {
+	return &MockUrlRepository_RetrieveAlias_Call{Call: _e.mock.On("RetrieveAlias", ctx, alias)}
+}
+
+func (_c *MockUrlRepository_RetrieveAlias_Call) Run(run func(ctx context.Context, alias string)) *MockUrlRepository_RetrieveAlias_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveAlias_Call) Return(_a0 *entity.URLAlias, _a1 error) *MockUrlRepository_RetrieveAlias_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveAlias_Call) RunAndReturn(run func(context.Context, string) (*entity.URLAlias, error)) *MockUrlRepository_RetrieveAlias_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetrieveAndUpdateStats provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlRepository) RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveAndUpdateStats")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_RetrieveAndUpdateStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveAndUpdateStats'
+type MockUrlRepository_RetrieveAndUpdateStats_Call struct {
+	*mock.Call
+}
+
+// RetrieveAndUpdateStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlRepository_Expecter) RetrieveAndUpdateStats(ctx interface{}, shortCode interface{}) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+	return &MockUrlRepository_RetrieveAndUpdateStats_Call{Call: _e.mock.On("RetrieveAndUpdateStats", ctx, shortCode)}
+}
+
+func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetrieveByShortCode provides a mock function with given fields: ctx, shortCode
+func (_m *MockUrlRepository) RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveByShortCode")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_RetrieveByShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveByShortCode'
+type MockUrlRepository_RetrieveByShortCode_Call struct {
+	*mock.Call
+}
+
+// RetrieveByShortCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+func (_e *MockUrlRepository_Expecter) RetrieveByShortCode(ctx interface{}, shortCode interface{}) *MockUrlRepository_RetrieveByShortCode_Call {
+	return &MockUrlRepository_RetrieveByShortCode_Call{Call: _e.mock.On("RetrieveByShortCode", ctx, shortCode)}
+}
+
+func (_c *MockUrlRepository_RetrieveByShortCode_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_RetrieveByShortCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveByShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_RetrieveByShortCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveByShortCode_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlRepository_RetrieveByShortCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetrieveClickStats provides a mock function with given fields: ctx, urlID, dimension, limit
+func (_m *MockUrlRepository) RetrieveClickStats(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int) ([]entity.StatCount, error) {
+	ret := _m.Called(ctx, urlID, dimension, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveClickStats")
+	}
+
+	var r0 []entity.StatCount
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, entity.ClickDimension, int) ([]entity.StatCount, error)); ok {
+		return rf(ctx, urlID, dimension, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, entity.ClickDimension, int) []entity.StatCount); ok {
+		r0 = rf(ctx, urlID, dimension, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.StatCount)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, entity.ClickDimension, int) error); ok {
+		r1 = rf(ctx, urlID, dimension, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_RetrieveClickStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveClickStats'
+type MockUrlRepository_RetrieveClickStats_Call struct {
+	*mock.Call
+}
+
+// RetrieveClickStats is a helper method to define mock.On call
+//   - ctx context.Context
+//   - urlID int64
+//   - dimension entity.ClickDimension
+//   - limit int
+func (_e *MockUrlRepository_Expecter) RetrieveClickStats(ctx interface{}, urlID interface{}, dimension interface{}, limit interface{}) *MockUrlRepository_RetrieveClickStats_Call {
+	return &MockUrlRepository_RetrieveClickStats_Call{Call: _e.mock.On("RetrieveClickStats", ctx, urlID, dimension, limit)}
+}
+
+func (_c *MockUrlRepository_RetrieveClickStats_Call) Run(run func(ctx context.Context, urlID int64, dimension entity.ClickDimension, limit int)) *MockUrlRepository_RetrieveClickStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(entity.ClickDimension), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveClickStats_Call) Return(_a0 []entity.StatCount, _a1 error) *MockUrlRepository_RetrieveClickStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveClickStats_Call) RunAndReturn(run func(context.Context, int64, entity.ClickDimension, int) ([]entity.StatCount, error)) *MockUrlRepository_RetrieveClickStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RetrieveManyByShortCodes provides a mock function with given fields: ctx, shortCodes
+func (_m *MockUrlRepository) RetrieveManyByShortCodes(ctx context.Context, shortCodes []string) (map[string]*entity.URL, error) {
+	ret := _m.Called(ctx, shortCodes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RetrieveManyByShortCodes")
+	}
+
+	var r0 map[string]*entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) (map[string]*entity.URL, error)); ok {
+		return rf(ctx, shortCodes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) map[string]*entity.URL); ok {
+		r0 = rf(ctx, shortCodes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, shortCodes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_RetrieveManyByShortCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveManyByShortCodes'
+type MockUrlRepository_RetrieveManyByShortCodes_Call struct {
+	*mock.Call
+}
+
+// RetrieveManyByShortCodes is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCodes []string
+func (_e *MockUrlRepository_Expecter) RetrieveManyByShortCodes(ctx interface{}, shortCodes interface{}) *MockUrlRepository_RetrieveManyByShortCodes_Call {
+	return &MockUrlRepository_RetrieveManyByShortCodes_Call{Call: _e.mock.On("RetrieveManyByShortCodes", ctx, shortCodes)}
+}
+
+func (_c *MockUrlRepository_RetrieveManyByShortCodes_Call) Run(run func(ctx context.Context, shortCodes []string)) *MockUrlRepository_RetrieveManyByShortCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveManyByShortCodes_Call) Return(_a0 map[string]*entity.URL, _a1 error) *MockUrlRepository_RetrieveManyByShortCodes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_RetrieveManyByShortCodes_Call) RunAndReturn(run func(context.Context, []string) (map[string]*entity.URL, error)) *MockUrlRepository_RetrieveManyByShortCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Save provides a mock function with given fields: ctx, shortCode, originalURL, note, tags, owner
+func (_m *MockUrlRepository) Save(ctx context.Context, shortCode string, originalURL string, note string, tags []string, owner string) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, originalURL, note, tags, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Save")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, originalURL, note, tags, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, originalURL, note, tags, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []string, string) error); ok {
+		r1 = rf(ctx, shortCode, originalURL, note, tags, owner)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-type MockUrlRepository_Expecter struct {
-	mock *mock.Mock
+// MockUrlRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
+type MockUrlRepository_Save_Call struct {
+	*mock.Call
 }
 
-func (_m *MockUrlRepository) EXPECT() *MockUrlRepository_Expecter {
-	return &MockUrlRepository_Expecter{mock: &_m.Mock}
+// Save is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - originalURL string
+//   - note string
+//   - tags []string
+//   - owner string
+func (_e *MockUrlRepository_Expecter) Save(ctx interface{}, shortCode interface{}, originalURL interface{}, note interface{}, tags interface{}, owner interface{}) *MockUrlRepository_Save_Call {
+	return &MockUrlRepository_Save_Call{Call: _e.mock.On("Save", ctx, shortCode, originalURL, note, tags, owner)}
 }
 
-// Remove provides a mock function with given fields: ctx, shortCode
-func (_m *MockUrlRepository) Remove(ctx context.Context, shortCode string) error {
-	ret := _m.Called(ctx, shortCode)
+func (_c *MockUrlRepository_Save_Call) Run(run func(ctx context.Context, shortCode string, originalURL string, note string, tags []string, owner string)) *MockUrlRepository_Save_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]string), args[5].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Save_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_Save_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_Save_Call) RunAndReturn(run func(context.Context, string, string, string, []string, string) (*entity.URL, error)) *MockUrlRepository_Save_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAccessEvent provides a mock function with given fields: ctx, urlID, ip, referrer
+func (_m *MockUrlRepository) SaveAccessEvent(ctx context.Context, urlID int64, ip string, referrer string) error {
+	ret := _m.Called(ctx, urlID, ip, referrer)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Remove")
+		panic("no return value specified for SaveAccessEvent")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
-		r0 = rf(ctx, shortCode)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) error); ok {
+		r0 = rf(ctx, urlID, ip, referrer)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -40,58 +1251,60 @@ func (_m *MockUrlRepository) Remove(ctx context.Context, shortCode string) error
 	return r0
 }
 
-// MockUrlRepository_Remove_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Remove'
-type MockUrlRepository_Remove_Call struct {
+// MockUrlRepository_SaveAccessEvent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAccessEvent'
+type MockUrlRepository_SaveAccessEvent_Call struct {
 	*mock.Call
 }
 
-// Remove is a helper method to define mock.On call
+// SaveAccessEvent is a helper method to define mock.On call
 //   - ctx context.Context
-//   - shortCode string
-func (_e *MockUrlRepository_Expecter) Remove(ctx interface{}, shortCode interface{}) *MockUrlRepository_Remove_Call {
-	return &MockUrlRepository_Remove_Call{Call: _e.mock.On("Remove", ctx, shortCode)}
+//   - urlID int64
+//   - ip string
+//   - referrer string
+func (_e *MockUrlRepository_Expecter) SaveAccessEvent(ctx interface{}, urlID interface{}, ip interface{}, referrer interface{}) *MockUrlRepository_SaveAccessEvent_Call {
+	return &MockUrlRepository_SaveAccessEvent_Call{Call: _e.mock.On("SaveAccessEvent", ctx, urlID, ip, referrer)}
 }
 
-func (_c *MockUrlRepository_Remove_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_Remove_Call {
+func (_c *MockUrlRepository_SaveAccessEvent_Call) Run(run func(ctx context.Context, urlID int64, ip string, referrer string)) *MockUrlRepository_SaveAccessEvent_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(string))
 	})
 	return _c
 }
 
-func (_c *MockUrlRepository_Remove_Call) Return(_a0 error) *MockUrlRepository_Remove_Call {
+func (_c *MockUrlRepository_SaveAccessEvent_Call) Return(_a0 error) *MockUrlRepository_SaveAccessEvent_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MockUrlRepository_Remove_Call) RunAndReturn(run func(context.Context, string) error) *MockUrlRepository_Remove_Call {
+func (_c *MockUrlRepository_SaveAccessEvent_Call) RunAndReturn(run func(context.Context, int64, string, string) error) *MockUrlRepository_SaveAccessEvent_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RetrieveAndUpdateStats provides a mock function with given fields: ctx, shortCode
-func (_m *MockUrlRepository) RetrieveAndUpdateStats(ctx context.Context, shortCode string) (*entity.URL, error) {
-	ret := _m.Called(ctx, shortCode)
+// SaveAlias provides a mock function with given fields: ctx, shortCode, alias
+func (_m *MockUrlRepository) SaveAlias(ctx context.Context, shortCode string, alias string) (*entity.URLAlias, error) {
+	ret := _m.Called(ctx, shortCode, alias)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RetrieveAndUpdateStats")
+		panic("no return value specified for SaveAlias")
 	}
 
-	var r0 *entity.URL
+	var r0 *entity.URLAlias
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
-		return rf(ctx, shortCode)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URLAlias, error)); ok {
+		return rf(ctx, shortCode, alias)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
-		r0 = rf(ctx, shortCode)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URLAlias); ok {
+		r0 = rf(ctx, shortCode, alias)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).(*entity.URLAlias)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, shortCode)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, shortCode, alias)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -99,58 +1312,107 @@ func (_m *MockUrlRepository) RetrieveAndUpdateStats(ctx context.Context, shortCo
 	return r0, r1
 }
 
-// MockUrlRepository_RetrieveAndUpdateStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveAndUpdateStats'
-type MockUrlRepository_RetrieveAndUpdateStats_Call struct {
+// MockUrlRepository_SaveAlias_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAlias'
+type MockUrlRepository_SaveAlias_Call struct {
 	*mock.Call
 }
 
-// RetrieveAndUpdateStats is a helper method to define mock.On call
+// SaveAlias is a helper method to define mock.On call
 //   - ctx context.Context
 //   - shortCode string
-func (_e *MockUrlRepository_Expecter) RetrieveAndUpdateStats(ctx interface{}, shortCode interface{}) *MockUrlRepository_RetrieveAndUpdateStats_Call {
-	return &MockUrlRepository_RetrieveAndUpdateStats_Call{Call: _e.mock.On("RetrieveAndUpdateStats", ctx, shortCode)}
+//   - alias string
+func (_e *MockUrlRepository_Expecter) SaveAlias(ctx interface{}, shortCode interface{}, alias interface{}) *MockUrlRepository_SaveAlias_Call {
+	return &MockUrlRepository_SaveAlias_Call{Call: _e.mock.On("SaveAlias", ctx, shortCode, alias)}
 }
 
-func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+func (_c *MockUrlRepository_SaveAlias_Call) Run(run func(ctx context.Context, shortCode string, alias string)) *MockUrlRepository_SaveAlias_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+func (_c *MockUrlRepository_SaveAlias_Call) Return(_a0 *entity.URLAlias, _a1 error) *MockUrlRepository_SaveAlias_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlRepository_RetrieveAndUpdateStats_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlRepository_RetrieveAndUpdateStats_Call {
+func (_c *MockUrlRepository_SaveAlias_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URLAlias, error)) *MockUrlRepository_SaveAlias_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// RetrieveByShortCode provides a mock function with given fields: ctx, shortCode
-func (_m *MockUrlRepository) RetrieveByShortCode(ctx context.Context, shortCode string) (*entity.URL, error) {
-	ret := _m.Called(ctx, shortCode)
+// SetCreator provides a mock function with given fields: ctx, shortCode, creator
+func (_m *MockUrlRepository) SetCreator(ctx context.Context, shortCode string, creator entity.Creator) error {
+	ret := _m.Called(ctx, shortCode, creator)
 
 	if len(ret) == 0 {
-		panic("no return value specified for RetrieveByShortCode")
+		panic("no return value specified for SetCreator")
 	}
 
-	var r0 *entity.URL
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.Creator) error); ok {
+		r0 = rf(ctx, shortCode, creator)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_SetCreator_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetCreator'
+type MockUrlRepository_SetCreator_Call struct {
+	*mock.Call
+}
+
+// SetCreator is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - creator entity.Creator
+func (_e *MockUrlRepository_Expecter) SetCreator(ctx interface{}, shortCode interface{}, creator interface{}) *MockUrlRepository_SetCreator_Call {
+	return &MockUrlRepository_SetCreator_Call{Call: _e.mock.On("SetCreator", ctx, shortCode, creator)}
+}
+
+func (_c *MockUrlRepository_SetCreator_Call) Run(run func(ctx context.Context, shortCode string, creator entity.Creator)) *MockUrlRepository_SetCreator_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.Creator))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_SetCreator_Call) Return(_a0 error) *MockUrlRepository_SetCreator_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_SetCreator_Call) RunAndReturn(run func(context.Context, string, entity.Creator) error) *MockUrlRepository_SetCreator_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Summary provides a mock function with given fields: ctx
+func (_m *MockUrlRepository) Summary(ctx context.Context) (*entity.Summary, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Summary")
+	}
+
+	var r0 *entity.Summary
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) (*entity.URL, error)); ok {
-		return rf(ctx, shortCode)
+	if rf, ok := ret.Get(0).(func(context.Context) (*entity.Summary, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) *entity.URL); ok {
-		r0 = rf(ctx, shortCode)
+	if rf, ok := ret.Get(0).(func(context.Context) *entity.Summary); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).(*entity.Summary)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, shortCode)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -158,58 +1420,57 @@ func (_m *MockUrlRepository) RetrieveByShortCode(ctx context.Context, shortCode
 	return r0, r1
 }
 
-// MockUrlRepository_RetrieveByShortCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RetrieveByShortCode'
-type MockUrlRepository_RetrieveByShortCode_Call struct {
+// MockUrlRepository_Summary_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Summary'
+type MockUrlRepository_Summary_Call struct {
 	*mock.Call
 }
 
-// RetrieveByShortCode is a helper method to define mock.On call
+// Summary is a helper method to define mock.On call
 //   - ctx context.Context
-//   - shortCode string
-func (_e *MockUrlRepository_Expecter) RetrieveByShortCode(ctx interface{}, shortCode interface{}) *MockUrlRepository_RetrieveByShortCode_Call {
-	return &MockUrlRepository_RetrieveByShortCode_Call{Call: _e.mock.On("RetrieveByShortCode", ctx, shortCode)}
+func (_e *MockUrlRepository_Expecter) Summary(ctx interface{}) *MockUrlRepository_Summary_Call {
+	return &MockUrlRepository_Summary_Call{Call: _e.mock.On("Summary", ctx)}
 }
 
-func (_c *MockUrlRepository_RetrieveByShortCode_Call) Run(run func(ctx context.Context, shortCode string)) *MockUrlRepository_RetrieveByShortCode_Call {
+func (_c *MockUrlRepository_Summary_Call) Run(run func(ctx context.Context)) *MockUrlRepository_Summary_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockUrlRepository_RetrieveByShortCode_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_RetrieveByShortCode_Call {
+func (_c *MockUrlRepository_Summary_Call) Return(_a0 *entity.Summary, _a1 error) *MockUrlRepository_Summary_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlRepository_RetrieveByShortCode_Call) RunAndReturn(run func(context.Context, string) (*entity.URL, error)) *MockUrlRepository_RetrieveByShortCode_Call {
+func (_c *MockUrlRepository_Summary_Call) RunAndReturn(run func(context.Context) (*entity.Summary, error)) *MockUrlRepository_Summary_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// Save provides a mock function with given fields: ctx, shortCode, originalURL
-func (_m *MockUrlRepository) Save(ctx context.Context, shortCode string, originalURL string) (*entity.URL, error) {
-	ret := _m.Called(ctx, shortCode, originalURL)
+// SummaryFromEvents provides a mock function with given fields: ctx
+func (_m *MockUrlRepository) SummaryFromEvents(ctx context.Context) (*entity.Summary, error) {
+	ret := _m.Called(ctx)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Save")
+		panic("no return value specified for SummaryFromEvents")
 	}
 
-	var r0 *entity.URL
+	var r0 *entity.Summary
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*entity.URL, error)); ok {
-		return rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context) (*entity.Summary, error)); ok {
+		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) *entity.URL); ok {
-		r0 = rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(0).(func(context.Context) *entity.Summary); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*entity.URL)
+			r0 = ret.Get(0).(*entity.Summary)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, shortCode, originalURL)
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -217,32 +1478,30 @@ func (_m *MockUrlRepository) Save(ctx context.Context, shortCode string, origina
 	return r0, r1
 }
 
-// MockUrlRepository_Save_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Save'
-type MockUrlRepository_Save_Call struct {
+// MockUrlRepository_SummaryFromEvents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SummaryFromEvents'
+type MockUrlRepository_SummaryFromEvents_Call struct {
 	*mock.Call
 }
 
-// Save is a helper method to define mock.On call
+// SummaryFromEvents is a helper method to define mock.On call
 //   - ctx context.Context
-//   - shortCode string
-//   - originalURL string
-func (_e *MockUrlRepository_Expecter) Save(ctx interface{}, shortCode interface{}, originalURL interface{}) *MockUrlRepository_Save_Call {
-	return &MockUrlRepository_Save_Call{Call: _e.mock.On("Save", ctx, shortCode, originalURL)}
+func (_e *MockUrlRepository_Expecter) SummaryFromEvents(ctx interface{}) *MockUrlRepository_SummaryFromEvents_Call {
+	return &MockUrlRepository_SummaryFromEvents_Call{Call: _e.mock.On("SummaryFromEvents", ctx)}
 }
 
-func (_c *MockUrlRepository_Save_Call) Run(run func(ctx context.Context, shortCode string, originalURL string)) *MockUrlRepository_Save_Call {
+func (_c *MockUrlRepository_SummaryFromEvents_Call) Run(run func(ctx context.Context)) *MockUrlRepository_SummaryFromEvents_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context))
 	})
 	return _c
 }
 
-func (_c *MockUrlRepository_Save_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_Save_Call {
+func (_c *MockUrlRepository_SummaryFromEvents_Call) Return(_a0 *entity.Summary, _a1 error) *MockUrlRepository_SummaryFromEvents_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *MockUrlRepository_Save_Call) RunAndReturn(run func(context.Context, string, string) (*entity.URL, error)) *MockUrlRepository_Save_Call {
+func (_c *MockUrlRepository_SummaryFromEvents_Call) RunAndReturn(run func(context.Context) (*entity.Summary, error)) *MockUrlRepository_SummaryFromEvents_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -307,6 +1566,183 @@ func (_c *MockUrlRepository_Update_Call) RunAndReturn(run func(context.Context,
 	return _c
 }
 
+// UpdateFields provides a mock function with given fields: ctx, shortCode, update
+func (_m *MockUrlRepository) UpdateFields(ctx context.Context, shortCode string, update entity.URLUpdate) (*entity.URL, error) {
+	ret := _m.Called(ctx, shortCode, update)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateFields")
+	}
+
+	var r0 *entity.URL
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.URLUpdate) (*entity.URL, error)); ok {
+		return rf(ctx, shortCode, update)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, entity.URLUpdate) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, update)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, entity.URLUpdate) error); ok {
+		r1 = rf(ctx, shortCode, update)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockUrlRepository_UpdateFields_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateFields'
+type MockUrlRepository_UpdateFields_Call struct {
+	*mock.Call
+}
+
+// UpdateFields is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - update entity.URLUpdate
+func (_e *MockUrlRepository_Expecter) UpdateFields(ctx interface{}, shortCode interface{}, update interface{}) *MockUrlRepository_UpdateFields_Call {
+	return &MockUrlRepository_UpdateFields_Call{Call: _e.mock.On("UpdateFields", ctx, shortCode, update)}
+}
+
+func (_c *MockUrlRepository_UpdateFields_Call) Run(run func(ctx context.Context, shortCode string, update entity.URLUpdate)) *MockUrlRepository_UpdateFields_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(entity.URLUpdate))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_UpdateFields_Call) Return(_a0 *entity.URL, _a1 error) *MockUrlRepository_UpdateFields_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockUrlRepository_UpdateFields_Call) RunAndReturn(run func(context.Context, string, entity.URLUpdate) (*entity.URL, error)) *MockUrlRepository_UpdateFields_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Upsert provides a mock function with given fields: ctx, shortCode, originalURL, note, tags, owner
+func (_m *MockUrlRepository) Upsert(ctx context.Context, shortCode string, originalURL string, note string, tags []string, owner string) (*entity.URL, bool, error) {
+	ret := _m.Called(ctx, shortCode, originalURL, note, tags, owner)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 *entity.URL
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) (*entity.URL, bool, error)); ok {
+		return rf(ctx, shortCode, originalURL, note, tags, owner)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, []string, string) *entity.URL); ok {
+		r0 = rf(ctx, shortCode, originalURL, note, tags, owner)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.URL)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, string, []string, string) bool); ok {
+		r1 = rf(ctx, shortCode, originalURL, note, tags, owner)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, string, []string, string) error); ok {
+		r2 = rf(ctx, shortCode, originalURL, note, tags, owner)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// MockUrlRepository_Upsert_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Upsert'
+type MockUrlRepository_Upsert_Call struct {
+	*mock.Call
+}
+
+// Upsert is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shortCode string
+//   - originalURL string
+//   - note string
+//   - tags []string
+//   - owner string
+func (_e *MockUrlRepository_Expecter) Upsert(ctx interface{}, shortCode interface{}, originalURL interface{}, note interface{}, tags interface{}, owner interface{}) *MockUrlRepository_Upsert_Call {
+	return &MockUrlRepository_Upsert_Call{Call: _e.mock.On("Upsert", ctx, shortCode, originalURL, note, tags, owner)}
+}
+
+func (_c *MockUrlRepository_Upsert_Call) Run(run func(ctx context.Context, shortCode string, originalURL string, note string, tags []string, owner string)) *MockUrlRepository_Upsert_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].([]string), args[5].(string))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_Upsert_Call) Return(_a0 *entity.URL, _a1 bool, _a2 error) *MockUrlRepository_Upsert_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *MockUrlRepository_Upsert_Call) RunAndReturn(run func(context.Context, string, string, string, []string, string) (*entity.URL, bool, error)) *MockUrlRepository_Upsert_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// WithTx provides a mock function with given fields: ctx, fn
+func (_m *MockUrlRepository) WithTx(ctx context.Context, fn func(context.Context) error) error {
+	ret := _m.Called(ctx, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WithTx")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(context.Context) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MockUrlRepository_WithTx_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'WithTx'
+type MockUrlRepository_WithTx_Call struct {
+	*mock.Call
+}
+
+// WithTx is a helper method to define mock.On call
+//   - ctx context.Context
+//   - fn func(context.Context) error
+func (_e *MockUrlRepository_Expecter) WithTx(ctx interface{}, fn interface{}) *MockUrlRepository_WithTx_Call {
+	return &MockUrlRepository_WithTx_Call{Call: _e.mock.On("WithTx", ctx, fn)}
+}
+
+func (_c *MockUrlRepository_WithTx_Call) Run(run func(ctx context.Context, fn func(context.Context) error)) *MockUrlRepository_WithTx_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(func(context.Context) error))
+	})
+	return _c
+}
+
+func (_c *MockUrlRepository_WithTx_Call) Return(_a0 error) *MockUrlRepository_WithTx_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MockUrlRepository_WithTx_Call) RunAndReturn(run func(context.Context, func(context.Context) error) error) *MockUrlRepository_WithTx_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMockUrlRepository creates a new instance of MockUrlRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockUrlRepository(t interface {
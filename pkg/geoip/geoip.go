@@ -0,0 +1,57 @@
+// Package geoip provides country lookups for IP addresses backed by a MaxMind (mmdb) database.
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record is the subset of a MaxMind country record that is decoded on lookup.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Reader resolves IP addresses to countries using a MaxMind database.
+type Reader struct {
+	db *maxminddb.Reader
+}
+
+// Open opens the MaxMind database at the specified path.
+func Open(path string) (*Reader, error) {
+	const op = "geoip.Open"
+
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open database: %w", op, err)
+	}
+
+	return &Reader{db: db}, nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country the IP address belongs to.
+// It returns an empty string if the IP address is invalid or not found in the database.
+func (r *Reader) Country(ip string) (string, error) {
+	const op = "geoip.Reader.Country"
+
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return "", nil
+	}
+
+	var rec record
+
+	if err := r.db.Lookup(parsedIP, &rec); err != nil {
+		return "", fmt.Errorf("%s: failed to lookup ip: %w", op, err)
+	}
+
+	return rec.Country.ISOCode, nil
+}
+
+// Close closes the underlying database.
+func (r *Reader) Close() error {
+	return r.db.Close()
+}
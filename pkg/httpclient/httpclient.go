@@ -0,0 +1,148 @@
+// Package httpclient creates the HTTP client used for the outbound requests of the service, e.g. link checks
+// and fetching signing keys, with timeouts and limits that keep a slow or misbehaving server from holding up
+// the requests waiting on it.
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrTooManyRedirects is returned when a request is redirected more often than allowed.
+	ErrTooManyRedirects = errors.New("too many redirects")
+	// ErrBodyTooLarge is returned when reading a response body beyond the allowed size.
+	ErrBodyTooLarge = errors.New("response body too large")
+)
+
+const (
+	defaultConnectTimeout = 5 * time.Second
+	defaultReadTimeout    = 10 * time.Second
+	defaultMaxRedirects   = 10
+	defaultMaxBodySize    = 1 << 20
+)
+
+// settings holds the timeouts and limits of a client.
+type settings struct {
+	connectTimeout time.Duration
+	readTimeout    time.Duration
+	maxRedirects   int
+	maxBodySize    int64
+}
+
+// Option defines a functional option for configuring the client created by New.
+type Option func(*settings)
+
+// WithConnectTimeout limits the time spent establishing a connection, including the TLS handshake.
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.connectTimeout = timeout
+	}
+}
+
+// WithReadTimeout limits the time waited for the response headers once a request is sent.
+func WithReadTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.readTimeout = timeout
+	}
+}
+
+// WithMaxRedirects limits the number of redirects followed for a request.
+func WithMaxRedirects(n int) Option {
+	return func(s *settings) {
+		s.maxRedirects = n
+	}
+}
+
+// WithMaxBodySize limits the number of bytes read from a response body.
+func WithMaxBodySize(size int64) Option {
+	return func(s *settings) {
+		s.maxBodySize = size
+	}
+}
+
+// New creates an HTTP client with the timeouts and limits given by the options. By default, connecting
+// times out after 5 seconds, waiting for a response after 10 seconds, at most 10 redirects are followed
+// and at most 1 MiB of a response body is read. A request including reading its body must complete within
+// the sum of the connect and read timeouts, which covers servers sending a body slowly.
+// Requests fail with ErrTooManyRedirects when redirected too often, and reading a response body fails
+// with ErrBodyTooLarge beyond the maximum size.
+func New(opts ...Option) *http.Client {
+	s := settings{
+		connectTimeout: defaultConnectTimeout,
+		readTimeout:    defaultReadTimeout,
+		maxRedirects:   defaultMaxRedirects,
+		maxBodySize:    defaultMaxBodySize,
+	}
+
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: s.connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = s.connectTimeout
+	transport.ResponseHeaderTimeout = s.readTimeout
+
+	return &http.Client{
+		Transport: &limitedTransport{base: transport, maxBodySize: s.maxBodySize},
+		Timeout:   s.connectTimeout + s.readTimeout,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) > s.maxRedirects {
+				return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, s.maxRedirects)
+			}
+
+			return nil
+		},
+	}
+}
+
+// limitedTransport caps the size of the response bodies of the requests sent by its base transport.
+type limitedTransport struct {
+	base        http.RoundTripper
+	maxBodySize int64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &limitedBody{ReadCloser: resp.Body, remaining: t.maxBodySize}
+
+	return resp, nil
+}
+
+// limitedBody is a response body that fails with ErrBodyTooLarge once more than the remaining bytes are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// A body of exactly the maximum size is fine, so only fail if there's more of it.
+		var buf [1]byte
+
+		n, err := b.ReadCloser.Read(buf[:])
+		if n > 0 {
+			return 0, ErrBodyTooLarge
+		}
+
+		return 0, err
+	}
+
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+
+	return n, err
+}
@@ -0,0 +1,90 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("read timeout", func(t *testing.T) {
+		release := make(chan struct{})
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		client := New(WithReadTimeout(50 * time.Millisecond))
+
+		start := time.Now()
+		_, err := client.Get(srv.URL)
+
+		var netErr net.Error
+		require.ErrorAs(t, err, &netErr)
+		assert.True(t, netErr.Timeout())
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("too many redirects", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/", http.StatusFound)
+		}))
+		defer srv.Close()
+
+		_, err := New(WithMaxRedirects(2)).Get(srv.URL)
+
+		assert.ErrorIs(t, err, ErrTooManyRedirects)
+	})
+
+	t.Run("body too large", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, strings.Repeat("a", 11))
+		}))
+		defer srv.Close()
+
+		resp, err := New(WithMaxBodySize(10)).Get(srv.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		_, err = io.ReadAll(resp.Body)
+
+		assert.ErrorIs(t, err, ErrBodyTooLarge)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/" {
+				http.Redirect(w, r, "/target", http.StatusFound)
+				return
+			}
+
+			_, _ = io.WriteString(w, strings.Repeat("a", 10))
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := New(WithMaxRedirects(1), WithMaxBodySize(10)).Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+
+		assert.NoError(t, err)
+		assert.Equal(t, strings.Repeat("a", 10), string(body))
+	})
+}
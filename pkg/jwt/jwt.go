@@ -0,0 +1,191 @@
+// Package jwt verifies JSON Web Tokens (https://www.rfc-editor.org/rfc/rfc7519) signed with HS256 or RS256,
+// e.g. access tokens issued by an identity provider. RS256 keys may be fetched from a JWKS endpoint.
+// Tokens are parsed and verified with github.com/golang-jwt/jwt/v5, and JWKS endpoints are served
+// by github.com/MicahParks/keyfunc/v3.
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v3"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/time/rate"
+)
+
+var (
+	// ErrInvalidToken is returned when a token is malformed, its signature doesn't match,
+	// or it is signed with an algorithm or key that isn't accepted.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenExpired is returned when a token has expired or isn't valid yet.
+	ErrTokenExpired = errors.New("token expired")
+)
+
+const (
+	// jwksRefreshInterval is the interval at which the keys of a JWKS endpoint are fetched again.
+	jwksRefreshInterval = time.Hour
+	// jwksUnknownKeyInterval is the minimum interval between fetching the keys of a JWKS endpoint
+	// again when a token is signed with an unknown key, so that forged tokens can't flood the endpoint.
+	jwksUnknownKeyInterval = time.Minute
+	// jwksFetchTimeout bounds fetching the keys of a JWKS endpoint, including waiting for the fetch
+	// to be allowed when a token is signed with an unknown key.
+	jwksFetchTimeout = 5 * time.Second
+)
+
+// Claims are the registered claims of a token used by the service.
+type Claims struct {
+	Subject string // Subject identifies the owner of the token, e.g. a user of the identity provider.
+}
+
+// Option defines a functional option for configuring Verifier.
+type Option func(*Verifier)
+
+// WithHMACSecret accepts tokens signed with HS256 using the secret.
+func WithHMACSecret(secret []byte) Option {
+	return func(v *Verifier) {
+		v.secret = secret
+	}
+}
+
+// WithRSAPublicKey accepts tokens signed with RS256 using the private key of the public key.
+func WithRSAPublicKey(key *rsa.PublicKey) Option {
+	return func(v *Verifier) {
+		v.publicKey = key
+	}
+}
+
+// WithJWKS accepts tokens signed with RS256 using the private key of one of the keys published
+// at the JWKS URL. The keys are fetched with the client when the verifier is created, every hour,
+// and again when a token is signed with an unknown key, at most once per minute.
+func WithJWKS(url string, client *http.Client) Option {
+	return func(v *Verifier) {
+		v.jwksURL = url
+		v.jwksClient = client
+	}
+}
+
+// Verifier verifies tokens signed with the accepted algorithms and keys. It is safe for concurrent use.
+type Verifier struct {
+	secret     []byte
+	publicKey  *rsa.PublicKey
+	jwksURL    string
+	jwksClient *http.Client
+	jwks       keyfunc.Keyfunc
+	methods    []string
+	now        func() time.Time
+}
+
+// New creates a new instance of Verifier accepting the algorithms and keys given by the options.
+// Tokens are rejected if no algorithm is accepted. The keys of a JWKS endpoint are refreshed
+// in the background until ctx is done. Failing to fetch them on creation isn't an error,
+// since they are fetched again when a token is verified.
+func New(ctx context.Context, opts ...Option) (*Verifier, error) {
+	const op = "jwt.New"
+
+	v := &Verifier{now: time.Now}
+
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.jwksURL != "" {
+		jwks, err := keyfunc.NewDefaultOverrideCtx(ctx, []string{v.jwksURL}, keyfunc.Override{
+			Client:            v.jwksClient,
+			HTTPTimeout:       jwksFetchTimeout,
+			RateLimitWaitMax:  jwksFetchTimeout,
+			RefreshInterval:   jwksRefreshInterval,
+			RefreshUnknownKID: rate.NewLimiter(rate.Every(jwksUnknownKeyInterval), 1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to set up jwks: %w", op, err)
+		}
+
+		v.jwks = jwks
+	}
+
+	if v.secret != nil {
+		v.methods = append(v.methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if v.publicKey != nil || v.jwks != nil {
+		v.methods = append(v.methods, jwt.SigningMethodRS256.Alg())
+	}
+
+	return v, nil
+}
+
+// Verify checks the signature of the token and that the token has neither expired nor is used
+// before it becomes valid, and returns its claims. It returns ErrInvalidToken or ErrTokenExpired
+// if the token is rejected.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	const op = "jwt.Verifier.Verify"
+
+	if len(v.methods) == 0 {
+		return nil, fmt.Errorf("%s: %w: no algorithm accepted", op, ErrInvalidToken)
+	}
+
+	var claims jwt.RegisteredClaims
+
+	// Only the algorithms a key is configured for are accepted, so that tokens can't pick a weaker one.
+	_, err := jwt.ParseWithClaims(token, &claims, v.keyfunc(ctx), jwt.WithValidMethods(v.methods), jwt.WithTimeFunc(v.now))
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) || errors.Is(err, jwt.ErrTokenNotValidYet) {
+			return nil, fmt.Errorf("%s: %w: %w", op, ErrTokenExpired, err)
+		}
+
+		return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	return &Claims{Subject: claims.Subject}, nil
+}
+
+// keyfunc returns the function providing the key a token is verified with, chosen by its algorithm.
+// RS256 tokens are verified with the public key if it is set, and with the keys of the JWKS endpoint otherwise.
+func (v *Verifier) keyfunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		switch token.Method {
+		case jwt.SigningMethodHS256:
+			return v.secret, nil
+		case jwt.SigningMethodRS256:
+			if v.publicKey != nil {
+				return v.publicKey, nil
+			}
+
+			return v.jwks.KeyfuncCtx(ctx)(token)
+		default:
+			return nil, fmt.Errorf("algorithm %q not accepted", token.Method.Alg())
+		}
+	}
+}
+
+// ParseRSAPublicKey parses a PEM-encoded RSA public key in PKIX or PKCS #1 form.
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	const op = "jwt.ParseRSAPublicKey"
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no pem block found", op)
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an rsa public key", op)
+	}
+
+	return rsaKey, nil
+}
@@ -0,0 +1,250 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var secret = []byte("secret")
+
+// encodeSegment encodes v as a base64url-encoded JSON segment of a token.
+func encodeSegment(t *testing.T, v any) string {
+	t.Helper()
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signHS256 creates a token with the claims signed with HS256 using the secret.
+func signHS256(t *testing.T, claims map[string]any, secret []byte) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(t, claims)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signRS256 creates a token with the claims signed with RS256 using the key.
+func signRS256(t *testing.T, claims map[string]any, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// newVerifier creates a verifier with the options, failing the test if it can't be created.
+func newVerifier(t *testing.T, opts ...Option) *Verifier {
+	t.Helper()
+
+	v, err := New(context.Background(), opts...)
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	v := newVerifier(t, WithHMACSecret(secret))
+	exp := time.Now().Add(time.Hour).Unix()
+
+	t.Run("valid token", func(t *testing.T) {
+		claims, err := v.Verify(context.Background(), signHS256(t, map[string]any{"sub": "user-1", "exp": exp}, secret))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		token := signHS256(t, map[string]any{"sub": "user-1", "exp": time.Now().Add(-time.Minute).Unix()}, secret)
+
+		_, err := v.Verify(context.Background(), token)
+
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("token not valid yet", func(t *testing.T) {
+		token := signHS256(t, map[string]any{"sub": "user-1", "nbf": time.Now().Add(time.Minute).Unix()}, secret)
+
+		_, err := v.Verify(context.Background(), token)
+
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		token := signHS256(t, map[string]any{"sub": "user-1", "exp": exp}, []byte("wrong"))
+
+		_, err := v.Verify(context.Background(), token)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("malformed token", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "a.b.c", "a.b"} {
+			_, err := v.Verify(context.Background(), token)
+
+			assert.ErrorIs(t, err, ErrInvalidToken, token)
+		}
+	})
+
+	t.Run("algorithm not accepted", func(t *testing.T) {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + encodeSegment(t, map[string]any{"sub": "user-1"}) + "."
+
+		for _, token := range []string{unsigned, signRS256(t, map[string]any{"sub": "user-1"}, key, "")} {
+			_, err := v.Verify(context.Background(), token)
+
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}
+	})
+}
+
+func TestVerifier_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	t.Run("public key", func(t *testing.T) {
+		v := newVerifier(t, WithRSAPublicKey(&key.PublicKey))
+
+		claims, err := v.Verify(context.Background(), signRS256(t, map[string]any{"sub": "user-1", "exp": exp}, key, ""))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+
+		_, err = v.Verify(context.Background(), signRS256(t, map[string]any{"sub": "user-1", "exp": time.Now().Unix()}, key, ""))
+
+		assert.ErrorIs(t, err, ErrTokenExpired)
+
+		// A token signed with HS256 using the public key as the secret must not be accepted.
+		_, err = v.Verify(context.Background(), signHS256(t, map[string]any{"sub": "user-1"}, key.PublicKey.N.Bytes()))
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	// jwksHandler publishes the public key with the key ID, answering the first failures requests with 500.
+	jwksHandler := func(kid string, failures int32, fetches *atomic.Int32) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			if fetches.Add(1) <= failures {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"keys": []map[string]string{
+					{
+						"kty": "RSA",
+						"kid": kid,
+						"use": "sig",
+						"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+						"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+					},
+				},
+			})
+		}
+	}
+
+	t.Run("jwks", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		var fetches atomic.Int32
+
+		server := httptest.NewServer(jwksHandler("key-1", 0, &fetches))
+		t.Cleanup(server.Close)
+
+		v := newVerifier(t, WithJWKS(server.URL, server.Client()))
+
+		claims, err := v.Verify(context.Background(), signRS256(t, map[string]any{"sub": "user-1", "exp": exp}, key, "key-1"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+
+		_, err = v.Verify(context.Background(), signRS256(t, map[string]any{"sub": "user-1", "exp": exp}, otherKey, "key-1"))
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		assert.Equal(t, int32(1), fetches.Load())
+
+		// An unknown key is looked up once, and not again right after.
+		for range 3 {
+			_, err = v.Verify(context.Background(), signRS256(t, map[string]any{"sub": "user-1", "exp": exp}, otherKey, "key-3"))
+
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		}
+
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+
+	t.Run("jwks unavailable on creation", func(t *testing.T) {
+		var fetches atomic.Int32
+
+		server := httptest.NewServer(jwksHandler("key-1", 1, &fetches))
+		t.Cleanup(server.Close)
+
+		v := newVerifier(t, WithJWKS(server.URL, server.Client()))
+		token := signRS256(t, map[string]any{"sub": "user-1", "exp": exp}, key, "key-1")
+
+		// A canceled request doesn't keep the keys from being fetched for the next one.
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := v.Verify(ctx, token)
+
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		claims, err := v.Verify(context.Background(), token)
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", claims.Subject)
+		assert.Equal(t, int32(2), fetches.Load())
+	})
+}
+
+func TestParseRSAPublicKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkix, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	for _, block := range []*pem.Block{
+		{Type: "PUBLIC KEY", Bytes: pkix},
+		{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)},
+	} {
+		parsed, err := ParseRSAPublicKey(pem.EncodeToMemory(block))
+
+		require.NoError(t, err)
+		assert.True(t, key.PublicKey.Equal(parsed))
+	}
+
+	_, err = ParseRSAPublicKey([]byte("not a key"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+// Package linkcheck checks whether URLs are reachable by sending HEAD requests to them.
+package linkcheck
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Checker checks whether URLs are reachable. It is safe for concurrent use.
+type Checker struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+// New creates a new instance of Checker sending the requests with the client and giving up on each URL
+// after the timeout, including the redirects followed for it.
+func New(client *http.Client, timeout time.Duration) *Checker {
+	return &Checker{client: client, timeout: timeout}
+}
+
+// Check sends a HEAD request to the URL, following redirects, and returns the status code of the response.
+// Servers that don't support HEAD requests are sent a GET request instead, whose body isn't read.
+// It returns an error if no response was received, e.g. in time.
+func (c *Checker) Check(ctx context.Context, url string) (int, error) {
+	const op = "linkcheck.Checker.Check"
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	statusCode, err := c.do(ctx, http.MethodHead, url)
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented) {
+		statusCode, err = c.do(ctx, http.MethodGet, url)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return statusCode, nil
+}
+
+// do sends a request with the method to the URL and returns the status code of the response.
+func (c *Checker) do(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
@@ -1,10 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/jmoiron/sqlx"
 
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -26,3 +31,73 @@ func RunMigrations(path string, dsn string) error {
 
 	return nil
 }
+
+// ErrMigrationsPending is returned when the schema of the database is behind the latest migration
+// or a migration failed halfway.
+var ErrMigrationsPending = errors.New("migrations pending")
+
+// MigrationChecker verifies that the migrations applied to the database are up to date.
+type MigrationChecker struct {
+	db      *sqlx.DB
+	version uint
+}
+
+// NewMigrationChecker creates a new instance of MigrationChecker expecting the database schema
+// to be at the version of the latest migration found at the specified path.
+func NewMigrationChecker(db *sqlx.DB, path string) (*MigrationChecker, error) {
+	const op = "postgres.NewMigrationChecker"
+
+	src, err := source.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to open migrations: %w", op, err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read first migration: %w", op, err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read migrations: %w", op, err)
+		}
+
+		version = next
+	}
+
+	return &MigrationChecker{db: db, version: version}, nil
+}
+
+// Check returns ErrMigrationsPending if the schema version recorded by golang-migrate in the
+// schema_migrations table is behind the expected version or is dirty.
+func (c *MigrationChecker) Check(ctx context.Context) error {
+	const op = "postgres.MigrationChecker.Check"
+
+	var (
+		version uint
+		dirty   bool
+	)
+
+	err := c.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w: no migrations applied, expected version %d", op, ErrMigrationsPending, c.version)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: failed to get schema version: %w", op, err)
+	}
+
+	if dirty {
+		return fmt.Errorf("%s: %w: schema version %d is dirty", op, ErrMigrationsPending, version)
+	}
+
+	if version < c.version {
+		return fmt.Errorf("%s: %w: schema version %d, expected %d", op, ErrMigrationsPending, version, c.version)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeMigrations writes empty up and down migrations with the given versions to a temporary directory
+// and returns its file source URL.
+func writeMigrations(t *testing.T, versions ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for _, v := range versions {
+		for _, direction := range []string{"up", "down"} {
+			name := filepath.Join(dir, v+"_migration."+direction+".sql")
+			require.NoError(t, os.WriteFile(name, nil, 0o644))
+		}
+	}
+
+	return "file://" + dir
+}
+
+func TestMigrationChecker_Check(t *testing.T) {
+	const query = "SELECT version, dirty FROM schema_migrations LIMIT 1"
+
+	tests := []struct {
+		name        string
+		rows        *sqlmock.Rows
+		wantPending bool
+	}{
+		{
+			name: "up to date",
+			rows: sqlmock.NewRows([]string{"version", "dirty"}).AddRow(3, false),
+		},
+		{
+			name:        "stale version",
+			rows:        sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, false),
+			wantPending: true,
+		},
+		{
+			name:        "dirty version",
+			rows:        sqlmock.NewRows([]string{"version", "dirty"}).AddRow(3, true),
+			wantPending: true,
+		},
+		{
+			name:        "no migrations applied",
+			rows:        sqlmock.NewRows([]string{"version", "dirty"}),
+			wantPending: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			defer mockDB.Close()
+
+			checker, err := NewMigrationChecker(sqlx.NewDb(mockDB, "sqlmock"), writeMigrations(t, "000001", "000002", "000003"))
+			require.NoError(t, err)
+
+			mock.ExpectQuery(query).WillReturnRows(tt.rows)
+
+			err = checker.Check(context.Background())
+
+			if tt.wantPending {
+				assert.ErrorIs(t, err, ErrMigrationsPending)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -68,3 +68,56 @@ func New(ctx context.Context, dsn string, opts ...Option) (*sqlx.DB, error) {
 
 	return db, nil
 }
+
+// maxRetryInterval caps the interval between connection attempts, which doubles after each attempt.
+const maxRetryInterval = 30 * time.Second
+
+// Retry configures retrying failed connection attempts, e.g. while the database is still starting.
+// Attempts is the number of retries after the first attempt, and Interval is the time waited before
+// the first retry, doubled before each subsequent one up to 30 seconds. OnRetry, if set, is called
+// before waiting for each retry, e.g. to log the failed attempt.
+type Retry struct {
+	Attempts int
+	Interval time.Duration
+	OnRetry  func(attempt int, wait time.Duration, err error)
+}
+
+// Connect creates a new connection to the PostgreSQL database like New, retrying as configured by retry
+// if the database can't be reached. It stops retrying and returns the error of the last attempt
+// if the context is done.
+func Connect(ctx context.Context, dsn string, retry Retry, opts ...Option) (*sqlx.DB, error) {
+	return connectWithRetry(ctx, retry, func(ctx context.Context) (*sqlx.DB, error) {
+		return New(ctx, dsn, opts...)
+	})
+}
+
+// connectWithRetry calls connect until it succeeds or the retries are used up.
+func connectWithRetry(ctx context.Context, retry Retry, connect func(ctx context.Context) (*sqlx.DB, error)) (*sqlx.DB, error) {
+	wait := retry.Interval
+
+	for attempt := 1; ; attempt++ {
+		db, err := connect(ctx)
+		if err == nil {
+			return db, nil
+		}
+
+		if attempt > retry.Attempts {
+			return nil, err
+		}
+
+		if retry.OnRetry != nil {
+			retry.OnRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+
+		wait = min(wait*2, maxRetryInterval)
+	}
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectWithRetry(t *testing.T) {
+	errUnavailable := errors.New("connection refused")
+
+	// delayedConnect simulates a database that becomes available after the given number of failed attempts.
+	delayedConnect := func(failures int) (func(ctx context.Context) (*sqlx.DB, error), *int) {
+		attempts := 0
+
+		return func(context.Context) (*sqlx.DB, error) {
+			attempts++
+			if attempts <= failures {
+				return nil, errUnavailable
+			}
+
+			return &sqlx.DB{}, nil
+		}, &attempts
+	}
+
+	t.Run("database becomes available", func(t *testing.T) {
+		connect, attempts := delayedConnect(3)
+
+		var waits []time.Duration
+		retry := Retry{
+			Attempts: 5,
+			Interval: time.Millisecond,
+			OnRetry: func(attempt int, wait time.Duration, err error) {
+				assert.Equal(t, len(waits)+1, attempt)
+				assert.ErrorIs(t, err, errUnavailable)
+				waits = append(waits, wait)
+			},
+		}
+
+		db, err := connectWithRetry(context.Background(), retry, connect)
+
+		require.NoError(t, err)
+		assert.NotNil(t, db)
+		assert.Equal(t, 4, *attempts)
+		assert.Equal(t, []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}, waits)
+	})
+
+	t.Run("retries used up", func(t *testing.T) {
+		connect, attempts := delayedConnect(10)
+
+		_, err := connectWithRetry(context.Background(), Retry{Attempts: 2, Interval: time.Millisecond}, connect)
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, 3, *attempts)
+	})
+
+	t.Run("no retries", func(t *testing.T) {
+		connect, attempts := delayedConnect(1)
+
+		_, err := connectWithRetry(context.Background(), Retry{Interval: time.Millisecond}, connect)
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, 1, *attempts)
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		connect, attempts := delayedConnect(10)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		retry := Retry{
+			Attempts: 5,
+			Interval: time.Hour,
+			OnRetry:  func(int, time.Duration, error) { cancel() },
+		}
+
+		_, err := connectWithRetry(ctx, retry, connect)
+
+		assert.ErrorIs(t, err, errUnavailable)
+		assert.Equal(t, 1, *attempts)
+	})
+}
@@ -0,0 +1,88 @@
+// Package servertiming collects the durations of the parts of serving a request, e.g. database calls,
+// and formats them as a Server-Timing header (https://www.w3.org/TR/server-timing/), so that clients
+// and browser devtools can see where the time of a request was spent.
+package servertiming
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contextKey is the key of the timings in the request context.
+type contextKey struct{}
+
+// metric is the total duration of a named part of serving a request.
+type metric struct {
+	name     string
+	duration time.Duration
+}
+
+// Timings accumulates the durations of named metrics of a request. It is safe for concurrent use,
+// since a request may be served by several goroutines.
+type Timings struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewContext returns a copy of ctx carrying new empty timings, along with the timings.
+func NewContext(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{}
+	return context.WithValue(ctx, contextKey{}, t), t
+}
+
+// FromContext returns the timings carried by ctx, or nil if there are none.
+func FromContext(ctx context.Context) *Timings {
+	t, _ := ctx.Value(contextKey{}).(*Timings)
+	return t
+}
+
+// Add adds d to the duration of the named metric of the timings carried by ctx.
+// It does nothing if ctx carries no timings.
+func Add(ctx context.Context, name string, d time.Duration) {
+	if t := FromContext(ctx); t != nil {
+		t.Add(name, d)
+	}
+}
+
+// Add adds d to the duration of the named metric. Metrics are kept in the order they are first added.
+func (t *Timings) Add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.metrics {
+		if t.metrics[i].name == name {
+			t.metrics[i].duration += d
+			return
+		}
+	}
+
+	t.metrics = append(t.metrics, metric{name: name, duration: d})
+}
+
+// Header formats the metrics as the value of a Server-Timing header, followed by the total metric
+// with the given duration, e.g. "db;dur=1.25, total;dur=3.5". Durations are in milliseconds.
+func (t *Timings) Header(total time.Duration) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var b strings.Builder
+
+	for _, m := range t.metrics {
+		writeMetric(&b, m.name, m.duration)
+		b.WriteString(", ")
+	}
+
+	writeMetric(&b, "total", total)
+
+	return b.String()
+}
+
+// writeMetric writes a metric with its duration in milliseconds to b.
+func writeMetric(b *strings.Builder, name string, d time.Duration) {
+	b.WriteString(name)
+	b.WriteString(";dur=")
+	b.WriteString(strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', -1, 64))
+}
@@ -0,0 +1,163 @@
+// Package shortcode provides generators of short codes for shortened URLs.
+package shortcode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	gonanoid "github.com/matoous/go-nanoid/v2"
+)
+
+// base62Alphabet contains the characters used by Base62, in the order of their values.
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Alphabets of the encodings random short codes can be generated in.
+const (
+	// AlphabetBase62 contains digits and ASCII letters, producing the most compact codes.
+	AlphabetBase62 = base62Alphabet
+	// AlphabetBase58 leaves out the characters of Base62 that are easily confused with each other:
+	// 0, O, I and l, producing codes that are easier to read out and type.
+	AlphabetBase58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// AlphabetHex contains the lowercase hexadecimal digits.
+	AlphabetHex = "0123456789abcdef"
+)
+
+// Base62 encodes the number using digits and ASCII letters.
+func Base62(n uint64) string {
+	if n == 0 {
+		return base62Alphabet[:1]
+	}
+
+	var buf [11]byte // 62^11 > 2^64
+	i := len(buf)
+
+	for n > 0 {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+	}
+
+	return string(buf[i:])
+}
+
+// padLeft pads the code with the zero digit of the base62 alphabet up to the given length.
+func padLeft(code string, length int) string {
+	if len(code) >= length {
+		return code
+	}
+
+	return strings.Repeat(base62Alphabet[:1], length-len(code)) + code
+}
+
+// NanoID generates random short codes using the nanoid alphabet.
+type NanoID struct{}
+
+// Generate returns a random short code of the given length.
+func (NanoID) Generate(_ context.Context, length int) (string, error) {
+	const op = "shortcode.NanoID.Generate"
+
+	code, err := gonanoid.New(length)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+// Random generates random short codes using the characters of an alphabet, e.g. AlphabetBase58.
+type Random struct {
+	alphabet string
+}
+
+// NewRandom creates a new Random that generates short codes using the characters of the alphabet.
+func NewRandom(alphabet string) Random {
+	return Random{alphabet: alphabet}
+}
+
+// Generate returns a random short code of the given length.
+func (g Random) Generate(_ context.Context, length int) (string, error) {
+	const op = "shortcode.Random.Generate"
+
+	code, err := gonanoid.Generate(g.alphabet, length)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return code, nil
+}
+
+// Counter generates sequential short codes by encoding an in-memory counter in base62.
+// It produces the shortest codes at low volume, but starts over when the process restarts,
+// so it is only suitable for tests and single-instance deployments that set the start value.
+type Counter struct {
+	next atomic.Uint64
+}
+
+// NewCounter creates a new Counter that generates its first short code from the given value.
+func NewCounter(start uint64) *Counter {
+	c := &Counter{}
+	c.next.Store(start)
+
+	return c
+}
+
+// Generate returns the next value of the counter encoded in base62,
+// left-padded with zeros if it is shorter than the given length.
+func (c *Counter) Generate(_ context.Context, length int) (string, error) {
+	n := c.next.Add(1) - 1
+
+	return padLeft(Base62(n), length), nil
+}
+
+// IDBlockSource reserves blocks of unique IDs, e.g. from a database sequence shared by all instances.
+type IDBlockSource interface {
+	// NextIDBlock reserves size consecutive IDs starting at first.
+	// The IDs must not be handed out by the source again.
+	NextIDBlock(ctx context.Context) (first, size uint64, err error)
+}
+
+// Sequence generates sequential short codes by encoding unique IDs in base62. IDs are fetched
+// from the source in blocks and handed out from memory, so that the source is only consulted
+// once per block. The codes never collide with each other, even across instances sharing the
+// source, and are the shortest possible for the number of codes generated so far.
+type Sequence struct {
+	source IDBlockSource
+
+	mu   sync.Mutex
+	next uint64
+	end  uint64
+}
+
+// NewSequence creates a new Sequence that generates short codes from the IDs reserved from the source.
+func NewSequence(source IDBlockSource) *Sequence {
+	return &Sequence{source: source}
+}
+
+// Generate returns the next ID encoded in base62, left-padded with zeros if it is shorter
+// than the given length. A new block of IDs is reserved if the current one is used up.
+func (s *Sequence) Generate(ctx context.Context, length int) (string, error) {
+	const op = "shortcode.Sequence.Generate"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == s.end {
+		first, size, err := s.source.NextIDBlock(ctx)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+		if size == 0 {
+			return "", fmt.Errorf("%s: empty id block", op)
+		}
+
+		s.next, s.end = first, first+size
+	}
+
+	n := s.next
+	s.next++
+
+	return padLeft(Base62(n), length), nil
+}
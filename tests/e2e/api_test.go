@@ -143,7 +143,7 @@ func (suite *APITestSuite) TestResolveShortCode() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -162,7 +162,7 @@ func (suite *APITestSuite) TestResolveShortCode() {
 	})
 }
 
-func (suite *APITestSuite) TestModifyURL() {
+func (suite *APITestSuite) TestUpsertURL() {
 	const path = "/api/v1/shorten/%s"
 
 	suite.Run("empty request body", func() {
@@ -200,19 +200,29 @@ func (suite *APITestSuite) TestModifyURL() {
 			ContainsKey("message")
 	})
 
-	suite.Run("url not found", func() {
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+	suite.Run("created", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "my-alias")).
+			WithJSON(map[string]any{"original_url": "https://example.com", "note": "launch"}).
 			Expect().
-			Status(http.StatusNotFound).
-			JSON().Object()
+			Status(http.StatusCreated)
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		resp.Header("Location").IsEqual("/api/v1/shorten/my-alias")
+		resp.JSON().Object().
+			HasValue("short_code", "my-alias").
+			HasValue("original_url", "https://example.com").
+			HasValue("note", "launch")
+
+		suite.e.PUT(fmt.Sprintf(path, "my-alias")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("original_url", "https://new-example.com").
+			HasValue("note", "launch")
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -246,7 +256,7 @@ func (suite *APITestSuite) TestDeactivateURL() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -271,7 +281,7 @@ func (suite *APITestSuite) TestGetURLStats() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -7,6 +7,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/gavv/httpexpect/v2"
@@ -18,7 +20,9 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 	"github.com/vadimbarashkov/url-shortener/internal/adapter/repository/postgres"
 	"github.com/vadimbarashkov/url-shortener/internal/config"
+	"github.com/vadimbarashkov/url-shortener/internal/entity"
 	"github.com/vadimbarashkov/url-shortener/internal/usecase"
+	"github.com/vadimbarashkov/url-shortener/pkg/shortcode"
 	"github.com/vadimbarashkov/url-shortener/tests"
 
 	delivery "github.com/vadimbarashkov/url-shortener/internal/adapter/delivery/http"
@@ -173,6 +177,22 @@ func (suite *APITestSuite) TestShortenURL() {
 		resp.HasValue("created_at", url.CreatedAt)
 		resp.ContainsKey("updated_at")
 	})
+
+	suite.Run("sequential short codes", func() {
+		uc := usecase.NewURLUseCase(suite.urlRepo, usecase.WithShortCodeGenerator(shortcode.NewCounter(0)))
+		router := delivery.NewRouter(suite.logger, uc)
+		e := httpexpect.Default(suite.T(), "")
+
+		for _, want := range []string{"0000000", "0000001", "0000002"} {
+			e.POST(path).
+				WithHandler(router).
+				WithJSON(map[string]string{"original_url": "https://example.com"}).
+				Expect().
+				Status(http.StatusCreated).
+				JSON().Object().
+				HasValue("short_code", want)
+		}
+	})
 }
 
 func (suite *APITestSuite) TestResolveShortCode() {
@@ -189,7 +209,7 @@ func (suite *APITestSuite) TestResolveShortCode() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -215,22 +235,120 @@ func (suite *APITestSuite) TestResolveShortCode() {
 	})
 }
 
-func (suite *APITestSuite) TestModifyURL() {
+// TestConcurrentResolves pins that no click is lost when the same short code is resolved concurrently,
+// both when clicks are counted in the access_count column and when they are counted by access events.
+func (suite *APITestSuite) TestConcurrentResolves() {
+	const (
+		workers  = 20
+		resolves = 10
+	)
+
+	// resolveConcurrently calls resolve workers*resolves times from concurrent workers
+	// and returns the number of failed calls.
+	resolveConcurrently := func(resolve func() error) int64 {
+		var (
+			wg     sync.WaitGroup
+			failed atomic.Int64
+		)
+
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for range resolves {
+					if err := resolve(); err != nil {
+						failed.Add(1)
+					}
+				}
+			}()
+		}
+
+		wg.Wait()
+
+		return failed.Load()
+	}
+
+	suite.Run("access count", func() {
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
+		if err != nil {
+			suite.T().Fatalf("Failed to save url record: %v", err)
+		}
+
+		client := suite.server.Client()
+
+		failed := resolveConcurrently(func() error {
+			resp, err := client.Get(suite.server.URL + "/api/v1/shorten/" + url.ShortCode)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return fmt.Errorf("unexpected status %d", resp.StatusCode)
+			}
+
+			return nil
+		})
+		suite.Zero(failed)
+
+		url, err = suite.urlRepo.RetrieveByShortCode(context.Background(), url.ShortCode)
+		if err != nil {
+			suite.T().Fatalf("Failed to retrieve url record: %v", err)
+		}
+
+		suite.Equal(int64(workers*resolves), url.AccessCount)
+	})
+
+	suite.Run("event tracking", func() {
+		uc := usecase.NewURLUseCase(suite.urlRepo, usecase.WithEventTracking(true))
+
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
+		if err != nil {
+			suite.T().Fatalf("Failed to save url record: %v", err)
+		}
+
+		failed := resolveConcurrently(func() error {
+			_, err := uc.ResolveShortCode(context.Background(), url.ShortCode, entity.Click{IP: "203.0.113.1"})
+			return err
+		})
+		suite.Zero(failed)
+
+		url, err = uc.GetURLStats(context.Background(), url.ShortCode)
+		if err != nil {
+			suite.T().Fatalf("Failed to get url stats: %v", err)
+		}
+
+		suite.Equal(int64(workers*resolves), url.AccessCount)
+	})
+}
+
+func (suite *APITestSuite) TestUpsertURL() {
 	const path = "/api/v1/shorten/%s"
 
-	suite.Run("url not found", func() {
-		resp := suite.e.PUT(fmt.Sprintf(path, "abc123")).
-			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+	suite.Run("created", func() {
+		resp := suite.e.PUT(fmt.Sprintf(path, "my-alias")).
+			WithJSON(map[string]any{"original_url": "https://example.com", "note": "launch"}).
 			Expect().
-			Status(http.StatusNotFound).
-			JSON().Object()
+			Status(http.StatusCreated)
 
-		resp.HasValue("status", "error")
-		resp.ContainsKey("message")
+		resp.Header("Location").IsEqual("/api/v1/shorten/my-alias")
+		resp.JSON().Object().
+			HasValue("short_code", "my-alias").
+			HasValue("original_url", "https://example.com").
+			HasValue("note", "launch")
+
+		suite.e.PUT(fmt.Sprintf(path, "my-alias")).
+			WithJSON(map[string]string{"original_url": "https://new-example.com"}).
+			Expect().
+			Status(http.StatusOK).
+			JSON().Object().
+			HasValue("original_url", "https://new-example.com").
+			HasValue("note", "launch")
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -264,7 +382,7 @@ func (suite *APITestSuite) TestDeactivateURL() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}
@@ -289,7 +407,7 @@ func (suite *APITestSuite) TestGetURLStats() {
 	})
 
 	suite.Run("success", func() {
-		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com")
+		url, err := suite.urlRepo.Save(context.Background(), "abc123", "https://example.com", "", nil, "")
 		if err != nil {
 			suite.T().Fatalf("Failed to save url record: %v", err)
 		}